@@ -59,6 +59,13 @@ func TestAcceptance(t *testing.T) {
 			MustUse:    []string{goRuntime, goBuild},
 			MustNotUse: []string{goPath},
 		},
+		{
+			Name:       "Build all entrypoints",
+			App:        "entrypoints",
+			Env:        []string{"GOOGLE_GO_BUILD_ALL=true", "GOOGLE_GO_WEB_PROCESS=first"},
+			MustUse:    []string{goRuntime, goBuild},
+			MustNotUse: []string{goPath},
+		},
 		{
 			Name: "Go.mod and vendor",
 			// go mod and vendor cannot be used together before go 1.14
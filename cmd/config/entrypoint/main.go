@@ -26,6 +26,7 @@ import (
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/appyaml"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/shell"
 )
 
 var (
@@ -75,6 +76,7 @@ func buildFn(ctx *gcp.Context) error {
 	}
 
 	if entrypoint := os.Getenv(env.Entrypoint); entrypoint != "" {
+		checkCommand(ctx, env.Entrypoint, entrypoint)
 		ctx.AddProcess(gcp.WebProcess, []string{entrypoint}, gcp.AsDefaultProcess())
 		ctx.Logf("Using entrypoint from environment variable %s: %s", env.Entrypoint, entrypoint)
 		return nil
@@ -127,6 +129,7 @@ func addProcfileProcesses(ctx *gcp.Context, content string) error {
 			continue
 		}
 		found[name] = true
+		checkCommand(ctx, fmt.Sprintf("Procfile %s process", name), command)
 
 		if name == gcp.WebProcess {
 			ctx.Logf("Using entrypoint from Procfile: %s", command)
@@ -141,3 +144,12 @@ func addProcfileProcesses(ctx *gcp.Context, content string) error {
 	}
 	return nil
 }
+
+// checkCommand warns about commands that fail to parse, e.g. because of an unterminated quote. The
+// commands are run with bash, which may accept syntax the parser does not, so they are not
+// rejected.
+func checkCommand(ctx *gcp.Context, source, command string) {
+	if _, err := shell.Split(command); err != nil {
+		ctx.Warnf("The %s command may fail to run: %v", source, err)
+	}
+}
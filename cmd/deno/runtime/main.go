@@ -0,0 +1,187 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Implements deno/runtime buildpack.
+// The runtime buildpack installs Deno and the dependencies of the application.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/cache"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/deno"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/runtime"
+	"github.com/buildpacks/libcnb/v2"
+)
+
+const (
+	denoLayer         = "deno"
+	denoDirLayer      = "deno_dir"
+	binLayer          = "bin"
+	dependencyHashKey = "dependency_hash"
+)
+
+func main() {
+	gcp.Main(detectFn, buildFn)
+}
+
+func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
+	if result := runtime.CheckOverride("deno"); result != nil {
+		return result, nil
+	}
+	for _, f := range append(deno.ConfigFiles, deno.LockFile) {
+		exists, err := ctx.FileExists(f)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return gcp.OptInFileFound(f), nil
+		}
+	}
+	return gcp.OptOut("none of deno.json, deno.jsonc or deno.lock found"), nil
+}
+
+func buildFn(ctx *gcp.Context) error {
+	version, err := deno.RuntimeVersion(ctx)
+	if err != nil {
+		return err
+	}
+	compile, err := env.IsPresentAndTrue(deno.EnvCompile)
+	if err != nil {
+		return gcp.UserErrorf("parsing %s: %w", deno.EnvCompile, err)
+	}
+
+	dl, err := ctx.Layer(denoLayer, gcp.BuildLayer, gcp.CacheLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", denoLayer, err)
+	}
+	// A compiled application embeds the Deno runtime and does not need it at launch.
+	dl.Launch = !compile
+	if _, err := runtime.InstallTarballIfNotCached(ctx, runtime.Deno, version, dl); err != nil {
+		return err
+	}
+	// The release archive contains the deno binary at its root.
+	dl.SharedEnvironment.Prepend("PATH", string(os.PathListSeparator), dl.Path)
+	dl.SharedEnvironment.Default("DENO_NO_UPDATE_CHECK", "1")
+	if err := ctx.Setenv("PATH", dl.Path+string(os.PathListSeparator)+os.Getenv("PATH")); err != nil {
+		return err
+	}
+
+	cfg, err := deno.ReadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	entrypoint, err := deno.Entrypoint(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	lockExists, err := ctx.FileExists(deno.LockFile)
+	if err != nil {
+		return err
+	}
+	ddl, err := denoDir(ctx, version, lockExists, !compile)
+	if err != nil {
+		return err
+	}
+	cmd, err := deno.InstallCommand(version, entrypoint, cfg != nil, lockExists)
+	if err != nil {
+		return err
+	}
+	if cmd != nil {
+		if _, err := ctx.Exec(cmd, gcp.WithUserAttribution, gcp.WithEnv("DENO_DIR="+ddl.Path)); err != nil {
+			return gcp.UserErrorf("installing Deno dependencies: %w", err)
+		}
+	}
+
+	permissions, err := deno.PermissionFlags(cfg)
+	if err != nil {
+		return err
+	}
+	if compile {
+		return compileApp(ctx, entrypoint, permissions)
+	}
+	if deno.HasTask(cfg, "start") {
+		ctx.AddWebProcess([]string{"deno", "task", "start"})
+		return nil
+	}
+	if entrypoint == "" {
+		ctx.Warnf("No start task nor entrypoint module found, set GOOGLE_ENTRYPOINT to specify the command starting the application.")
+		return nil
+	}
+	serve, err := deno.IsServeModule(ctx, entrypoint)
+	if err != nil {
+		return err
+	}
+	if serve {
+		// deno serve is run in a shell to read the port from the PORT environment variable.
+		ctx.AddProcess(gcp.WebProcess, deno.RunCommand(entrypoint, permissions, true), gcp.AsDefaultProcess())
+		return nil
+	}
+	ctx.AddWebProcess(deno.RunCommand(entrypoint, permissions, false))
+	return nil
+}
+
+// denoDir returns the layer used as DENO_DIR, the cache of remote modules and npm packages. The
+// layer is cleared when the lock file changes so that unused dependencies do not accumulate.
+func denoDir(ctx *gcp.Context, version string, lockExists, launch bool) (*libcnb.Layer, error) {
+	l, err := ctx.Layer(denoDirLayer, gcp.BuildLayer, gcp.CacheLayer)
+	if err != nil {
+		return nil, fmt.Errorf("creating %v layer: %w", denoDirLayer, err)
+	}
+	l.Launch = launch
+	opts := []cache.Option{cache.WithStrings(version)}
+	if lockExists {
+		opts = append(opts, cache.WithFiles(filepath.Join(ctx.ApplicationRoot(), deno.LockFile)))
+	}
+	hash, cached, err := cache.HashAndCheck(ctx, l, dependencyHashKey, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if !cached {
+		if err := ctx.ClearLayer(l); err != nil {
+			return nil, fmt.Errorf("clearing layer %q: %w", l.Name, err)
+		}
+	}
+	cache.Add(ctx, l, dependencyHashKey, hash)
+	l.SharedEnvironment.Override("DENO_DIR", l.Path)
+	return l, nil
+}
+
+// compileApp compiles the entrypoint into a self-contained binary set as the web process.
+func compileApp(ctx *gcp.Context, entrypoint string, permissions []string) error {
+	if entrypoint == "" {
+		return gcp.UserErrorf("%s is set but no entrypoint module was found to compile", deno.EnvCompile)
+	}
+	serve, err := deno.IsServeModule(ctx, entrypoint)
+	if err != nil {
+		return err
+	}
+	if serve {
+		return gcp.UserErrorf("%s does not support modules run with deno serve, start the server with Deno.serve() in %s", deno.EnvCompile, entrypoint)
+	}
+	bl, err := ctx.Layer(binLayer, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", binLayer, err)
+	}
+	output := filepath.Join(bl.Path, "bin", "app")
+	if _, err := ctx.Exec(deno.CompileCommand(entrypoint, output, permissions), gcp.WithUserAttribution); err != nil {
+		return gcp.UserErrorf("compiling %s: %w", entrypoint, err)
+	}
+	ctx.AddWebProcess([]string{output})
+	return nil
+}
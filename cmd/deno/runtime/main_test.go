@@ -0,0 +1,83 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		env   []string
+		want  int
+	}{
+		{
+			name: "deno.json",
+			files: map[string]string{
+				"deno.json": "{}",
+				"main.ts":   "",
+			},
+			want: 0,
+		},
+		{
+			name: "deno.jsonc",
+			files: map[string]string{
+				"deno.jsonc": "{}",
+			},
+			want: 0,
+		},
+		{
+			name: "deno.lock",
+			files: map[string]string{
+				"deno.lock": "{}",
+				"main.ts":   "",
+			},
+			want: 0,
+		},
+		{
+			name: "typescript without deno files",
+			files: map[string]string{
+				"main.ts":      "",
+				"package.json": "{}",
+			},
+			want: 100,
+		},
+		{
+			name: "runtime set to deno",
+			files: map[string]string{
+				"main.ts": "",
+			},
+			env:  []string{"GOOGLE_RUNTIME=deno"},
+			want: 0,
+		},
+		{
+			name: "runtime set to nodejs",
+			files: map[string]string{
+				"deno.json": "{}",
+			},
+			env:  []string{"GOOGLE_RUNTIME=nodejs"},
+			want: 100,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buildpacktest.TestDetect(t, detectFn, tc.name, tc.files, tc.env, tc.want)
+		})
+	}
+}
@@ -23,11 +23,13 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/ar"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/cache"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/devmode"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/dotnet"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/shell"
 	"github.com/buildpacks/libcnb/v2"
 )
 
@@ -78,6 +80,10 @@ func buildFn(ctx *gcp.Context) error {
 		ctx.CacheMiss(cacheTag)
 	}
 
+	if err := ar.GenerateNuGetConfig(ctx); err != nil {
+		return fmt.Errorf("generating NuGet config: %w", err)
+	}
+
 	// Run restore regardless of cache status because it generates files expected by publish.
 	cmd := []string{"dotnet", "restore", "--packages", pkgLayer.Path, proj}
 	if _, err := ctx.Exec(cmd, gcp.WithEnv("DOTNET_CLI_TELEMETRY_OPTOUT=true"), gcp.WithUserAttribution); err != nil {
@@ -112,10 +118,9 @@ func buildFn(ctx *gcp.Context) error {
 		proj,
 	}
 
-	if args := os.Getenv(env.BuildArgs); args != "" {
-		// Use bash to excute the command to avoid havnig to parse the build arguments.
-		// strings.Fields may be unsafe here in case some arguments have a space.
-		cmd = []string{"/bin/bash", "-c", strings.Join(append(cmd, args), " ")}
+	cmd, err = withBuildArgs(cmd)
+	if err != nil {
+		return err
 	}
 
 	if _, err := ctx.Exec(cmd, gcp.WithEnv("DOTNET_CLI_TELEMETRY_OPTOUT=true"), gcp.WithUserAttribution); err != nil {
@@ -294,3 +299,24 @@ func configureBinSymlink(ctx *gcp.Context, binLayerPath string) error {
 	}
 	return nil
 }
+
+// withBuildArgs appends the arguments of GOOGLE_BUILD_ARGS to the command, split following the
+// shell quoting rules. The command is run with bash instead if GOOGLE_DOTNET_BUILD_SHELL is true.
+func withBuildArgs(cmd []string) ([]string, error) {
+	args := os.Getenv(env.BuildArgs)
+	if args == "" {
+		return cmd, nil
+	}
+	useShell, err := env.IsPresentAndTrue(dotnet.EnvBuildShell)
+	if err != nil {
+		return nil, gcp.UserErrorf("parsing %s: %w", dotnet.EnvBuildShell, err)
+	}
+	if useShell {
+		return []string{"/bin/bash", "-c", strings.Join(append(cmd, args), " ")}, nil
+	}
+	split, err := shell.Split(args)
+	if err != nil {
+		return nil, gcp.UserErrorf("parsing %s: %w", env.BuildArgs, err)
+	}
+	return append(cmd, split...), nil
+}
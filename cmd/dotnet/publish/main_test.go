@@ -24,6 +24,7 @@ import (
 
 	buildpacktest "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestGetAssemblyName(t *testing.T) {
@@ -338,3 +339,51 @@ func TestDeleteFolder(t *testing.T) {
 		})
 	}
 }
+
+func TestWithBuildArgs(t *testing.T) {
+	cmd := []string{"dotnet", "publish", "app.csproj"}
+	testCases := []struct {
+		name      string
+		buildArgs string
+		useShell  string
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name: "no build args",
+			want: []string{"dotnet", "publish", "app.csproj"},
+		},
+		{
+			name:      "quoted build args",
+			buildArgs: `-p:Version=1.0.0 -p:Description="My app"`,
+			want:      []string{"dotnet", "publish", "app.csproj", "-p:Version=1.0.0", "-p:Description=My app"},
+		},
+		{
+			name:      "unterminated quote",
+			buildArgs: `-p:Description="My app`,
+			wantErr:   true,
+		},
+		{
+			name:      "shell",
+			buildArgs: `-p:Version=$VERSION`,
+			useShell:  "true",
+			want:      []string{"/bin/bash", "-c", "dotnet publish app.csproj -p:Version=$VERSION"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GOOGLE_BUILD_ARGS", tc.buildArgs)
+			if tc.useShell != "" {
+				t.Setenv("GOOGLE_DOTNET_BUILD_SHELL", tc.useShell)
+			}
+
+			got, err := withBuildArgs(cmd)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("withBuildArgs() got error: %v, want error: %t", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("withBuildArgs() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/dotnet"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/memlimit"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/runtime"
 )
 
@@ -77,6 +78,17 @@ func buildFn(ctx *gcp.Context) error {
 	if err := buildRuntimeLayer(ctx, runtimeVersion); err != nil {
 		return fmt.Errorf("building the runtime layer: %w", err)
 	}
+	sizeHeap, err := env.IsPresentAndTrue(env.SizeHeap)
+	if err != nil {
+		return gcp.UserErrorf("%v", err)
+	}
+	if sizeHeap {
+		// When the container starts, the memlimit binary sets DOTNET_GCHeapHardLimit, unless the heap
+		// limit is set by the user.
+		if err := memlimit.Install(ctx, memlimit.Dotnet); err != nil {
+			return fmt.Errorf("installing memlimit: %w", err)
+		}
+	}
 	return nil
 }
 
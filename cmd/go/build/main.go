@@ -19,7 +19,9 @@ package main
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/devmode"
@@ -49,10 +51,10 @@ func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
 }
 
 func buildFn(ctx *gcp.Context) error {
-	// Keep GOCACHE in Devmode for faster rebuilds.
-	cl, err := ctx.Layer("gocache", gcp.BuildLayer, gcp.LaunchLayerIfDevMode)
+	// Keep GOCACHE across builds, and in Devmode for faster rebuilds.
+	cl, err := golang.NewBuildCacheLayer(ctx, goBuildFlags()...)
 	if err != nil {
-		return fmt.Errorf("creating layer: %w", err)
+		return fmt.Errorf("creating build cache layer: %w", err)
 	}
 	if devmode.Enabled(ctx) {
 		cl.LaunchEnvironment.Override("GOCACHE", cl.Path)
@@ -67,24 +69,46 @@ func buildFn(ctx *gcp.Context) error {
 	bl.LaunchEnvironment.Prepend("PATH", string(os.PathListSeparator), bl.Path)
 	outBin := filepath.Join(bl.Path, golang.OutBin)
 
+	// BuildDirEnv should only be set by App Engine buildpacks.
+	workdir := os.Getenv(golang.BuildDirEnv)
+	if workdir == "" {
+		workdir = ctx.ApplicationRoot()
+	}
+
+	buildAll, err := env.IsPresentAndTrue(env.GoBuildAll)
+	if err != nil {
+		return gcp.UserErrorf("%v", err)
+	}
+	if _, buildableSet := os.LookupEnv(env.Buildable); buildAll && !buildableSet {
+		if devmode.Enabled(ctx) {
+			ctx.Warnf("%s is ignored in dev mode, a single main package is built.", env.GoBuildAll)
+		} else {
+			buildables, err := searchBuildables(ctx)
+			if err != nil {
+				return fmt.Errorf("unable to find a valid buildable: %w", err)
+			}
+			if len(buildables) > 1 {
+				if err := buildAllBinaries(ctx, bl.Path, cl.Path, workdir, buildables); err != nil {
+					return err
+				}
+				return golang.TrimBuildCache(ctx, cl)
+			}
+		}
+	}
+
 	buildable, err := goBuildable(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to find a valid buildable: %w", err)
 	}
 
 	// Build the application.
-	bld := []string{"go", "build"}
-	bld = append(bld, goBuildFlags()...)
-	bld = append(bld, "-o", outBin)
-	bld = append(bld, buildable)
-	// BuildDirEnv should only be set by App Engine buildpacks.
-	workdir := os.Getenv(golang.BuildDirEnv)
-	if workdir == "" {
-		workdir = ctx.ApplicationRoot()
-	}
+	bld := goBuildCommand(outBin, buildable)
 	if _, err := ctx.Exec(bld, gcp.WithEnv("GOCACHE="+cl.Path), gcp.WithWorkDir(workdir), gcp.WithMessageProducer(printTipsAndKeepStderrTail(ctx)), gcp.WithUserAttribution); err != nil {
 		return err
 	}
+	if err := golang.TrimBuildCache(ctx, cl); err != nil {
+		return err
+	}
 
 	// Configure the entrypoint for production. Use the full path to save `skaffold debug`
 	// from fetching the remote container image (tens to hundreds of megabytes), which is slow.
@@ -105,6 +129,97 @@ func buildFn(ctx *gcp.Context) error {
 	return nil
 }
 
+func goBuildCommand(outBin, buildable string) []string {
+	bld := []string{"go", "build"}
+	bld = append(bld, goBuildFlags()...)
+	bld = append(bld, "-o", outBin)
+	return append(bld, buildable)
+}
+
+// binary is a main package built into its own executable.
+type binary struct {
+	// pkg is the path of the main package, for example ./cmd/api.
+	pkg string
+	// name is the name of the executable and of its process.
+	name string
+}
+
+// buildAllBinaries builds every main package into its own executable in binDir and registers each
+// as a process named after it. The web process runs the binary selected by GOOGLE_GO_WEB_PROCESS,
+// or the binary named web, unless it is declared in a Procfile.
+func buildAllBinaries(ctx *gcp.Context, binDir, cacheDir, workdir string, buildables []string) error {
+	bins, err := binaries(buildables)
+	if err != nil {
+		return err
+	}
+	procfile, err := ctx.FileExists("Procfile")
+	if err != nil {
+		return err
+	}
+	web, err := webBinary(bins, os.Getenv(env.GoWebProcess), procfile)
+	if err != nil {
+		return err
+	}
+
+	for _, b := range bins {
+		outBin := filepath.Join(binDir, b.name)
+		if _, err := ctx.Exec(goBuildCommand(outBin, b.pkg), gcp.WithEnv("GOCACHE="+cacheDir), gcp.WithWorkDir(workdir), gcp.WithMessageProducer(printTipsAndKeepStderrTail(ctx)), gcp.WithUserAttribution); err != nil {
+			return err
+		}
+		if b.name == web {
+			ctx.AddWebProcess([]string{outBin})
+		}
+		if b.name != gcp.WebProcess {
+			ctx.AddProcess(b.name, []string{outBin}, gcp.AsDirectProcess())
+		}
+	}
+	return nil
+}
+
+// binaries returns the executables of the main packages, named after the directory of the package.
+// The main package at the root of the application is named after golang.OutBin.
+func binaries(buildables []string) ([]binary, error) {
+	var bins []binary
+	pkgs := make(map[string]string)
+	for _, b := range buildables {
+		name := path.Base(b)
+		if name == "." || name == "/" {
+			name = golang.OutBin
+		}
+		if other, ok := pkgs[name]; ok {
+			return nil, gcp.UserErrorf("main packages %s and %s would both be built into the binary %q, set %s to build a single package", other, b, name, env.Buildable)
+		}
+		pkgs[name] = b
+		bins = append(bins, binary{pkg: b, name: name})
+	}
+	return bins, nil
+}
+
+// webBinary returns the name of the binary run as the web process: the binary selected with
+// GOOGLE_GO_WEB_PROCESS, else the binary named web. It returns an empty string if the web process
+// is declared in a Procfile instead.
+func webBinary(bins []binary, selected string, procfile bool) (string, error) {
+	var names []string
+	for _, b := range bins {
+		names = append(names, b.name)
+	}
+	hasWeb := slices.Contains(names, gcp.WebProcess)
+	switch {
+	case selected != "" && !slices.Contains(names, selected):
+		return "", gcp.UserErrorf("%s=%q does not match any binary, it must be one of %s", env.GoWebProcess, selected, strings.Join(names, ", "))
+	case selected != "" && hasWeb && selected != gcp.WebProcess:
+		return "", gcp.UserErrorf("%s=%q conflicts with the binary named %q which is always the web process, set %s to build a single package", env.GoWebProcess, selected, gcp.WebProcess, env.Buildable)
+	case selected != "":
+		return selected, nil
+	case hasWeb:
+		return gcp.WebProcess, nil
+	case procfile:
+		return "", nil
+	default:
+		return "", gcp.UserErrorf("found %d main packages, set %s to the binary run as the web process, one of %s, or declare the web process in a Procfile", len(bins), env.GoWebProcess, strings.Join(names, ", "))
+	}
+}
+
 func goBuildable(ctx *gcp.Context) (string, error) {
 	// The user tells us what to build.
 	if buildable, ok := os.LookupEnv(env.Buildable); ok {
@@ -128,9 +243,18 @@ func goBuildable(ctx *gcp.Context) (string, error) {
 }
 
 // searchBuildables searches the source for all the files that contain
-// a `main()` entrypoint.
+// a `main()` entrypoint, in all the modules of a go.work workspace.
 func searchBuildables(ctx *gcp.Context) ([]string, error) {
-	result, err := ctx.Exec([]string{"go", "list", "-f", `{{if eq .Name "main"}}{{.Dir}}{{end}}`, "./..."}, gcp.WithUserAttribution)
+	patterns := []string{"./..."}
+	ws, err := golang.ReadWorkspace(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ws != nil {
+		patterns = ws.Patterns()
+	}
+	cmd := append([]string{"go", "list", "-f", `{{if eq .Name "main"}}{{.Dir}}{{end}}`}, patterns...)
+	result, err := ctx.Exec(cmd, gcp.WithUserAttribution)
 	if err != nil {
 		return nil, err
 	}
@@ -90,6 +90,107 @@ func TestGoBuildFlags(t *testing.T) {
 	}
 }
 
+func TestBinaries(t *testing.T) {
+	testCases := []struct {
+		name       string
+		buildables []string
+		want       []binary
+		wantErr    bool
+	}{
+		{
+			name:       "cmd directories",
+			buildables: []string{"./cmd/first", "./cmd/second"},
+			want: []binary{
+				{pkg: "./cmd/first", name: "first"},
+				{pkg: "./cmd/second", name: "second"},
+			},
+		},
+		{
+			name:       "root package",
+			buildables: []string{"./.", "./tools/migrate"},
+			want: []binary{
+				{pkg: "./.", name: "main"},
+				{pkg: "./tools/migrate", name: "migrate"},
+			},
+		},
+		{
+			name:       "same directory names",
+			buildables: []string{"./api/server", "./worker/server"},
+			wantErr:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := binaries(tc.buildables)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("binaries(%v) got error: %v, want error? %v", tc.buildables, err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("binaries(%v) = %v, want %v", tc.buildables, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWebBinary(t *testing.T) {
+	bins := []binary{{pkg: "./cmd/api", name: "api"}, {pkg: "./cmd/worker", name: "worker"}}
+	withWeb := append([]binary{{pkg: "./cmd/web", name: "web"}}, bins...)
+	testCases := []struct {
+		name     string
+		bins     []binary
+		selected string
+		procfile bool
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "selected",
+			bins:     bins,
+			selected: "worker",
+			want:     "worker",
+		},
+		{
+			name:     "selected unknown binary",
+			bins:     bins,
+			selected: "cron",
+			wantErr:  true,
+		},
+		{
+			name: "binary named web",
+			bins: withWeb,
+			want: "web",
+		},
+		{
+			name:     "selected conflicts with binary named web",
+			bins:     withWeb,
+			selected: "api",
+			wantErr:  true,
+		},
+		{
+			name:     "procfile",
+			bins:     bins,
+			procfile: true,
+			want:     "",
+		},
+		{
+			name:    "no selection",
+			bins:    bins,
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := webBinary(tc.bins, tc.selected, tc.procfile)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("webBinary() got error: %v, want error? %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("webBinary() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
 func clearAndSetEnv(env []string) {
 	os.Clearenv()
 	for _, p := range env {
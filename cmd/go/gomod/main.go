@@ -13,13 +13,15 @@
 // limitations under the License.
 
 // Implements go/gomod buildpack.
-// The gomod buildpack downloads modules specified in go.mod.
+// The gomod buildpack downloads modules specified in go.mod, or in the go.mod files of the modules
+// of a go.work workspace.
 package main
 
 import (
 	"fmt"
 	"os"
 
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/ar"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/golang"
 )
@@ -36,6 +38,13 @@ func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
 	if goModExists {
 		return gcp.OptInFileFound("go.mod"), nil
 	}
+	ws, err := golang.ReadWorkspace(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ws != nil {
+		return gcp.OptInFileFound(golang.GoWork), nil
+	}
 	return gcp.OptOutFileNotFound("go.mod"), nil
 }
 
@@ -44,6 +53,13 @@ func buildFn(ctx *gcp.Context) error {
 	if err != nil {
 		return fmt.Errorf("creating GOPATH layer: %w", err)
 	}
+	ws, err := golang.ReadWorkspace(ctx)
+	if err != nil {
+		return err
+	}
+	if ws != nil {
+		return downloadWorkspaceModules(ctx, l.Path)
+	}
 
 	vendorExists, err := ctx.FileExists("vendor")
 	if err != nil {
@@ -75,6 +91,10 @@ func buildFn(ctx *gcp.Context) error {
 	}
 	env := []string{"GOPATH=" + l.Path, "GO111MODULE=on"}
 
+	if err := ar.GenerateGoConfig(ctx); err != nil {
+		return fmt.Errorf("configuring private modules: %w", err)
+	}
+
 	// BuildDirEnv should only be set by App Engine buildpacks.
 	workdir := os.Getenv(golang.BuildDirEnv)
 	if workdir == "" {
@@ -100,3 +120,29 @@ func buildFn(ctx *gcp.Context) error {
 
 	return nil
 }
+
+// downloadWorkspaceModules downloads the modules of a go.work workspace from its root. `go work
+// sync` first updates the requirements of the workspace modules to the versions selected for the
+// workspace, so that the modules build with the same dependencies on their own.
+func downloadWorkspaceModules(ctx *gcp.Context, gopath string) error {
+	vendored, err := golang.CheckWorkspaceModFlag(ctx)
+	if err != nil {
+		return err
+	}
+	if vendored {
+		ctx.Logf("Not downloading modules because the workspace dependencies are vendored")
+		return nil
+	}
+	if err := ar.GenerateGoConfig(ctx); err != nil {
+		return fmt.Errorf("configuring private modules: %w", err)
+	}
+	env := []string{"GOPATH=" + gopath, "GO111MODULE=on"}
+	opts := []gcp.ExecOption{gcp.WithEnv(env...), gcp.WithWorkDir(ctx.ApplicationRoot()), gcp.WithUserAttribution}
+	if _, err := golang.ExecWithGoproxyFallback(ctx, []string{"go", "work", "sync"}, opts...); err != nil {
+		return fmt.Errorf("running go work sync: %w", err)
+	}
+	if _, err := golang.ExecWithGoproxyFallback(ctx, []string{"go", "mod", "download"}, opts...); err != nil {
+		return fmt.Errorf("running go mod download: %w", err)
+	}
+	return nil
+}
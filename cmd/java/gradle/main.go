@@ -28,6 +28,7 @@ import (
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/fileutil"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/java"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/shell"
 )
 
 const (
@@ -91,11 +92,19 @@ func buildFn(ctx *gcp.Context) error {
 		if strings.Contains(buildArgs, "project-cache-dir") {
 			ctx.Warnf("Detected project-cache-dir property set in GOOGLE_BUILD_ARGS. Dependency caching may not work properly.")
 		}
-		command = append(command, buildArgs)
+		args, err := shell.Split(buildArgs)
+		if err != nil {
+			return gcp.UserErrorf("parsing %s: %w", env.BuildArgs, err)
+		}
+		command = append(command, args...)
 	}
 
 	if gradleBuildArgs := os.Getenv(java.GradleBuildArgs); gradleBuildArgs != "" {
-		command = append([]string{gradle}, strings.Fields(gradleBuildArgs)...)
+		args, err := shell.Split(gradleBuildArgs)
+		if err != nil {
+			return gcp.UserErrorf("parsing %s: %w", java.GradleBuildArgs, err)
+		}
+		command = append([]string{gradle}, args...)
 	}
 
 	if !ctx.Debug() && !devmode.Enabled(ctx) {
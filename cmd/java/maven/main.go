@@ -23,11 +23,13 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/ar"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/devmode"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/fileutil"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/java"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/shell"
 )
 
 const (
@@ -105,11 +107,29 @@ func buildFn(ctx *gcp.Context) error {
 		if strings.Contains(buildArgs, "maven.repo.local") {
 			ctx.Warnf("Detected maven.repo.local property set in GOOGLE_BUILD_ARGS. Maven caching may not work properly.")
 		}
-		command = append(command, strings.Fields(buildArgs)...)
+		args, err := shell.Split(buildArgs)
+		if err != nil {
+			return gcp.UserErrorf("parsing %s: %w", env.BuildArgs, err)
+		}
+		command = append(command, args...)
 	}
 
 	if mvnBuildArgs := os.Getenv(java.MavenBuildArgs); mvnBuildArgs != "" {
-		command = append([]string{mvn}, strings.Fields(mvnBuildArgs)...)
+		args, err := shell.Split(mvnBuildArgs)
+		if err != nil {
+			return gcp.UserErrorf("parsing %s: %w", java.MavenBuildArgs, err)
+		}
+		command = append([]string{mvn}, args...)
+	}
+
+	settings, err := ar.GenerateMavenSettings(ctx)
+	if err != nil {
+		return fmt.Errorf("generating Maven settings: %w", err)
+	}
+	if settings != "" {
+		// The generated settings are the global settings, so that the user settings, e.g. the
+		// mirrors and profiles of ~/.m2/settings.xml or a --settings build argument, still apply.
+		command = append(command, "--global-settings", settings)
 	}
 
 	if !ctx.Debug() && !devmode.Enabled(ctx) {
@@ -24,6 +24,7 @@ import (
 
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/memlimit"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/runtime"
 )
 
@@ -106,8 +107,21 @@ func buildFn(ctx *gcp.Context) error {
 	if strings.HasPrefix(featureVersion, "21") {
 		jdkRuntime = runtime.CanonicalJDK
 	}
-	_, err = runtime.InstallTarballIfNotCached(ctx, jdkRuntime, featureVersion, l)
-	return err
+	if _, err = runtime.InstallTarballIfNotCached(ctx, jdkRuntime, featureVersion, l); err != nil {
+		return err
+	}
+	sizeHeap, err := env.IsPresentAndTrue(env.SizeHeap)
+	if err != nil {
+		return gcp.UserErrorf("%v", err)
+	}
+	if sizeHeap {
+		// When the container starts, the memlimit binary adds -XX:MaxRAMPercentage to
+		// JAVA_TOOL_OPTIONS, unless the heap size is set by the user.
+		if err := memlimit.Install(ctx, memlimit.Java); err != nil {
+			return fmt.Errorf("installing memlimit: %w", err)
+		}
+	}
+	return nil
 }
 
 type binaryPkg struct {
@@ -0,0 +1,158 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Implements nodejs/bun buildpack.
+// The bun buildpack installs dependencies using Bun and installs Bun itself.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/ar"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/firebase/faherror"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/nodejs"
+)
+
+const (
+	bunLayer = "bun_engine"
+)
+
+func main() {
+	gcp.Main(detectFn, buildFn)
+}
+
+func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
+	pkgJSONExists, err := ctx.FileExists("package.json")
+	if err != nil {
+		return nil, err
+	}
+	if !pkgJSONExists {
+		return gcp.OptOutFileNotFound("package.json"), nil
+	}
+	pjs, err := nodejs.ReadPackageJSONIfExists(ctx.ApplicationRoot())
+	if err != nil {
+		return nil, err
+	}
+	usesBun, err := nodejs.UsesBun(ctx, pjs)
+	if err != nil {
+		return nil, err
+	}
+	if !usesBun {
+		return gcp.OptOut("neither bun.lock, bun.lockb nor packageManager bun found"), nil
+	}
+	return gcp.OptIn("found Bun project"), nil
+}
+
+func buildFn(ctx *gcp.Context) error {
+	pjs, err := nodejs.ReadPackageJSONIfExists(ctx.ApplicationRoot())
+	if err != nil {
+		return err
+	}
+	layer, err := ctx.Layer(bunLayer, gcp.BuildLayer, gcp.CacheLayer, gcp.LaunchLayer)
+	if err != nil {
+		return gcp.InternalErrorf("creating %v layer: %w", bunLayer, err)
+	}
+	if err := nodejs.InstallBun(ctx, layer, pjs); err != nil {
+		return err
+	}
+	// Bun reads registry credentials from .npmrc.
+	if err := ar.GenerateNPMConfig(ctx); err != nil {
+		return fmt.Errorf("generating Artifact Registry credentials: %w", err)
+	}
+
+	if err := bunInstallModules(ctx); err != nil {
+		return err
+	}
+
+	el, err := ctx.Layer("env", gcp.BuildLayer, gcp.LaunchLayer)
+	if err != nil {
+		return gcp.InternalErrorf("creating layer: %w", err)
+	}
+	el.SharedEnvironment.Prepend("PATH", string(os.PathListSeparator), filepath.Join(ctx.ApplicationRoot(), "node_modules", ".bin"))
+	el.SharedEnvironment.Default("NODE_ENV", nodejs.NodeEnv())
+
+	// Configure the entrypoint for production.
+	ctx.AddWebProcess(nodejs.BunStartCommand(pjs))
+	return nil
+}
+
+func bunInstallModules(ctx *gcp.Context) error {
+	pjs, err := nodejs.OverrideAppHostingBuildScript(ctx, nodejs.ApphostingPreprocessedPathForPack)
+	if err != nil {
+		return err
+	}
+	lockExists, err := nodejs.BunLockExists(ctx)
+	if err != nil {
+		return err
+	}
+	buildCmds, _ := nodejs.DetermineBuildCommands(pjs, "bun")
+	// Respect the user's NODE_ENV value if it's set
+	buildNodeEnv, nodeEnvPresent := os.LookupEnv(nodejs.EnvNodeEnv)
+	if !nodeEnvPresent {
+		if len(buildCmds) > 0 {
+			// Assume that dev dependencies are required to run build scripts to
+			// support the most use cases possible.
+			buildNodeEnv = nodejs.EnvDevelopment
+		} else {
+			buildNodeEnv = nodejs.EnvProduction
+		}
+	}
+	cmd := bunInstallCommand(lockExists, buildNodeEnv == nodejs.EnvProduction)
+	if _, err := ctx.Exec(cmd, gcp.WithUserAttribution, gcp.WithEnv("CI=true"), gcp.WithEnv("NODE_ENV="+buildNodeEnv)); err != nil {
+		return err
+	}
+	// If there are multiple build scripts to run, run them one-by-one so the logs are
+	// easier to understand.
+	for _, cmd := range buildCmds {
+		split, cmdEnv, err := nodejs.ParseBuildCommand(cmd)
+		if err != nil {
+			return err
+		}
+		if _, err := ctx.Exec(split, gcp.WithUserAttribution, gcp.WithEnv(cmdEnv...)); err != nil {
+			if fahCmd, fahCmdPresent := os.LookupEnv(nodejs.AppHostingBuildEnv); fahCmdPresent {
+				return gcp.UserErrorf("%w", faherror.FailedFrameworkBuildError(fahCmd, err))
+			}
+			if nodejs.HasApphostingPackageBuild(pjs) {
+				return gcp.UserErrorf("%w", faherror.FailedFrameworkBuildError(pjs.Scripts[nodejs.ScriptApphostingBuild], err))
+			}
+			return err
+		}
+	}
+	shouldPruneDevDependencies := buildNodeEnv == nodejs.EnvDevelopment && !nodeEnvPresent && nodejs.HasDevDependencies(pjs)
+	if shouldPruneDevDependencies {
+		// Bun has no prune command, reinstalling with --production removes the devDependencies
+		// from node_modules.
+		cmd := bunInstallCommand(lockExists, true)
+		if _, err := ctx.Exec(cmd, gcp.WithUserAttribution, gcp.WithEnv("CI=true")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bunInstallCommand returns the bun install command. The lock file is frozen when it exists so
+// that the build fails rather than installing versions different from the locked ones.
+func bunInstallCommand(lockExists, production bool) []string {
+	cmd := []string{"bun", "install"}
+	if lockExists {
+		cmd = append(cmd, "--frozen-lockfile")
+	}
+	if production {
+		cmd = append(cmd, "--production")
+	}
+	return cmd
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	buildpacktest "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		want  int
+	}{
+		{
+			name: "without package",
+			files: map[string]string{
+				"index.ts": "",
+				"bun.lock": "",
+			},
+			want: 100,
+		},
+		{
+			name: "with package without bun",
+			files: map[string]string{
+				"index.js":     "",
+				"package.json": "{}",
+			},
+			want: 100,
+		},
+		{
+			name: "with bun.lock",
+			files: map[string]string{
+				"index.ts":     "",
+				"bun.lock":     "",
+				"package.json": "{}",
+			},
+			want: 0,
+		},
+		{
+			name: "with bun.lockb",
+			files: map[string]string{
+				"index.ts":     "",
+				"bun.lockb":    "",
+				"package.json": "{}",
+			},
+			want: 0,
+		},
+		{
+			name: "with packageManager",
+			files: map[string]string{
+				"index.ts":     "",
+				"package.json": `{"packageManager": "bun@1.2.5"}`,
+			},
+			want: 0,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buildpacktest.TestDetect(t, detectFn, tc.name, tc.files, []string{}, tc.want)
+		})
+	}
+}
+
+func TestBunInstallCommand(t *testing.T) {
+	testCases := []struct {
+		name       string
+		lockExists bool
+		production bool
+		want       []string
+	}{
+		{
+			name: "without lock file",
+			want: []string{"bun", "install"},
+		},
+		{
+			name:       "with lock file",
+			lockExists: true,
+			want:       []string{"bun", "install", "--frozen-lockfile"},
+		},
+		{
+			name:       "production",
+			lockExists: true,
+			production: true,
+			want:       []string{"bun", "install", "--frozen-lockfile", "--production"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := bunInstallCommand(tc.lockExists, tc.production)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("bunInstallCommand(%v, %v) mismatch (-want +got):\n%s", tc.lockExists, tc.production, diff)
+			}
+		})
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/cloudfunctions"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/memlimit"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/nodejs"
 	"github.com/buildpacks/libcnb/v2"
 )
@@ -35,9 +36,6 @@ import (
 const (
 	layerName                 = "functions-framework"
 	functionsFrameworkPackage = "@google-cloud/functions-framework"
-
-	// nodeJSHeadroomMB is the amount of memory we'll set aside before computing the max memory size.
-	nodeJSHeadroomMB int = 64
 )
 
 var functionsFrameworkNodeModulePath = path.Join("node_modules", functionsFrameworkPackage)
@@ -191,12 +189,14 @@ func buildFn(ctx *gcp.Context) error {
 		}
 	}
 
-	// Get and set the valid value for --max-old-space-size node_options.
-	// Keep the existing behaviour if the value is not provided or invalid
-	if size, err := getMaxOldSpaceSize(); err != nil {
+	// The memlimit exec.d binary of the nodejs/runtime buildpack sets --max-old-space-size when the
+	// function starts, from the memory hint if the container has no cgroup memory limit. The function
+	// is served by a single process, which gets the whole heap.
+	l.LaunchEnvironment.Default("WEB_CONCURRENCY", "1")
+	if hint, err := memoryHint(); err != nil {
 		return err
-	} else if size > 0 {
-		l.LaunchEnvironment.Prepend("NODE_OPTIONS", " ", fmt.Sprintf("--max-old-space-size=%d", size))
+	} else if hint != "" {
+		l.LaunchEnvironment.Default(env.ContainerMemoryHintMB, hint)
 	}
 
 	if err := ctx.SetFunctionsEnvVars(l); err != nil {
@@ -244,24 +244,24 @@ func installFunctionsFramework(ctx *gcp.Context, l *libcnb.Layer) error {
 	return nil
 }
 
-// getMaxOldSpaceSize returns the memory size specified by (GOOGLE_CONTAINER_MEMORY_HINT_MB - nodeJSHeadroomMB),
-// or 0 if env var is not specified.
-func getMaxOldSpaceSize() (int, error) {
+// memoryHint returns GOOGLE_CONTAINER_MEMORY_HINT_MB, or an empty string if it is not set. The hint
+// must leave memlimit.NodeHeadroomMiB outside the V8 heap.
+func memoryHint() (string, error) {
 	memHintStr, exist := os.LookupEnv(env.ContainerMemoryHintMB)
 	if !exist {
-		return 0, nil
+		return "", nil
 	}
 
-	memHint, err := strconv.Atoi(memHintStr)
+	memHint, err := strconv.ParseInt(memHintStr, 10, 64)
 	if err != nil {
-		return 0, fmt.Errorf("%s=%q must be an integer: %v", env.ContainerMemoryHintMB, memHintStr, err)
+		return "", fmt.Errorf("%s=%q must be an integer: %v", env.ContainerMemoryHintMB, memHintStr, err)
 	}
 
-	if memHint <= nodeJSHeadroomMB {
-		return 0, fmt.Errorf("%s=%q must be greater than %d", env.ContainerMemoryHintMB, memHintStr, nodeJSHeadroomMB)
+	if memHint <= memlimit.NodeHeadroomMiB {
+		return "", fmt.Errorf("%s=%q must be greater than %d", env.ContainerMemoryHintMB, memHintStr, memlimit.NodeHeadroomMiB)
 	}
 
-	return memHint - nodeJSHeadroomMB, nil
+	return memHintStr, nil
 }
 
 // tryAddFrameworkVersionLabel attempts to identify the functions framework
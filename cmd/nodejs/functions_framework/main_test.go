@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	buildpacktest "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/memlimit"
 )
 
 func TestDetect(t *testing.T) {
@@ -58,11 +59,11 @@ func TestDetect(t *testing.T) {
 	}
 }
 
-func TestGetMaxOldSpaceSize(t *testing.T) {
+func TestMemoryHint(t *testing.T) {
 	testCases := []struct {
 		name    string
 		env     []string
-		want    int
+		want    string
 		wantErr bool
 	}{
 		{
@@ -75,7 +76,7 @@ func TestGetMaxOldSpaceSize(t *testing.T) {
 		},
 		{
 			name:    "env val set but less than head room",
-			env:     []string{"GOOGLE_CONTAINER_MEMORY_HINT_MB=" + strconv.Itoa(nodeJSHeadroomMB-1)},
+			env:     []string{"GOOGLE_CONTAINER_MEMORY_HINT_MB=" + strconv.FormatInt(memlimit.NodeHeadroomMiB-1, 10)},
 			wantErr: true,
 		},
 		{
@@ -95,13 +96,13 @@ func TestGetMaxOldSpaceSize(t *testing.T) {
 		},
 		{
 			name:    "env val set but equal to head room",
-			env:     []string{"GOOGLE_CONTAINER_MEMORY_HINT_MB=" + strconv.Itoa(nodeJSHeadroomMB)},
+			env:     []string{"GOOGLE_CONTAINER_MEMORY_HINT_MB=" + strconv.FormatInt(memlimit.NodeHeadroomMiB, 10)},
 			wantErr: true,
 		},
 		{
 			name: "env val set and greater than head room",
 			env:  []string{"GOOGLE_CONTAINER_MEMORY_HINT_MB=4096"},
-			want: 4096 - nodeJSHeadroomMB,
+			want: "4096",
 		},
 	}
 	for _, tc := range testCases {
@@ -110,14 +111,14 @@ func TestGetMaxOldSpaceSize(t *testing.T) {
 				setEnv(t, keyVal)
 			}
 
-			got, err := getMaxOldSpaceSize()
+			got, err := memoryHint()
 			gotErr := err != nil
 
 			if gotErr != tc.wantErr {
-				t.Errorf("getMaxOldSpaceSize() got err=%t, want err=%t. err: %v", gotErr, tc.wantErr, err)
+				t.Errorf("memoryHint() got err=%t, want err=%t. err: %v", gotErr, tc.wantErr, err)
 			}
 			if got != tc.want {
-				t.Errorf("getMaxOldSpaceSize()=%d, want=%d", got, tc.want)
+				t.Errorf("memoryHint()=%q, want=%q", got, tc.want)
 			}
 		})
 	}
@@ -20,7 +20,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/ar"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/buildermetrics"
@@ -29,6 +28,7 @@ import (
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/firebase/faherror"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/nodejs"
+	"github.com/buildpacks/libcnb/v2"
 )
 
 const (
@@ -51,7 +51,17 @@ func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
 }
 
 func buildFn(ctx *gcp.Context) error {
-	ml, err := ctx.Layer("npm_modules", gcp.BuildLayer, gcp.CacheLayer)
+	strategy, err := nodejs.ModulesStrategy()
+	if err != nil {
+		return err
+	}
+	var ml *libcnb.Layer
+	if strategy == nodejs.ModulesSymlink {
+		// The application node_modules directory links to the layer at launch time.
+		ml, err = ctx.Layer("npm_modules", gcp.BuildLayer, gcp.CacheLayer, gcp.LaunchLayer)
+	} else {
+		ml, err = ctx.Layer("npm_modules", gcp.BuildLayer, gcp.CacheLayer)
+	}
 	if err != nil {
 		return fmt.Errorf("creating layer: %w", err)
 	}
@@ -86,6 +96,13 @@ func buildFn(ctx *gcp.Context) error {
 	if err != nil {
 		return err
 	}
+	ws, wpkg, err := nodejs.BuildableWorkspacePackage(ctx)
+	if err != nil {
+		return err
+	}
+	if wpkg != nil {
+		return buildWorkspacePackage(ctx, ws, wpkg, pjs, ml, strategy, lockfile)
+	}
 	pjs, err = nodejs.OverrideAppHostingBuildScript(ctx, nodejs.ApphostingPreprocessedPathForPack)
 	if err != nil {
 		return err
@@ -109,19 +126,30 @@ func buildFn(ctx *gcp.Context) error {
 			return err
 		}
 	} else {
+		dc, err := nodejs.NewDownloadCache(ctx, "npm")
+		if err != nil {
+			return err
+		}
 		cached, err := nodejs.CheckOrClearCache(ctx, ml, cache.WithStrings(buildNodeEnv), cache.WithFiles("package.json", lockfile))
 		if err != nil {
 			return fmt.Errorf("checking cache: %w", err)
 		}
+		if strategy == nodejs.ModulesSymlink {
+			if err := linkModules(ctx, ml); err != nil {
+				return err
+			}
+		}
 		if cached {
 			// Restore cached node_modules.
-			if _, err := ctx.Exec([]string{"cp", "--archive", nm, "node_modules"}, gcp.WithUserTimingAttribution); err != nil {
-				return err
+			if strategy != nodejs.ModulesSymlink {
+				if _, err := nodejs.CopyModules(ctx, strategy, nm, "node_modules"); err != nil {
+					return err
+				}
 			}
 
 			// Always run npm install to run preinstall/postinstall scripts.
 			// Otherwise it should be a no-op because the lockfile is unchanged.
-			if _, err := ctx.Exec([]string{"npm", "install", "--quiet"}, gcp.WithEnv("NODE_ENV="+buildNodeEnv), gcp.WithUserAttribution); err != nil {
+			if _, err := ctx.Exec([]string{"npm", "install", "--quiet"}, gcp.WithEnv("NODE_ENV="+buildNodeEnv), gcp.WithEnv(dc.Env...), gcp.WithUserAttribution); err != nil {
 				return err
 			}
 		} else {
@@ -131,28 +159,41 @@ func buildFn(ctx *gcp.Context) error {
 				return err
 			}
 
-			if _, err := ctx.Exec([]string{"npm", installCmd, "--quiet", "--no-fund", "--no-audit"}, gcp.WithEnv("NODE_ENV="+buildNodeEnv), gcp.WithUserAttribution); err != nil {
+			if _, err := ctx.Exec([]string{"npm", installCmd, "--quiet", "--no-fund", "--no-audit"}, gcp.WithEnv("NODE_ENV="+buildNodeEnv), gcp.WithEnv(dc.Env...), gcp.WithUserAttribution); err != nil {
 				return err
 			}
 			// Ensure node_modules exists even if no dependencies were installed.
 			if err := ctx.MkdirAll("node_modules", 0755); err != nil {
 				return err
 			}
-			if _, err := ctx.Exec([]string{"cp", "--archive", "node_modules", nm}, gcp.WithUserTimingAttribution); err != nil {
+			if strategy != nodejs.ModulesSymlink {
+				if _, err := nodejs.CopyModules(ctx, strategy, "node_modules", nm); err != nil {
+					return err
+				}
+			}
+		}
+		if strategy == nodejs.ModulesSymlink {
+			if err := relinkModules(ctx, ml); err != nil {
 				return err
 			}
 		}
+		if err := dc.Evict(ctx); err != nil {
+			return err
+		}
 	}
 
 	if len(buildCmds) > 0 {
 		// If there are multiple build scripts to run, run them one-by-one so the logs are
 		// easier to understand.
 		for _, cmd := range buildCmds {
-			execOpts := []gcp.ExecOption{gcp.WithUserAttribution}
+			split, cmdEnv, err := nodejs.ParseBuildCommand(cmd)
+			if err != nil {
+				return err
+			}
+			execOpts := []gcp.ExecOption{gcp.WithUserAttribution, gcp.WithEnv(cmdEnv...)}
 			if nodejs.DetectSvelteKitAutoAdapter(pjs) {
 				execOpts = append(execOpts, gcp.WithEnv(nodejs.SvelteAdapterEnv))
 			}
-			split := strings.Split(cmd, " ")
 			if _, err := ctx.Exec(split, execOpts...); err != nil {
 				if !isCustomBuild {
 					return fmt.Errorf(`%w
@@ -177,8 +218,16 @@ NOTE: Running the default build script can be skipped by passing the empty envir
 			if _, err := ctx.Exec([]string{"npm", "prune", "--production"}, gcp.WithUserAttribution); err != nil {
 				return err
 			}
+			if strategy == nodejs.ModulesSymlink {
+				if err := relinkModules(ctx, ml); err != nil {
+					return err
+				}
+			}
 		}
 	}
+	if err := nodejs.SkipModulesAtLaunch(ctx, ml); err != nil {
+		return err
+	}
 
 	el, err := ctx.Layer("env", gcp.BuildLayer, gcp.LaunchLayer)
 	if err != nil {
@@ -209,6 +258,145 @@ NOTE: Running the default build script can be skipped by passing the empty envir
 	return nil
 }
 
+// linkModules links the application node_modules directory to the node_modules directory of the
+// layer, so that the dependencies are installed in the layer and never copied. NODE_PATH is set
+// for the tools resolving modules from their real path.
+func linkModules(ctx *gcp.Context, ml *libcnb.Layer) error {
+	nm := filepath.Join(ml.Path, "node_modules")
+	if err := ctx.MkdirAll(nm, 0755); err != nil {
+		return err
+	}
+	if err := ctx.Symlink(nm, "node_modules"); err != nil {
+		return err
+	}
+	ml.SharedEnvironment.Prepend("NODE_PATH", string(os.PathListSeparator), nm)
+	return nil
+}
+
+// relinkModules moves the application node_modules directory to the layer and links it again if
+// npm replaced the link with a directory, as npm ci does by removing node_modules before installing.
+func relinkModules(ctx *gcp.Context, ml *libcnb.Layer) error {
+	appModules := filepath.Join(ctx.ApplicationRoot(), "node_modules")
+	fi, err := os.Lstat(appModules)
+	if err != nil && !os.IsNotExist(err) {
+		return gcp.InternalErrorf("reading %s: %v", appModules, err)
+	}
+	exists := err == nil
+	if exists && fi.Mode()&os.ModeSymlink != 0 {
+		return nil
+	}
+	nm := filepath.Join(ml.Path, "node_modules")
+	if err := ctx.RemoveAll(nm); err != nil {
+		return err
+	}
+	if !exists {
+		if err := ctx.MkdirAll(nm, 0755); err != nil {
+			return err
+		}
+	} else if _, err := ctx.Exec([]string{"mv", appModules, nm}, gcp.WithUserTimingAttribution); err != nil {
+		return err
+	}
+	return ctx.Symlink(nm, appModules)
+}
+
+// buildWorkspacePackage installs the dependencies of a single package of an npm workspace, builds
+// it and configures it as the web process. The node_modules directories of the workspace root and
+// of the packages it needs are cached in the modules layer, the other workspace packages are
+// removed from the application.
+func buildWorkspacePackage(ctx *gcp.Context, ws *nodejs.Workspace, wpkg *nodejs.WorkspacePackage, rootPJS *nodejs.PackageJSON, ml *libcnb.Layer, strategy, lockfile string) error {
+	if strategy == nodejs.ModulesSymlink {
+		ctx.Warnf("%s=%s is not supported when building a workspace package, node_modules is copied instead.", nodejs.ModulesStrategyEnv, strategy)
+		strategy = nodejs.ModulesAuto
+		ml.Launch = false
+	}
+	if ws.Turbo {
+		if err := nodejs.TurboPrune(ctx, rootPJS, wpkg); err != nil {
+			return err
+		}
+	}
+	pjs, err := nodejs.ReadPackageJSONIfExists(filepath.Join(ctx.ApplicationRoot(), wpkg.Dir))
+	if err != nil {
+		return err
+	}
+	deps, err := ws.Dependencies(wpkg)
+	if err != nil {
+		return err
+	}
+	buildCmds := nodejs.WorkspaceBuildCommands("npm", ws, rootPJS, wpkg, pjs)
+	buildNodeEnv, nodeEnvPresent := os.LookupEnv(nodejs.EnvNodeEnv)
+	if !nodeEnvPresent {
+		buildNodeEnv = nodejs.EnvProduction
+		if len(buildCmds) > 0 {
+			buildNodeEnv = nodejs.EnvDevelopment
+		}
+	}
+
+	dc, err := nodejs.NewDownloadCache(ctx, "npm")
+	if err != nil {
+		return err
+	}
+	cached, err := nodejs.CheckOrClearCache(ctx, ml, cache.WithStrings(buildNodeEnv, wpkg.Name), cache.WithFiles(nodejs.WorkspaceCacheFiles(ws, deps, lockfile)...))
+	if err != nil {
+		return fmt.Errorf("checking cache: %w", err)
+	}
+	workspaceArgs := nodejs.WorkspaceInstallArgs("npm", wpkg)
+	if cached {
+		if err := nodejs.RestoreWorkspaceModules(ctx, strategy, ws, deps, ml.Path); err != nil {
+			return err
+		}
+		// npm ci would delete the restored node_modules, npm install only runs the lifecycle scripts
+		// as the lockfile is unchanged.
+		cmd := append([]string{"npm", "install", "--quiet", "--no-fund", "--no-audit"}, workspaceArgs...)
+		if _, err := ctx.Exec(cmd, gcp.WithEnv("NODE_ENV="+buildNodeEnv), gcp.WithEnv(dc.Env...), gcp.WithUserAttribution); err != nil {
+			return err
+		}
+	} else {
+		ctx.Logf("Installing dependencies of workspace package %s.", wpkg.Name)
+		installCmd, err := nodejs.NPMInstallCommand(ctx)
+		if err != nil {
+			return err
+		}
+		cmd := append([]string{"npm", installCmd, "--quiet", "--no-fund", "--no-audit"}, workspaceArgs...)
+		if _, err := ctx.Exec(cmd, gcp.WithEnv("NODE_ENV="+buildNodeEnv), gcp.WithEnv(dc.Env...), gcp.WithUserAttribution); err != nil {
+			return err
+		}
+		if err := nodejs.SaveWorkspaceModules(ctx, strategy, ws, deps, ml.Path); err != nil {
+			return err
+		}
+	}
+	if err := dc.Evict(ctx); err != nil {
+		return err
+	}
+	for _, cmd := range buildCmds {
+		if _, err := ctx.Exec(cmd, gcp.WithUserAttribution); err != nil {
+			return err
+		}
+	}
+	if len(buildCmds) > 0 {
+		shouldPrune, err := shouldPrune(ctx, pjs)
+		if err != nil {
+			return err
+		}
+		if shouldPrune {
+			if _, err := ctx.Exec(append([]string{"npm", "prune", "--production"}, workspaceArgs...), gcp.WithUserAttribution); err != nil {
+				return err
+			}
+		}
+	}
+	if err := nodejs.RemoveUnusedWorkspacePackages(ctx, ws, deps); err != nil {
+		return err
+	}
+
+	el, err := ctx.Layer("env", gcp.BuildLayer, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating layer: %w", err)
+	}
+	el.SharedEnvironment.Prepend("PATH", string(os.PathListSeparator), filepath.Join(ctx.ApplicationRoot(), "node_modules", ".bin"))
+	el.SharedEnvironment.Default("NODE_ENV", nodejs.NodeEnv())
+	ctx.AddWebProcess(nodejs.WorkspaceStartCommand("npm", wpkg, pjs))
+	return nil
+}
+
 func shouldPrune(ctx *gcp.Context, pjs *nodejs.PackageJSON) (bool, error) {
 	// if we are vendoring dependencies, we do not need to prune
 	if nodejs.IsUsingVendoredDependencies() {
@@ -243,21 +431,5 @@ func upgradeNPM(ctx *gcp.Context, pjs *nodejs.PackageJSON) error {
 	if err != nil {
 		return fmt.Errorf("creating layer: %w", err)
 	}
-	metaVersion := ctx.GetMetadata(npmLayer, "version")
-	if metaVersion == npmVersion {
-		ctx.Logf("npm@%s cache hit, skipping installation.", npmVersion)
-		return nil
-	}
-	ctx.ClearLayer(npmLayer)
-	prefix := fmt.Sprintf("--prefix=%s", npmLayer.Path)
-	pkg := fmt.Sprintf("npm@%s", npmVersion)
-	if _, err := ctx.Exec([]string{"npm", "install", "-g", prefix, pkg}, gcp.WithUserAttribution); err != nil {
-		return err
-	}
-	// Set the path here to ensure the version we just installed takes precedence over the npm bundled
-	// with the Node.js engine.
-	if err := ctx.Setenv("PATH", filepath.Join(npmLayer.Path, "bin")+":"+os.Getenv("PATH")); err != nil {
-		return err
-	}
-	return nil
+	return nodejs.InstallNPM(ctx, npmLayer, npmVersion, pjs)
 }
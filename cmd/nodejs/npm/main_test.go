@@ -16,11 +16,16 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	bpt "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
 	"github.com/GoogleCloudPlatform/buildpacks/internal/mockprocess"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/nodejs"
+	"github.com/buildpacks/libcnb/v2"
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestDetect(t *testing.T) {
@@ -190,3 +195,81 @@ func TestBuild(t *testing.T) {
 		})
 	}
 }
+
+func TestRelinkModules(t *testing.T) {
+	testCases := []struct {
+		name string
+		// install simulates the changes of npm to the application node_modules link.
+		install   func(appModules string) error
+		wantFiles []string
+	}{
+		{
+			name: "npm install keeps the link",
+			install: func(appModules string) error {
+				return os.WriteFile(filepath.Join(appModules, "express.js"), nil, 0644)
+			},
+			wantFiles: []string{"express.js", "stale.js"},
+		},
+		{
+			name: "npm ci replaces the link",
+			install: func(appModules string) error {
+				if err := os.Remove(appModules); err != nil {
+					return err
+				}
+				if err := os.Mkdir(appModules, 0755); err != nil {
+					return err
+				}
+				return os.WriteFile(filepath.Join(appModules, "express.js"), nil, 0644)
+			},
+			wantFiles: []string{"express.js"},
+		},
+		{
+			name: "npm ci without dependencies removes the link",
+			install: func(appModules string) error {
+				return os.Remove(appModules)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			app := t.TempDir()
+			ml := &libcnb.Layer{Path: t.TempDir()}
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(app))
+			appModules := filepath.Join(app, "node_modules")
+			nm := filepath.Join(ml.Path, "node_modules")
+			// A stale module cached in the layer.
+			if err := os.MkdirAll(nm, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(nm, "stale.js"), nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Symlink(nm, appModules); err != nil {
+				t.Fatal(err)
+			}
+			if err := tc.install(appModules); err != nil {
+				t.Fatalf("simulating npm: %v", err)
+			}
+
+			if err := relinkModules(ctx, ml); err != nil {
+				t.Fatalf("relinkModules() got error: %v", err)
+			}
+
+			if target, err := os.Readlink(appModules); err != nil || target != nm {
+				t.Errorf("node_modules links to %q (error: %v), want %q", target, err, nm)
+			}
+			entries, err := os.ReadDir(nm)
+			if err != nil {
+				t.Fatalf("reading layer node_modules: %v", err)
+			}
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.Name())
+			}
+			if diff := cmp.Diff(tc.wantFiles, got); diff != "" {
+				t.Errorf("layer node_modules mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
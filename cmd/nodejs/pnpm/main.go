@@ -19,7 +19,6 @@ package main
 import (
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/firebase/faherror"
@@ -28,8 +27,9 @@ import (
 )
 
 const (
-	cacheTag  = "prod dependencies"
-	pnpmLayer = "pnpm_engine"
+	cacheTag    = "prod dependencies"
+	pnpmLayer   = "pnpm_engine"
+	deployLayer = "deploy"
 )
 
 func main() {
@@ -64,6 +64,13 @@ func buildFn(ctx *gcp.Context) error {
 	if err := installPNPM(ctx, pjs); err != nil {
 		return gcp.InternalErrorf("installing pnpm: %w", err)
 	}
+	ws, wpkg, err := nodejs.BuildableWorkspacePackage(ctx)
+	if err != nil {
+		return err
+	}
+	if wpkg != nil {
+		return buildWorkspacePackage(ctx, ws, wpkg, pjs)
+	}
 
 	if err := pnpmInstallModules(ctx, pjs); err != nil {
 		return err
@@ -98,16 +105,26 @@ func pnpmInstallModules(ctx *gcp.Context, pjs *nodejs.PackageJSON) error {
 			buildNodeEnv = nodejs.EnvProduction
 		}
 	}
+	dc, err := nodejs.NewDownloadCache(ctx, "pnpm")
+	if err != nil {
+		return err
+	}
 	cmd := []string{"pnpm", "install"}
-	if _, err := ctx.Exec(cmd, gcp.WithUserAttribution, gcp.WithEnv("CI=true"), gcp.WithEnv("NODE_ENV="+buildNodeEnv)); err != nil {
+	if _, err := ctx.Exec(cmd, gcp.WithUserAttribution, gcp.WithEnv("CI=true"), gcp.WithEnv("NODE_ENV="+buildNodeEnv), gcp.WithEnv(dc.Env...)); err != nil {
 		return gcp.UserErrorf("installing pnpm dependencies: %w", err)
 	}
+	if err := dc.Evict(ctx); err != nil {
+		return err
+	}
 	if len(buildCmds) > 0 {
 		// If there are multiple build scripts to run, run them one-by-one so the logs are
 		// easier to understand.
 		for _, cmd := range buildCmds {
-			split := strings.Split(cmd, " ")
-			if _, err := ctx.Exec(split, gcp.WithUserAttribution); err != nil {
+			split, cmdEnv, err := nodejs.ParseBuildCommand(cmd)
+			if err != nil {
+				return err
+			}
+			if _, err := ctx.Exec(split, gcp.WithUserAttribution, gcp.WithEnv(cmdEnv...)); err != nil {
 				if fahCmd, fahCmdPresent := os.LookupEnv(nodejs.AppHostingBuildEnv); fahCmdPresent {
 					return gcp.UserErrorf("%w", faherror.FailedFrameworkBuildError(fahCmd, err))
 				}
@@ -128,13 +145,83 @@ func pnpmInstallModules(ctx *gcp.Context, pjs *nodejs.PackageJSON) error {
 		// If we installed dependencies with NODE_ENV=development and the user didn't explicitly set
 		// NODE_ENV we should prune the devDependencies from the final app image.
 		cmd := []string{"pnpm", "prune", "--prod"}
-		if _, err := ctx.Exec(cmd, gcp.WithUserAttribution, gcp.WithEnv("CI=true")); err != nil {
+		if _, err := ctx.Exec(cmd, gcp.WithUserAttribution, gcp.WithEnv("CI=true"), gcp.WithEnv(dc.Env...)); err != nil {
 			return gcp.UserErrorf("pruning devDependencies: %w", err)
 		}
 	}
 	return nil
 }
 
+// buildWorkspacePackage installs the dependencies of a single package of a pnpm workspace and
+// builds it, then deploys the package with its production dependencies to a launch layer with
+// pnpm deploy and configures it as the web process.
+func buildWorkspacePackage(ctx *gcp.Context, ws *nodejs.Workspace, wpkg *nodejs.WorkspacePackage, rootPJS *nodejs.PackageJSON) error {
+	if ws.Turbo {
+		if err := nodejs.TurboPrune(ctx, rootPJS, wpkg); err != nil {
+			return err
+		}
+	}
+	pjs, err := nodejs.ReadPackageJSONIfExists(filepath.Join(ctx.ApplicationRoot(), wpkg.Dir))
+	if err != nil {
+		return err
+	}
+	buildCmds := nodejs.WorkspaceBuildCommands("pnpm", ws, rootPJS, wpkg, pjs)
+	buildNodeEnv, nodeEnvPresent := os.LookupEnv(nodejs.EnvNodeEnv)
+	if !nodeEnvPresent {
+		buildNodeEnv = nodejs.EnvProduction
+		if len(buildCmds) > 0 {
+			buildNodeEnv = nodejs.EnvDevelopment
+		}
+	}
+	dc, err := nodejs.NewDownloadCache(ctx, "pnpm")
+	if err != nil {
+		return err
+	}
+	cmd := append([]string{"pnpm", "install"}, nodejs.WorkspaceInstallArgs("pnpm", wpkg)...)
+	if _, err := ctx.Exec(cmd, gcp.WithUserAttribution, gcp.WithEnv("CI=true"), gcp.WithEnv("NODE_ENV="+buildNodeEnv), gcp.WithEnv(dc.Env...)); err != nil {
+		return gcp.UserErrorf("installing pnpm dependencies: %w", err)
+	}
+	for _, cmd := range buildCmds {
+		if _, err := ctx.Exec(cmd, gcp.WithUserAttribution); err != nil {
+			return err
+		}
+	}
+
+	dl, err := ctx.Layer(deployLayer, gcp.LaunchLayer)
+	if err != nil {
+		return gcp.InternalErrorf("creating %v layer: %w", deployLayer, err)
+	}
+	// pnpm deploy requires an empty target directory.
+	if err := ctx.RemoveAll(dl.Path); err != nil {
+		return err
+	}
+	cmd = []string{"pnpm", "--filter=" + wpkg.Name, "deploy", dl.Path}
+	if nodejs.NodeEnv() == nodejs.EnvProduction {
+		cmd = append(cmd, "--prod")
+	}
+	// pnpm 10 only deploys packages of workspaces with inject-workspace-packages set, the legacy
+	// implementation deploys any workspace.
+	if _, err := ctx.Exec(cmd, gcp.WithUserAttribution, gcp.WithEnv("CI=true", "npm_config_force_legacy_deploy=true"), gcp.WithEnv(dc.Env...)); err != nil {
+		return gcp.UserErrorf("deploying workspace package %s: %w", wpkg.Name, err)
+	}
+	if err := dc.Evict(ctx); err != nil {
+		return err
+	}
+	dl.LaunchEnvironment.Prepend("PATH", string(os.PathListSeparator), filepath.Join(dl.Path, "node_modules", ".bin"))
+	dl.LaunchEnvironment.Default("NODE_ENV", nodejs.NodeEnv())
+
+	if nodejs.HasScript(pjs, "start") {
+		ctx.AddWebProcess([]string{"pnpm", "--dir", dl.Path, "run", "start"})
+		return nil
+	}
+	main := "index.js"
+	if pjs != nil && pjs.Main != "" {
+		main = pjs.Main
+	}
+	ctx.AddWebProcess([]string{"node", filepath.Join(dl.Path, main)})
+	return nil
+}
+
 func installPNPM(ctx *gcp.Context, pjs *nodejs.PackageJSON) error {
 	layer, err := ctx.Layer(pnpmLayer, gcp.BuildLayer, gcp.CacheLayer, gcp.LaunchLayer)
 	if err != nil {
@@ -18,12 +18,12 @@ package main
 
 import (
 	"fmt"
-	"io/ioutil"
 	"os"
-	"path/filepath"
+	"strconv"
 
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/memlimit"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/nodejs"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/ruby"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/runtime"
@@ -31,8 +31,9 @@ import (
 
 const (
 	nodeLayer           = "node"
-	heapsizeLayer       = "heapsize"
 	runtimeVersionLabel = "runtime_version"
+	// setHeapSizeEnv disables sizing the heap and web workers from the memory limit when false.
+	setHeapSizeEnv = "X_GOOGLE_SET_NODE_HEAP_SIZE"
 )
 
 func main() {
@@ -96,34 +97,34 @@ func buildFn(ctx *gcp.Context) error {
 	if _, err = runtime.InstallTarballIfNotCached(ctx, runtime.Nodejs, version, nrl); err != nil {
 		return fmt.Errorf("installing nodejs: %w", err)
 	}
-	setHeapSize, err := env.IsPresentAndTrue("X_GOOGLE_SET_NODE_HEAP_SIZE")
+	setHeapSize, err := setHeapSizeEnabled()
 	if err != nil {
-		return fmt.Errorf("checking X_GOOGLE_SET_NODE_HEAP_SIZE: %w", err)
+		return err
+	}
+	// Ruby on Rails apps only use Node.js to precompile assets, WEB_CONCURRENCY would configure Puma.
+	isRailsApp, err := ruby.NeedsRailsAssetPrecompile(ctx)
+	if err != nil {
+		return err
 	}
-	if setHeapSize {
-		if err = installHeapsizeScript(ctx); err != nil {
-			return fmt.Errorf("installing heapsize script: %w", err)
+	if setHeapSize && !isRailsApp {
+		// When the container starts, the memlimit binary sets NODE_OPTIONS=--max-old-space-size and
+		// WEB_CONCURRENCY from the memory limit of the container, unless they are set by the user.
+		if err = memlimit.Install(ctx, memlimit.Nodejs); err != nil {
+			return fmt.Errorf("installing memlimit: %w", err)
 		}
 	}
-	return err
+	return nil
 }
 
-// installHeapsizeScript copies the exec/heapsize.sh script into the layer's exec.d directory.
-func installHeapsizeScript(ctx *gcp.Context) error {
-	l, err := ctx.Layer(heapsizeLayer, gcp.LaunchLayer)
-	if err != nil {
-		return fmt.Errorf("creating %v layer: %w", heapsizeLayer, err)
+// setHeapSizeEnabled returns true unless X_GOOGLE_SET_NODE_HEAP_SIZE is false.
+func setHeapSizeEnabled() (bool, error) {
+	v, ok := os.LookupEnv(setHeapSizeEnv)
+	if !ok || v == "" {
+		return true, nil
 	}
-	ctx.Logf("Installing the heapsize.sh exec.d script.")
-	scriptPath := filepath.Join(ctx.BuildpackRoot(), "exec", "heapsize.sh")
-	destPath := filepath.Join(l.Exec.Path, "heapsize.sh")
-	data, err := ioutil.ReadFile(scriptPath)
+	enabled, err := strconv.ParseBool(v)
 	if err != nil {
-		return fmt.Errorf("reading %s: %w", scriptPath, err)
+		return false, gcp.UserErrorf("parsing %s=%q: %v", setHeapSizeEnv, v, err)
 	}
-	ctx.MkdirAll(l.Exec.Path, 0755)
-	if err := ioutil.WriteFile(destPath, data, 0777); err != nil {
-		return fmt.Errorf("writing %s: %w", destPath, err)
-	}
-	return nil
+	return enabled, nil
 }
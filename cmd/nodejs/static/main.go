@@ -0,0 +1,103 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Implements nodejs/static buildpack.
+// The static buildpack serves the static files of single page applications with nginx.
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/nginx"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/nodejs"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/runtime"
+)
+
+const (
+	// nginxVerConstraint is used to control updating to a new major version with any potential breaking change.
+	nginxVerConstraint = "^1.21.6"
+	nginxLayer         = "nginx"
+	staticLayer        = "static"
+	// portPlaceholder is replaced by $PORT in the nginx config when the server starts.
+	portPlaceholder = "@PORT@"
+	// runtimeConfPath is the nginx config written when the server starts, /tmp is writable at runtime.
+	runtimeConfPath = "/tmp/nginx-static.conf"
+)
+
+func main() {
+	gcp.Main(detectFn, buildFn)
+}
+
+func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
+	reason, err := nodejs.StaticSPAOptOutReason(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		return gcp.OptOut(reason), nil
+	}
+	return gcp.OptIn("found single page application"), nil
+}
+
+func buildFn(ctx *gcp.Context) error {
+	outputDir, err := nodejs.StaticOutputDir(ctx)
+	if err != nil {
+		return err
+	}
+	if outputDir == "" {
+		ctx.Warnf("No index.html found in dist/ or build/ after the build, the application is started with Node.js. Set %s=false to disable static file serving.", nodejs.StaticSiteEnv)
+		return nil
+	}
+	ctx.Logf("Serving the static files in %s with nginx.", outputDir)
+
+	nl, err := ctx.Layer(nginxLayer, gcp.BuildLayer, gcp.CacheLayer, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", nginxLayer, err)
+	}
+	if _, err := runtime.InstallTarballIfNotCached(ctx, runtime.Nginx, nginxVerConstraint, nl); err != nil {
+		return err
+	}
+
+	sl, err := ctx.Layer(staticLayer, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", staticLayer, err)
+	}
+	confTemplate, err := nginx.WriteStaticConfigToPath(sl.Path, nginx.StaticConfig{
+		Port:          portPlaceholder,
+		Root:          filepath.Join(ctx.ApplicationRoot(), outputDir),
+		MimeTypesPath: filepath.Join(nl.Path, "conf", "mime.types"),
+		TempPath:      filepath.Dir(runtimeConfPath),
+	})
+	if err != nil {
+		return err
+	}
+
+	// The static files are served by nginx, node_modules is not needed at runtime. This buildpack
+	// runs last in its group, so only the launch image is affected. The package manager buildpacks
+	// do not launch the layers node_modules may link to, see nodejs.ServesStaticSPA.
+	if err := ctx.RemoveAll("node_modules"); err != nil {
+		return err
+	}
+
+	ctx.AddProcess(gcp.WebProcess, []string{startCommand(filepath.Join(nl.Path, "sbin", "nginx"), confTemplate)}, gcp.AsDefaultProcess())
+	return nil
+}
+
+// startCommand returns the shell command replacing the port placeholder with $PORT in the nginx
+// config, as nginx does not read environment variables, and starting nginx.
+func startCommand(nginxBin, confTemplate string) string {
+	return fmt.Sprintf(`sed "s/%s/${PORT:-8080}/g" %s > %s && exec %s -e stderr -c %s`, portPlaceholder, confTemplate, runtimeConfPath, nginxBin, runtimeConfPath)
+}
@@ -0,0 +1,116 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	buildpacktest "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		env   []string
+		want  int
+	}{
+		{
+			name:  "without package.json",
+			files: map[string]string{"index.html": ""},
+			want:  100,
+		},
+		{
+			name: "vite app",
+			files: map[string]string{
+				"package.json": `{"scripts": {"dev": "vite", "build": "vite build"}, "devDependencies": {"vite": "^6.0.0"}}`,
+			},
+			want: 0,
+		},
+		{
+			name: "create react app",
+			files: map[string]string{
+				"package.json": `{"scripts": {"start": "react-scripts start", "build": "react-scripts build"}, "dependencies": {"react-scripts": "5.0.1"}}`,
+			},
+			want: 0,
+		},
+		{
+			name: "vite app with a server",
+			files: map[string]string{
+				"package.json": `{"scripts": {"start": "node server.js", "build": "vite build"}, "devDependencies": {"vite": "^6.0.0"}}`,
+			},
+			want: 100,
+		},
+		{
+			name: "sveltekit app",
+			files: map[string]string{
+				"package.json": `{"scripts": {"build": "vite build"}, "devDependencies": {"vite": "^6.0.0", "@sveltejs/kit": "^2.0.0"}}`,
+			},
+			want: 100,
+		},
+		{
+			name: "express app",
+			files: map[string]string{
+				"package.json": `{"dependencies": {"express": "^4.21.0"}}`,
+			},
+			want: 100,
+		},
+		{
+			name: "Procfile",
+			files: map[string]string{
+				"package.json": `{"devDependencies": {"vite": "^6.0.0"}}`,
+				"Procfile":     "web: npx serve dist",
+			},
+			want: 100,
+		},
+		{
+			name: "entrypoint",
+			files: map[string]string{
+				"package.json": `{"devDependencies": {"vite": "^6.0.0"}}`,
+			},
+			env:  []string{"GOOGLE_ENTRYPOINT=npx serve dist"},
+			want: 100,
+		},
+		{
+			name: "function target",
+			files: map[string]string{
+				"package.json": `{"devDependencies": {"vite": "^6.0.0"}}`,
+			},
+			env:  []string{"GOOGLE_FUNCTION_TARGET=helloWorld"},
+			want: 100,
+		},
+		{
+			name: "disabled",
+			files: map[string]string{
+				"package.json": `{"devDependencies": {"vite": "^6.0.0"}}`,
+			},
+			env:  []string{"GOOGLE_NODE_STATIC_SITE=false"},
+			want: 100,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buildpacktest.TestDetect(t, detectFn, tc.name, tc.files, tc.env, tc.want)
+		})
+	}
+}
+
+func TestStartCommand(t *testing.T) {
+	got := startCommand("/layers/google.nodejs.static/nginx/sbin/nginx", "/layers/google.nodejs.static/static/nginx-static.conf")
+	want := `sed "s/@PORT@/${PORT:-8080}/g" /layers/google.nodejs.static/static/nginx-static.conf > /tmp/nginx-static.conf && exec /layers/google.nodejs.static/nginx/sbin/nginx -e stderr -c /tmp/nginx-static.conf`
+	if got != want {
+		t.Errorf("startCommand() = %q, want %q", got, want)
+	}
+}
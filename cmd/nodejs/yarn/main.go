@@ -29,11 +29,14 @@ import (
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/firebase/faherror"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/nodejs"
+	"github.com/buildpacks/libcnb/v2"
 )
 
 const (
 	cacheTag  = "prod dependencies"
 	yarnLayer = "yarn_engine"
+	// pnpCacheLayer keeps the package archives loaded by Plug'n'Play at launch time.
+	pnpCacheLayer = "yarn_pnp_cache"
 )
 
 func main() {
@@ -68,17 +71,29 @@ func buildFn(ctx *gcp.Context) error {
 	if err := installYarn(ctx, pjs); err != nil {
 		return fmt.Errorf("installing Yarn: %w", err)
 	}
+	ws, wpkg, err := nodejs.BuildableWorkspacePackage(ctx)
+	if err != nil {
+		return err
+	}
+	if wpkg != nil {
+		return buildWorkspacePackage(ctx, ws, wpkg, pjs)
+	}
 
-	if yarn2, err := nodejs.IsYarn2(ctx.ApplicationRoot()); err != nil {
+	yarn2, err := nodejs.IsYarn2(ctx.ApplicationRoot())
+	if err != nil {
 		return err
-	} else if yarn2 {
-		if err := yarn2InstallModules(ctx, pjs); err != nil {
-			return err
-		}
+	}
+	var ml *libcnb.Layer
+	if yarn2 {
+		ml, err = yarn2InstallModules(ctx, pjs)
 	} else {
-		if err := yarn1InstallModules(ctx, pjs); err != nil {
-			return err
-		}
+		ml, err = yarn1InstallModules(ctx, pjs)
+	}
+	if err != nil {
+		return err
+	}
+	if err := nodejs.SkipModulesAtLaunch(ctx, ml); err != nil {
+		return err
 	}
 
 	el, err := ctx.Layer("env", gcp.BuildLayer, gcp.LaunchLayer)
@@ -87,6 +102,9 @@ func buildFn(ctx *gcp.Context) error {
 	}
 	el.SharedEnvironment.Prepend("PATH", string(os.PathListSeparator), filepath.Join(ctx.ApplicationRoot(), "node_modules", ".bin"))
 	el.SharedEnvironment.Default("NODE_ENV", nodejs.NodeEnv())
+	if err := addPnPNodeOptions(ctx, el); err != nil {
+		return err
+	}
 
 	// Configure the entrypoint for production.
 	cmd := []string{"yarn", "run", "start"}
@@ -107,24 +125,26 @@ func buildFn(ctx *gcp.Context) error {
 	return nil
 }
 
-func yarn1InstallModules(ctx *gcp.Context, pjs *nodejs.PackageJSON) error {
+// yarn1InstallModules installs the dependencies with Yarn classic in a launch layer linked to the
+// application node_modules directory, and returns the layer.
+func yarn1InstallModules(ctx *gcp.Context, pjs *nodejs.PackageJSON) (*libcnb.Layer, error) {
 	freezeLockfile, err := nodejs.UseFrozenLockfile(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	ml, err := ctx.Layer("yarn_modules", gcp.BuildLayer, gcp.CacheLayer, gcp.LaunchLayer)
 	if err != nil {
-		return fmt.Errorf("creating layer: %w", err)
+		return nil, fmt.Errorf("creating layer: %w", err)
 	}
 
 	if err := ar.GenerateNPMConfig(ctx); err != nil {
-		return fmt.Errorf("generating Artifact Registry credentials: %w", err)
+		return nil, fmt.Errorf("generating Artifact Registry credentials: %w", err)
 	}
 
 	_, err = nodejs.CheckOrClearCache(ctx, ml, cache.WithFiles("package.json", nodejs.YarnLock))
 	if err != nil {
-		return fmt.Errorf("checking cache: %w", err)
+		return nil, fmt.Errorf("checking cache: %w", err)
 	}
 
 	// Use Yarn's --modules-folder flag to install directly into the layer and then symlink them into
@@ -132,32 +152,36 @@ func yarn1InstallModules(ctx *gcp.Context, pjs *nodejs.PackageJSON) error {
 	layerModules := filepath.Join(ml.Path, "node_modules")
 	appModules := filepath.Join(ctx.ApplicationRoot(), "node_modules")
 	if err := ctx.MkdirAll(layerModules, 0755); err != nil {
-		return err
+		return nil, err
 	}
 	if err := ctx.RemoveAll(appModules); err != nil {
-		return err
+		return nil, err
 	}
 	if err := ctx.Symlink(layerModules, appModules); err != nil {
-		return err
+		return nil, err
 	}
 	locationFlag := fmt.Sprintf("--modules-folder=%s", layerModules)
 
 	runtimeconfigJSONExists, err := ctx.FileExists(".runtimeconfig.json")
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// This is a hack to fix a bug in an old version of Firebase that loaded a config using a path
 	// relative to node_modules: https://github.com/firebase/firebase-functions/issues/630.
 	if runtimeconfigJSONExists {
 		layerConfig := filepath.Join(ml.Path, ".runtimeconfig.json")
 		if err := ctx.RemoveAll(layerConfig); err != nil {
-			return err
+			return nil, err
 		}
 		if err := ctx.Symlink(filepath.Join(ctx.ApplicationRoot(), ".runtimeconfig.json"), layerConfig); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
+	dc, err := nodejs.NewDownloadCache(ctx, "yarn")
+	if err != nil {
+		return nil, err
+	}
 	// Always run yarn install to execute customer's lifecycle hooks.
 	cmd := []string{"yarn", "install", "--non-interactive", "--prefer-offline", locationFlag}
 
@@ -176,26 +200,33 @@ func yarn1InstallModules(ctx *gcp.Context, pjs *nodejs.PackageJSON) error {
 
 	// Add the layer's node_modules/.bin to the path so it is available in postinstall scripts.
 	nodeBin := filepath.Join(layerModules, ".bin")
-	if _, err := ctx.Exec(cmd, gcp.WithUserAttribution, gcp.WithEnv(fmt.Sprintf("PATH=%s:%s", os.Getenv("PATH"), nodeBin))); err != nil {
-		return err
+	if _, err := ctx.Exec(cmd, gcp.WithUserAttribution, gcp.WithEnv(fmt.Sprintf("PATH=%s:%s", os.Getenv("PATH"), nodeBin)), gcp.WithEnv(dc.Env...)); err != nil {
+		return nil, err
+	}
+	if err := dc.Evict(ctx); err != nil {
+		return nil, err
 	}
 	pjs, err = nodejs.OverrideAppHostingBuildScript(ctx, nodejs.ApphostingPreprocessedPathForPack)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	appHostingBuildScriptPresent := nodejs.HasApphostingPackageBuild(pjs)
 	if gcpBuild || appHostingBuildEnvPresent || appHostingBuildScriptPresent {
 		if appHostingBuildScriptPresent {
 			if _, err := ctx.Exec([]string{"yarn", "run", "apphosting:build"}, gcp.WithUserAttribution); err != nil {
-				return gcp.UserErrorf("%w", faherror.FailedFrameworkBuildError(pjs.Scripts[nodejs.ScriptApphostingBuild], err))
+				return nil, gcp.UserErrorf("%w", faherror.FailedFrameworkBuildError(pjs.Scripts[nodejs.ScriptApphostingBuild], err))
 			}
 		} else if appHostingBuildEnvPresent {
-			if _, err := ctx.Exec(strings.Split(appHostingBuildEnv, " "), gcp.WithUserAttribution); err != nil {
-				return gcp.UserErrorf("%w", faherror.FailedFrameworkBuildError(appHostingBuildEnv, err))
+			split, cmdEnv, err := nodejs.ParseBuildCommand(appHostingBuildEnv)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := ctx.Exec(split, gcp.WithUserAttribution, gcp.WithEnv(cmdEnv...)); err != nil {
+				return nil, gcp.UserErrorf("%w", faherror.FailedFrameworkBuildError(appHostingBuildEnv, err))
 			}
 		} else {
 			if _, err := ctx.Exec([]string{"yarn", "run", "gcp-build"}, gcp.WithUserAttribution); err != nil {
-				return err
+				return nil, err
 			}
 		}
 
@@ -208,7 +239,7 @@ func yarn1InstallModules(ctx *gcp.Context, pjs *nodejs.PackageJSON) error {
 			if env.IsFAH() {
 				// We don't prune if the user is using App Hosting since App Hosting builds don't
 				// rely on the node_modules folder at this point.
-				return nil
+				return ml, nil
 			}
 			// For Yarn1, setting `--production=true` causes all `devDependencies` to be deleted.
 			ctx.Logf("Pruning devDependencies")
@@ -216,24 +247,26 @@ func yarn1InstallModules(ctx *gcp.Context, pjs *nodejs.PackageJSON) error {
 			if freezeLockfile {
 				cmd = append(cmd, "--frozen-lockfile")
 			}
-			if _, err := ctx.Exec(cmd, gcp.WithUserAttribution); err != nil {
-				return err
+			if _, err := ctx.Exec(cmd, gcp.WithUserAttribution, gcp.WithEnv(dc.Env...)); err != nil {
+				return nil, err
 			}
 		}
 	}
 
-	return nil
+	return ml, nil
 }
 
-func yarn2InstallModules(ctx *gcp.Context, pjs *nodejs.PackageJSON) error {
+// yarn2InstallModules installs the dependencies with Yarn 2+, and returns the launch layer holding
+// the Plug'n'Play package archives, if any.
+func yarn2InstallModules(ctx *gcp.Context, pjs *nodejs.PackageJSON) (*libcnb.Layer, error) {
 	if err := ar.GenerateYarnConfig(ctx); err != nil {
-		return fmt.Errorf("generating Artifact Registry credentials: %w", err)
+		return nil, fmt.Errorf("generating Artifact Registry credentials: %w", err)
 	}
 
 	cmd := []string{"yarn", "install", "--immutable"}
 	yarnCacheExists, err := ctx.FileExists(ctx.ApplicationRoot(), ".yarn", "cache")
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// In Plug'n'Play mode (https://yarnpkg.com/features/pnp) all dependencies must be included in
 	// the Yarn cache. The --immutable-cache option will abort the install with an error if anything
@@ -241,47 +274,251 @@ func yarn2InstallModules(ctx *gcp.Context, pjs *nodejs.PackageJSON) error {
 	if yarnCacheExists {
 		cmd = append(cmd, "--immutable-cache")
 	}
-	if _, err := ctx.Exec(cmd, gcp.WithUserAttribution); err != nil {
-		return err
+	dc, err := nodejs.NewDownloadCache(ctx, "yarn")
+	if err != nil {
+		return nil, err
+	}
+	yarnEnv, cl, err := yarn2Env(ctx, dc, yarnCacheExists)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ctx.Exec(cmd, gcp.WithUserAttribution, gcp.WithEnv(yarnEnv...)); err != nil {
+		return nil, err
+	}
+	if err := dc.Evict(ctx); err != nil {
+		return nil, err
 	}
 
 	if gcpBuild := nodejs.HasGCPBuild(pjs); gcpBuild {
-		if _, err := ctx.Exec([]string{"yarn", "run", "gcp-build"}, gcp.WithUserAttribution); err != nil {
-			return err
+		if _, err := ctx.Exec([]string{"yarn", "run", "gcp-build"}, gcp.WithUserAttribution, gcp.WithEnv(yarnEnv...)); err != nil {
+			return nil, err
 		}
 	}
 	if appHostingBuildScript, ok := os.LookupEnv(nodejs.AppHostingBuildEnv); ok {
-		if _, err := ctx.Exec(strings.Split(appHostingBuildScript, " "), gcp.WithUserAttribution); err != nil {
-			return err
+		split, cmdEnv, err := nodejs.ParseBuildCommand(appHostingBuildScript)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := ctx.Exec(split, gcp.WithUserAttribution, gcp.WithEnv(cmdEnv...)); err != nil {
+			return nil, err
 		}
 	}
 
 	// If there are no devDependencies, there is nothing to prune. We are done.
 	if !nodejs.HasDevDependencies(pjs) {
-		return nil
+		return cl, nil
 	}
 
 	nodeEnv := nodejs.NodeEnv()
 	if nodeEnv != nodejs.EnvProduction {
 		ctx.Logf("Retaining devDependencies because NODE_ENV=%q", nodeEnv)
-		return nil
+		return cl, nil
 	}
 	hasWorkPlugin, err := nodejs.HasYarnWorkspacePlugin(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if !hasWorkPlugin {
 		ctx.Warnf("Keeping devDependencies because the Yarn workspace-tools plugin is not installed. You can add it to your project by running 'yarn plugin import workspace-tools'")
-		return nil
+		return cl, nil
 	}
-	// For Yarn2, dependency pruning is via the workspaces plugin.
+	// For Yarn2, dependency pruning is via the workspaces plugin. With Plug'n'Play, it regenerates
+	// the .pnp.cjs map with the production dependencies and removes the archives of the
+	// devDependencies from the project cache.
 	ctx.Logf("Pruning devDependencies")
-	if _, err := ctx.Exec([]string{"yarn", "workspaces", "focus", "--all", "--production"}, gcp.WithUserAttribution); err != nil {
+	if _, err := ctx.Exec([]string{"yarn", "workspaces", "focus", "--all", "--production"}, gcp.WithUserAttribution, gcp.WithEnv(yarnEnv...)); err != nil {
+		return nil, err
+	}
+	return cl, nil
+}
+
+// yarn2Env returns the environment of the Yarn 2+ commands installing the dependencies. Unless they
+// are committed to the repository, the package archives of a Plug'n'Play project are installed in
+// a launch layer since they are loaded at launch time, copied from the download cache. The layer is
+// returned if it is used.
+func yarn2Env(ctx *gcp.Context, dc *nodejs.DownloadCache, yarnCacheExists bool) ([]string, *libcnb.Layer, error) {
+	pnp, err := nodejs.IsYarnPnP(ctx.ApplicationRoot())
+	if err != nil {
+		return nil, nil, err
+	}
+	if !pnp || yarnCacheExists {
+		return dc.Env, nil, nil
+	}
+	cl, err := ctx.Layer(pnpCacheLayer, gcp.CacheLayer, gcp.LaunchLayer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating %v layer: %w", pnpCacheLayer, err)
+	}
+	pnpEnv := nodejs.YarnPnPCacheEnv(cl.Path)
+	// Yarn commands run at launch time use the same cache.
+	for _, e := range pnpEnv {
+		name, value, _ := strings.Cut(e, "=")
+		cl.LaunchEnvironment.Default(name, value)
+	}
+	return append(append([]string{}, dc.Env...), pnpEnv...), cl, nil
+}
+
+// addPnPNodeOptions loads the Plug'n'Play runtime in the Node.js processes of the application, so
+// that entrypoints not started with Yarn resolve the dependencies.
+func addPnPNodeOptions(ctx *gcp.Context, el *libcnb.Layer) error {
+	opts, err := nodejs.YarnPnPNodeOptions(ctx.ApplicationRoot())
+	if err != nil {
 		return err
 	}
+	if opts != "" {
+		el.LaunchEnvironment.Append("NODE_OPTIONS", " ", opts)
+	}
 	return nil
 }
 
+// buildWorkspacePackage installs the dependencies of a single package of a Yarn workspace, builds
+// it and configures it as the web process. Yarn classic cannot restrict the installation to a
+// package: it installs the dependencies of the whole workspace and hoists them to the root
+// node_modules directory, which is cached in the modules layer. Yarn 2+ caches the package
+// archives instead. The workspace packages the package does not need are removed from the
+// application.
+func buildWorkspacePackage(ctx *gcp.Context, ws *nodejs.Workspace, wpkg *nodejs.WorkspacePackage, rootPJS *nodejs.PackageJSON) error {
+	if ws.Turbo {
+		if err := nodejs.TurboPrune(ctx, rootPJS, wpkg); err != nil {
+			return err
+		}
+	}
+	pjs, err := nodejs.ReadPackageJSONIfExists(filepath.Join(ctx.ApplicationRoot(), wpkg.Dir))
+	if err != nil {
+		return err
+	}
+	deps, err := ws.Dependencies(wpkg)
+	if err != nil {
+		return err
+	}
+	buildCmds := nodejs.WorkspaceBuildCommands("yarn", ws, rootPJS, wpkg, pjs)
+	yarn2, err := nodejs.IsYarn2(ctx.ApplicationRoot())
+	if err != nil {
+		return err
+	}
+	focus := false
+	if yarn2 {
+		if err := ar.GenerateYarnConfig(ctx); err != nil {
+			return fmt.Errorf("generating Artifact Registry credentials: %w", err)
+		}
+		if focus, err = nodejs.HasYarnWorkspacePlugin(ctx); err != nil {
+			return err
+		}
+		if !focus {
+			ctx.Warnf("Installing the dependencies of all workspace packages because the Yarn workspace-tools plugin is not installed. You can add it to your project by running 'yarn plugin import workspace-tools'")
+		}
+	} else if err := ar.GenerateNPMConfig(ctx); err != nil {
+		return fmt.Errorf("generating Artifact Registry credentials: %w", err)
+	}
+
+	var cmd []string
+	switch {
+	case focus:
+		cmd = []string{"yarn", "workspaces", "focus", wpkg.Name}
+	case yarn2:
+		cmd = []string{"yarn", "install", "--immutable"}
+	default:
+		cmd = []string{"yarn", "install", "--non-interactive", "--prefer-offline", "--frozen-lockfile"}
+		if len(buildCmds) > 0 {
+			// Install the devDependencies regardless of NODE_ENV for the build scripts, they are
+			// pruned below.
+			cmd = append(cmd, "--production=false")
+		}
+	}
+	dc, err := nodejs.NewDownloadCache(ctx, "yarn")
+	if err != nil {
+		return err
+	}
+	yarnEnv := dc.Env
+	if yarn2 {
+		yarnCacheExists, err := ctx.FileExists(ctx.ApplicationRoot(), ".yarn", "cache")
+		if err != nil {
+			return err
+		}
+		if yarnEnv, _, err = yarn2Env(ctx, dc, yarnCacheExists); err != nil {
+			return err
+		}
+	}
+	if yarn2 {
+		if _, err := ctx.Exec(cmd, gcp.WithUserAttribution, gcp.WithEnv(yarnEnv...)); err != nil {
+			return err
+		}
+	} else if err := yarn1InstallWorkspaceModules(ctx, ws, wpkg, deps, cmd, yarnEnv); err != nil {
+		return err
+	}
+	if err := dc.Evict(ctx); err != nil {
+		return err
+	}
+	for _, cmd := range buildCmds {
+		if _, err := ctx.Exec(cmd, gcp.WithUserAttribution, gcp.WithEnv(yarnEnv...)); err != nil {
+			return err
+		}
+	}
+
+	// Without the workspace-tools plugin Yarn 2+ cannot prune devDependencies.
+	prune := len(buildCmds) > 0 && nodejs.HasDevDependencies(pjs) && nodejs.NodeEnv() == nodejs.EnvProduction && (focus || !yarn2)
+	if prune && !env.IsFAH() {
+		ctx.Logf("Pruning devDependencies")
+		cmd := []string{"yarn", "workspaces", "focus", "--production", wpkg.Name}
+		if !yarn2 {
+			cmd = []string{"yarn", "install", "--ignore-scripts", "--prefer-offline", "--production=true", "--frozen-lockfile"}
+		}
+		if _, err := ctx.Exec(cmd, gcp.WithUserAttribution, gcp.WithEnv(yarnEnv...)); err != nil {
+			return err
+		}
+	}
+
+	if err := nodejs.RemoveUnusedWorkspacePackages(ctx, ws, deps); err != nil {
+		return err
+	}
+
+	el, err := ctx.Layer("env", gcp.BuildLayer, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating layer: %w", err)
+	}
+	el.SharedEnvironment.Prepend("PATH", string(os.PathListSeparator), filepath.Join(ctx.ApplicationRoot(), "node_modules", ".bin"))
+	el.SharedEnvironment.Default("NODE_ENV", nodejs.NodeEnv())
+	if err := addPnPNodeOptions(ctx, el); err != nil {
+		return err
+	}
+	ctx.AddWebProcess(nodejs.WorkspaceStartCommand("yarn", wpkg, pjs))
+	return nil
+}
+
+// yarn1InstallWorkspaceModules runs the Yarn classic install command of a workspace package. The
+// node_modules directories of the workspace root and of the packages it needs are restored from
+// the modules layer before the install, which then only runs the lifecycle scripts, or saved to it
+// after the install.
+func yarn1InstallWorkspaceModules(ctx *gcp.Context, ws *nodejs.Workspace, wpkg *nodejs.WorkspacePackage, deps []nodejs.WorkspacePackage, cmd, yarnEnv []string) error {
+	strategy, err := nodejs.ModulesStrategy()
+	if err != nil {
+		return err
+	}
+	if strategy == nodejs.ModulesSymlink {
+		ctx.Warnf("%s=%s is not supported when building a workspace package, node_modules is copied instead.", nodejs.ModulesStrategyEnv, strategy)
+		strategy = nodejs.ModulesAuto
+	}
+	ml, err := ctx.Layer("yarn_modules", gcp.BuildLayer, gcp.CacheLayer)
+	if err != nil {
+		return fmt.Errorf("creating layer: %w", err)
+	}
+	cached, err := nodejs.CheckOrClearCache(ctx, ml, cache.WithStrings(append([]string{nodejs.NodeEnv(), wpkg.Name}, cmd...)...), cache.WithFiles(nodejs.WorkspaceCacheFiles(ws, deps, nodejs.YarnLock)...))
+	if err != nil {
+		return fmt.Errorf("checking cache: %w", err)
+	}
+	if cached {
+		if err := nodejs.RestoreWorkspaceModules(ctx, strategy, ws, deps, ml.Path); err != nil {
+			return err
+		}
+	}
+	if _, err := ctx.Exec(cmd, gcp.WithUserAttribution, gcp.WithEnv(yarnEnv...)); err != nil {
+		return err
+	}
+	if cached {
+		return nil
+	}
+	return nodejs.SaveWorkspaceModules(ctx, strategy, ws, deps, ml.Path)
+}
+
 func installYarn(ctx *gcp.Context, pjs *nodejs.PackageJSON) error {
 	yrl, err := ctx.Layer(yarnLayer, gcp.BuildLayer, gcp.CacheLayer, gcp.LaunchLayer)
 	if err != nil {
@@ -0,0 +1,142 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Implements python/django buildpack.
+// The django buildpack collects the static files of a Django project and sets a web process
+// serving its WSGI or ASGI application.
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/python"
+)
+
+func main() {
+	gcp.Main(detectFn, buildFn)
+}
+
+func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
+	p, err := python.FindDjangoProject(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("finding Django project: %w", err)
+	}
+	if p == nil {
+		return gcp.OptOut("no Django project found"), nil
+	}
+	return gcp.OptIn(fmt.Sprintf("found Django project with settings %q", p.SettingsModule)), nil
+}
+
+func buildFn(ctx *gcp.Context) error {
+	p, err := python.FindDjangoProject(ctx)
+	if err != nil {
+		return fmt.Errorf("finding Django project: %w", err)
+	}
+	if p == nil {
+		return gcp.UserErrorf("%s or the %s settings module not found", python.DjangoManagePy, python.DjangoSettingsModuleEnv)
+	}
+	if err := ctx.Setenv(python.DjangoSettingsModuleEnv, p.SettingsModule); err != nil {
+		return err
+	}
+
+	customEntrypoint := os.Getenv(env.Entrypoint) != ""
+	if err := collectStatic(ctx); err != nil {
+		// Apps with their own entrypoint built before this buildpack existed, so a failure must not
+		// break their build.
+		if !customEntrypoint {
+			return err
+		}
+		ctx.Warnf("Failed to collect Django static files, set %s=true to skip collectstatic: %v", python.DjangoSkipCollectStaticEnv, err)
+	}
+
+	checkDeploy, err := env.IsPresentAndTrue(python.DjangoCheckDeployEnv)
+	if err != nil {
+		return err
+	}
+	if checkDeploy {
+		ctx.Logf("Running Django deployment checks.")
+		if _, err := ctx.Exec([]string{"python3", python.DjangoManagePy, "check", "--deploy", "--fail-level", "WARNING"}, gcp.WithUserAttribution); err != nil {
+			return err
+		}
+	}
+
+	if customEntrypoint {
+		ctx.Debugf("Custom entrypoint present, skipping Django web process.")
+		return nil
+	}
+	cmd, err := webProcess(ctx, p)
+	if err != nil {
+		return err
+	}
+	ctx.Logf("Setting Django entrypoint: %q", strings.Join(cmd, " "))
+	ctx.AddProcess(gcp.WebProcess, cmd, gcp.AsDefaultProcess())
+	return nil
+}
+
+// staticRootScript prints STATIC_ROOT if the project collects static files into it.
+const staticRootScript = `from django.conf import settings
+if "django.contrib.staticfiles" in settings.INSTALLED_APPS and settings.STATIC_ROOT:
+    print(settings.STATIC_ROOT)`
+
+// collectStatic collects the static files of the project into STATIC_ROOT. Projects without
+// django.contrib.staticfiles or without a STATIC_ROOT are skipped.
+func collectStatic(ctx *gcp.Context) error {
+	skip, err := env.IsPresentAndTrue(python.DjangoSkipCollectStaticEnv)
+	if err != nil {
+		return err
+	}
+	if skip {
+		ctx.Logf("Skipping collectstatic, %s is set.", python.DjangoSkipCollectStaticEnv)
+		return nil
+	}
+	// Settings may depend on secrets only available at runtime, so they are not required to load.
+	result, err := ctx.Exec([]string{"python3", "-c", staticRootScript})
+	if err != nil {
+		ctx.Logf("Skipping collectstatic, the Django settings could not be loaded.")
+		if result != nil {
+			ctx.Debugf("Loading Django settings: %s", result.Combined)
+		}
+		return nil
+	}
+	staticRoot := strings.TrimSpace(result.Stdout)
+	if staticRoot == "" {
+		ctx.Logf("Skipping collectstatic, STATIC_ROOT is not set or django.contrib.staticfiles is not installed.")
+		return nil
+	}
+	ctx.Logf("Collecting Django static files into %s.", staticRoot)
+	_, err = ctx.Exec([]string{"python3", python.DjangoManagePy, "collectstatic", "--noinput"}, gcp.WithUserAttribution)
+	return err
+}
+
+// webProcess returns the command serving the project. ASGI applications are served with uvicorn
+// when it is a dependency of the project, otherwise the WSGI application is served with gunicorn.
+func webProcess(ctx *gcp.Context, p *python.DjangoProject) ([]string, error) {
+	if p.ASGIApplication != "" {
+		uvicornPresent, err := python.ProjectDependencyPresent(ctx, "uvicorn")
+		if err != nil {
+			return nil, fmt.Errorf("error detecting uvicorn: %w", err)
+		}
+		if uvicornPresent {
+			return []string{"uvicorn", p.ASGIApplication, "--port", "8080", "--host", "0.0.0.0"}, nil
+		}
+	}
+	if p.WSGIApplication != "" {
+		return []string{"gunicorn", "-b", ":8080", p.WSGIApplication}, nil
+	}
+	return nil, gcp.UserErrorf("no WSGI application found for Django settings %q, set WSGI_APPLICATION in the settings or set an entrypoint with %q env var", p.SettingsModule, env.Entrypoint)
+}
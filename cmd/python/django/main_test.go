@@ -0,0 +1,161 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	buildpacktest "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+	"github.com/GoogleCloudPlatform/buildpacks/internal/mockprocess"
+)
+
+const managePy = `import os
+os.environ.setdefault("DJANGO_SETTINGS_MODULE", "mysite.settings")
+`
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		env   []string
+		want  int
+	}{
+		{
+			name: "django project",
+			files: map[string]string{
+				"manage.py":          managePy,
+				"mysite/settings.py": "",
+			},
+			want: 0,
+		},
+		{
+			name: "settings module from env",
+			files: map[string]string{
+				"manage.py":               "",
+				"config/settings/prod.py": "",
+			},
+			env:  []string{"DJANGO_SETTINGS_MODULE=config.settings.prod"},
+			want: 0,
+		},
+		{
+			name: "missing settings module",
+			files: map[string]string{
+				"manage.py": managePy,
+			},
+			want: 100,
+		},
+		{
+			name: "no manage.py",
+			files: map[string]string{
+				"main.py": "",
+			},
+			want: 100,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buildpacktest.TestDetect(t, detectFn, tc.name, tc.files, tc.env, tc.want)
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	files := map[string]string{
+		"manage.py":          managePy,
+		"mysite/settings.py": `WSGI_APPLICATION = "mysite.wsgi.application"`,
+		"mysite/wsgi.py":     "",
+	}
+	testCases := []struct {
+		name            string
+		envs            []string
+		mocks           []*mockprocess.Mock
+		wantExitCode    int // 0 if unspecified
+		wantCommands    []string
+		notWantCommands []string
+	}{
+		{
+			name: "collectstatic into STATIC_ROOT",
+			mocks: []*mockprocess.Mock{
+				mockprocess.New(`^python3 -c`, mockprocess.WithStdout("/workspace/staticfiles")),
+				mockprocess.New(`^python3 manage.py collectstatic --noinput$`),
+			},
+			wantCommands: []string{"python3 manage.py collectstatic --noinput"},
+		},
+		{
+			name: "no STATIC_ROOT",
+			mocks: []*mockprocess.Mock{
+				mockprocess.New(`^python3 -c`),
+			},
+			notWantCommands: []string{"collectstatic"},
+		},
+		{
+			name: "settings fail to load",
+			mocks: []*mockprocess.Mock{
+				mockprocess.New(`^python3 -c`, mockprocess.WithStderr("KeyError: 'SECRET_KEY'"), mockprocess.WithExitCode(1)),
+			},
+			notWantCommands: []string{"collectstatic"},
+		},
+		{
+			name: "collectstatic fails",
+			mocks: []*mockprocess.Mock{
+				mockprocess.New(`^python3 -c`, mockprocess.WithStdout("/workspace/staticfiles")),
+				mockprocess.New(`^python3 manage.py collectstatic --noinput$`, mockprocess.WithExitCode(1)),
+			},
+			wantExitCode: 1,
+		},
+		{
+			name: "collectstatic fails with custom entrypoint",
+			envs: []string{"GOOGLE_ENTRYPOINT=gunicorn -b :8080 mysite.wsgi"},
+			mocks: []*mockprocess.Mock{
+				mockprocess.New(`^python3 -c`, mockprocess.WithStdout("/workspace/staticfiles")),
+				mockprocess.New(`^python3 manage.py collectstatic --noinput$`, mockprocess.WithExitCode(1)),
+			},
+		},
+		{
+			name:            "skip collectstatic",
+			envs:            []string{"GOOGLE_DJANGO_SKIP_COLLECTSTATIC=true"},
+			notWantCommands: []string{"python3 -c", "collectstatic"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := []buildpacktest.Option{
+				buildpacktest.WithTestName(tc.name),
+				buildpacktest.WithFiles(files),
+				buildpacktest.WithEnvs(tc.envs...),
+				buildpacktest.WithExecMocks(tc.mocks...),
+			}
+			result, err := buildpacktest.RunBuild(t, buildFn, opts...)
+			if err != nil && tc.wantExitCode == 0 {
+				t.Fatalf("error running build: %v, logs: %s", err, result.Output)
+			}
+
+			if result.ExitCode != tc.wantExitCode {
+				t.Errorf("build exit code mismatch, got: %d, want: %d", result.ExitCode, tc.wantExitCode)
+			}
+			for _, cmd := range tc.wantCommands {
+				if !result.CommandExecuted(cmd) {
+					t.Errorf("expected command %q to be executed, but it was not, build output: %s", cmd, result.Output)
+				}
+			}
+			for _, cmd := range tc.notWantCommands {
+				if result.CommandExecuted(cmd) {
+					t.Errorf("expected command %q not to be executed, but it was, build output: %s", cmd, result.Output)
+				}
+			}
+		})
+	}
+}
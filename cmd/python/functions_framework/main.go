@@ -71,6 +71,12 @@ func buildFn(ctx *gcp.Context) error {
 		}
 		hasFrameworkDependency = containsFF(string(content))
 	}
+	if !hasFrameworkDependency {
+		hasFrameworkDependency, err = python.PyProjectDependencyPresent(ctx, "functions-framework")
+		if err != nil {
+			return err
+		}
+	}
 
 	// Install functions-framework if necessary.
 	l, err := ctx.Layer(layerName, gcp.LaunchLayer, gcp.BuildLayer)
@@ -33,6 +33,9 @@ const (
 	uvicorn   = "uvicorn"
 	gradio    = "gradio"
 	streamlit = "streamlit"
+
+	// defaultTarget is the application object served when none is found in the sources.
+	defaultTarget = "main:app"
 )
 
 func main() {
@@ -59,26 +62,33 @@ func buildFn(ctx *gcp.Context) error {
 	if err != nil {
 		return fmt.Errorf("finding main.py files: %w", err)
 	}
-	if !hasMain {
-		return fmt.Errorf("for Python, provide a main.py file or set an entrypoint with %q env var or by creating a %q file", env.Entrypoint, "Procfile")
-	}
-	cmd := []string{"gunicorn", "-b", ":8080", "main:app"}
 
 	// We will use the smart default entrypoint if the runtime version supports it (>=3.13)
+	var entrypointFn func(*gcp.Context, string, bool) ([]string, error)
 	if supports, err := python.SupportsSmartDefaultEntrypoint(ctx); err == nil && supports {
 		// We will eventually remove the FastAPISmartDefaults flag and use smartdefaultEntrypoint for
 		// all use-cases.
 		if os.Getenv(env.FastAPISmartDefaults) == "true" {
-			cmd, err = uvicornEntrypoint(ctx)
-			if err != nil {
-				return fmt.Errorf("error detecting smart default entrypoint: %w", err)
-			}
+			entrypointFn = uvicornEntrypoint
 		}
 		if os.Getenv(env.PythonSmartDefaults) == "true" {
-			cmd, err = smartDefaultEntrypoint(ctx)
-			if err != nil {
-				return fmt.Errorf("error detecting smart default entrypoint: %w", err)
-			}
+			entrypointFn = smartDefaultEntrypoint
+		}
+	}
+
+	target, err := appTarget(ctx)
+	if err != nil {
+		return err
+	}
+	if target == defaultTarget && !hasMain {
+		return fmt.Errorf("for Python, provide a main.py file or set an entrypoint with %q env var or by creating a %q file", env.Entrypoint, "Procfile")
+	}
+
+	cmd := gunicornCmd(target)
+	if entrypointFn != nil {
+		cmd, err = entrypointFn(ctx, target, hasMain)
+		if err != nil {
+			return fmt.Errorf("error detecting smart default entrypoint: %w", err)
 		}
 	}
 	ctx.Warnf("Setting default entrypoint: %q", strings.Join(cmd, " "))
@@ -87,49 +97,80 @@ func buildFn(ctx *gcp.Context) error {
 	return nil
 }
 
-func uvicornEntrypoint(ctx *gcp.Context) ([]string, error) {
+// appTarget returns the application object to serve in module:variable form. The application
+// sources are analyzed to find the object, falling back to main:app if none is found.
+func appTarget(ctx *gcp.Context) (string, error) {
+	objs, err := python.FindAppObjects(ctx, ctx.ApplicationRoot())
+	if err != nil {
+		return "", err
+	}
+	if len(objs) == 0 {
+		ctx.Logf("No application object found in the Python sources, using %q.", defaultTarget)
+		return defaultTarget, nil
+	}
+	ctx.Logf("Using %s as the application object %q.", objs[0], objs[0].Target())
+	for _, obj := range objs[1:] {
+		ctx.Logf("Ignoring %s, set an entrypoint with %q env var to use it instead.", obj, env.Entrypoint)
+	}
+	return objs[0].Target(), nil
+}
+
+func gunicornCmd(target string) []string {
+	return []string{"gunicorn", "-b", ":8080", target}
+}
+
+func uvicornCmd(target string) []string {
+	return []string{"uvicorn", target, "--port", "8080", "--host", "0.0.0.0"}
+}
+
+func uvicornEntrypoint(ctx *gcp.Context, target string, _ bool) ([]string, error) {
 	// To be compatible with the old builder, we will use below priority order:
 	// 1. gunicorn 2. uvicorn
-	// If gunicorn is present in requirements.txt, we will use gunicorn as the entrypoint.
-	gPresent, err := python.PackagePresent(ctx, "requirements.txt", gunicorn)
+	// If gunicorn is a dependency in requirements.txt or pyproject.toml, we will use gunicorn as the entrypoint.
+	gPresent, err := python.ProjectDependencyPresent(ctx, gunicorn)
 	if err != nil {
 		return nil, fmt.Errorf("error detecting gunicorn: %w", err)
 	}
 	if gPresent {
-		return []string{"gunicorn", "-b", ":8080", "main:app"}, nil
+		return gunicornCmd(target), nil
 	}
-	// If uvicorn is present in requirements.txt, we will use uvicorn as the entrypoint.
-	uPresent, err := python.PackagePresent(ctx, "requirements.txt", uvicorn)
+	// If uvicorn is a dependency in requirements.txt or pyproject.toml, we will use uvicorn as the entrypoint.
+	uPresent, err := python.ProjectDependencyPresent(ctx, uvicorn)
 	if err != nil {
 		return nil, fmt.Errorf("error detecting uvicorn: %w", err)
 	}
 	if uPresent {
-		return []string{"uvicorn", "main:app", "--port", "8080", "--host", "0.0.0.0"}, nil
+		return uvicornCmd(target), nil
 	}
-	return []string{"gunicorn", "-b", ":8080", "main:app"}, nil
+	return gunicornCmd(target), nil
 }
 
-func smartDefaultEntrypoint(ctx *gcp.Context) ([]string, error) {
+func smartDefaultEntrypoint(ctx *gcp.Context, target string, hasMain bool) ([]string, error) {
 	// To be compatible with the old builder, we will use below priority order:
 	// 1. gunicorn 2. uvicorn 3. gradio 4. streamlit
-	// If gunicorn is present in requirements.txt, we will use gunicorn as the entrypoint.
-	gPresent, err := python.PackagePresent(ctx, "requirements.txt", gunicorn)
+	// If gunicorn is a dependency in requirements.txt or pyproject.toml, we will use gunicorn as the entrypoint.
+	gPresent, err := python.ProjectDependencyPresent(ctx, gunicorn)
 	if err != nil {
 		return nil, fmt.Errorf("error detecting gunicorn: %w", err)
 	}
 	if gPresent {
-		return []string{"gunicorn", "-b", ":8080", "main:app"}, nil
+		return gunicornCmd(target), nil
 	}
-	// If uvicorn is present in requirements.txt, we will use uvicorn as the entrypoint.
-	uPresent, err := python.PackagePresent(ctx, "requirements.txt", uvicorn)
+	// If uvicorn is a dependency in requirements.txt or pyproject.toml, we will use uvicorn as the entrypoint.
+	uPresent, err := python.ProjectDependencyPresent(ctx, uvicorn)
 	if err != nil {
 		return nil, fmt.Errorf("error detecting uvicorn: %w", err)
 	}
 	if uPresent {
-		return []string{"uvicorn", "main:app", "--port", "8080", "--host", "0.0.0.0"}, nil
+		return uvicornCmd(target), nil
+	}
+	// Gradio and streamlit applications are scripts rather than application objects, so they can only
+	// be run from main.py.
+	if !hasMain {
+		return gunicornCmd(target), nil
 	}
-	// If gradio is present in requirements.txt, we will use gradio as the entrypoint.
-	gradioPresent, err := python.PackagePresent(ctx, "requirements.txt", gradio)
+	// If gradio is a dependency in requirements.txt or pyproject.toml, we will use gradio as the entrypoint.
+	gradioPresent, err := python.ProjectDependencyPresent(ctx, gradio)
 	if err != nil {
 		return nil, fmt.Errorf("error detecting gradio: %w", err)
 	}
@@ -139,8 +180,8 @@ func smartDefaultEntrypoint(ctx *gcp.Context) ([]string, error) {
 		}
 		return []string{"python", "main.py"}, nil
 	}
-	// If streamlit is present in requirements.txt, we will use streamlit as the entrypoint.
-	sPresent, err := python.PackagePresent(ctx, "requirements.txt", streamlit)
+	// If streamlit is a dependency in requirements.txt or pyproject.toml, we will use streamlit as the entrypoint.
+	sPresent, err := python.ProjectDependencyPresent(ctx, streamlit)
 	if err != nil {
 		return nil, fmt.Errorf("error detecting streamlit: %w", err)
 	}
@@ -148,7 +189,7 @@ func smartDefaultEntrypoint(ctx *gcp.Context) ([]string, error) {
 		return []string{"streamlit", "run", "main.py", "--server.address", "0.0.0.0", "--server.port", "8080"}, nil
 	}
 
-	return []string{"gunicorn", "-b", ":8080", "main:app"}, nil
+	return gunicornCmd(target), nil
 }
 
 func addGradioEnvVarLayer(ctx *gcp.Context) error {
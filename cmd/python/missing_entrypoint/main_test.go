@@ -193,6 +193,19 @@ func TestBuild(t *testing.T) {
 			runtime: "python3.13",
 			wantCmd: []string{"streamlit", "run", "main.py", "--server.address", "0.0.0.0", "--server.port", "8080"},
 		},
+		{
+			name: "python_smart_defaults_uvicorn_in_pyproject",
+			files: map[string]string{
+				"main.py":        "",
+				"pyproject.toml": "[project]\ndependencies = [\"fastapi\", \"uvicorn[standard]\"]\n",
+			},
+			env: []string{
+				env.PythonSmartDefaults + "=true",
+				env.RuntimeVersion + "=3.13.0",
+			},
+			runtime: "python3.13",
+			wantCmd: []string{"uvicorn", "main:app", "--port", "8080", "--host", "0.0.0.0"},
+		},
 		{
 			name: "python_smart_defaults_none",
 			files: map[string]string{
@@ -256,6 +269,86 @@ func TestBuild(t *testing.T) {
 			},
 			wantCmd: []string{"uvicorn", "main:app", "--port", "8080", "--host", "0.0.0.0"},
 		},
+		{
+			name: "python_smart_defaults_app_object_in_package",
+			files: map[string]string{
+				"app/__init__.py":  "",
+				"app/server.py":    "from fastapi import FastAPI\n\napi = FastAPI()\n",
+				"requirements.txt": "fastapi\nuvicorn",
+			},
+			env: []string{
+				env.PythonSmartDefaults + "=true",
+				env.RuntimeVersion + "=3.13.0",
+			},
+			runtime: "python3.13",
+			wantCmd: []string{"uvicorn", "app.server:api", "--port", "8080", "--host", "0.0.0.0"},
+		},
+		{
+			name: "python_smart_defaults_app_object_in_main",
+			files: map[string]string{
+				"main.py":          "import flask\n\nserver = flask.Flask(__name__)\n",
+				"other.py":         "from flask import Flask\n\napp = Flask(__name__)\n",
+				"requirements.txt": "flask\ngunicorn",
+			},
+			env: []string{
+				env.PythonSmartDefaults + "=true",
+				env.RuntimeVersion + "=3.13.0",
+			},
+			runtime: "python3.13",
+			wantCmd: []string{"gunicorn", "-b", ":8080", "main:server"},
+		},
+		{
+			name: "fastapi_smart_defaults_app_object",
+			files: map[string]string{
+				"app.py":           "from starlette.applications import Starlette\n\napplication = Starlette()\n",
+				"requirements.txt": "uvicorn",
+			},
+			env: []string{
+				env.FastAPISmartDefaults + "=true",
+				env.RuntimeVersion + "=3.13.0",
+			},
+			runtime: "python3.13",
+			wantCmd: []string{"uvicorn", "app:application", "--port", "8080", "--host", "0.0.0.0"},
+		},
+		{
+			name: "python_smart_defaults_gradio_without_main.py",
+			files: map[string]string{
+				"app.py":           "from flask import Flask\n\napp = Flask(__name__)\n",
+				"requirements.txt": "gradio",
+			},
+			env: []string{
+				env.PythonSmartDefaults + "=true",
+				env.RuntimeVersion + "=3.13.0",
+			},
+			runtime: "python3.13",
+			wantCmd: []string{"gunicorn", "-b", ":8080", "app:app"},
+		},
+		{
+			name: "default_gunicorn_app_object",
+			files: map[string]string{
+				"app.py": "from flask import Flask\n\napp = Flask(__name__)\n",
+			},
+			wantCmd: []string{"gunicorn", "-b", ":8080", "app:app"},
+		},
+		{
+			name: "default_gunicorn_app_object_in_main",
+			files: map[string]string{
+				"main.py": "from flask import Flask\n\nserver = Flask(__name__)\n",
+			},
+			wantCmd: []string{"gunicorn", "-b", ":8080", "main:server"},
+		},
+		{
+			name: "python_smart_defaults_no_main.py_no_app_object",
+			files: map[string]string{
+				"app.py": "",
+			},
+			env: []string{
+				env.PythonSmartDefaults + "=true",
+				env.RuntimeVersion + "=3.13.0",
+			},
+			runtime:      "python3.13",
+			wantExitCode: 1,
+		},
 		{
 			name: "no main.py",
 			files: map[string]string{
@@ -28,7 +28,10 @@ import (
 )
 
 const (
-	layerName = "pip"
+	layerName        = "pip"
+	uvLayerName      = "uv"
+	uvCacheLayerName = "uv-cache"
+	wheelsLayerName  = "pip-wheels"
 )
 
 // metadata represents metadata stored for a dependencies layer.
@@ -51,7 +54,12 @@ func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
 	if err != nil {
 		return nil, err
 	}
-	if requirementsExists {
+	// Projects locked with uv are installed from uv.lock, which provides the same dependency.
+	uvLockExists, err := ctx.FileExists(python.UVLock)
+	if err != nil {
+		return nil, err
+	}
+	if requirementsExists || uvLockExists {
 		plan.Provides = python.RequirementsProvides
 	}
 	return gcp.OptInAlways(gcp.WithBuildPlans(plan)), nil
@@ -76,7 +84,19 @@ func buildFn(ctx *gcp.Context) error {
 		return fmt.Errorf("creating %v layer: %w", layerName, err)
 	}
 
-	if err := python.InstallRequirements(ctx, l, reqs...); err != nil {
+	usesUV, err := python.UsesUV(ctx)
+	if err != nil {
+		return err
+	}
+	if usesUV {
+		return installWithUV(ctx, l, reqs)
+	}
+
+	wl, err := ctx.Layer(wheelsLayerName, gcp.CacheLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", wheelsLayerName, err)
+	}
+	if err := python.InstallRequirements(ctx, l, wl, reqs...); err != nil {
 		return fmt.Errorf("installing dependencies: %w", err)
 	}
 
@@ -100,3 +120,31 @@ func buildFn(ctx *gcp.Context) error {
 	return gcp.UserErrorf("found incompatible dependencies: %q", result.Stdout)
 
 }
+
+// installWithUV installs the dependencies in the pip layer using uv.
+func installWithUV(ctx *gcp.Context, l *libcnb.Layer, reqs []string) error {
+	ul, err := ctx.Layer(uvLayerName, gcp.CacheLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", uvLayerName, err)
+	}
+	if err := python.InstallUV(ctx, ul); err != nil {
+		return err
+	}
+	cl, err := ctx.Layer(uvCacheLayerName, gcp.CacheLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", uvCacheLayerName, err)
+	}
+	if err := python.InstallRequirementsWithUV(ctx, l, cl, reqs...); err != nil {
+		return fmt.Errorf("installing dependencies: %w", err)
+	}
+
+	ctx.Logf("Checking for incompatible dependencies.")
+	result, err := ctx.Exec([]string{"uv", "pip", "check"}, gcp.WithUserAttribution)
+	if result == nil {
+		return fmt.Errorf("uv pip check: %w", err)
+	}
+	if result.ExitCode != 0 {
+		return gcp.UserErrorf("found incompatible dependencies: %q", result.Stdout)
+	}
+	return nil
+}
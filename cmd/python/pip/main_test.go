@@ -34,6 +34,15 @@ func TestDetect(t *testing.T) {
 			},
 			want: 0,
 		},
+		{
+			name: "uv lock file",
+			files: map[string]string{
+				"main.py":        "",
+				"pyproject.toml": "",
+				"uv.lock":        "",
+			},
+			want: 0,
+		},
 		{
 			// Opt-in with no requirements in case there's a build plan.
 			name: "no requirements",
@@ -0,0 +1,83 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Implements python/pipenv buildpack.
+// The pipenv buildpack provides the packages locked in Pipfile.lock as a requirements file
+// installed by the pip buildpack.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/python"
+)
+
+const (
+	layerName = "pipenv"
+)
+
+func main() {
+	gcp.Main(detectFn, buildFn)
+}
+
+func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
+	requirementsExists, err := ctx.FileExists("requirements.txt")
+	if err != nil {
+		return nil, err
+	}
+	if requirementsExists {
+		return gcp.OptOut("requirements.txt found, dependencies are installed from it"), nil
+	}
+	lockExists, err := ctx.FileExists(python.PipfileLock)
+	if err != nil {
+		return nil, err
+	}
+	if lockExists {
+		return gcp.OptInFileFound(python.PipfileLock, gcp.WithBuildPlans(python.RequirementsProvidesPlan)), nil
+	}
+	// Opt in to report that Pipfile.lock is missing rather than silently skipping the dependencies.
+	pipfileExists, err := ctx.FileExists(python.Pipfile)
+	if err != nil {
+		return nil, err
+	}
+	if pipfileExists {
+		return gcp.OptInFileFound(python.Pipfile, gcp.WithBuildPlans(python.RequirementsProvidesPlan)), nil
+	}
+	return gcp.OptOutFileNotFound(python.PipfileLock), nil
+}
+
+func buildFn(ctx *gcp.Context) error {
+	reqs, err := python.PipenvRequirements(ctx, ctx.ApplicationRoot())
+	if err != nil {
+		return err
+	}
+
+	l, err := ctx.Layer(layerName, gcp.BuildLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", layerName, err)
+	}
+	r := filepath.Join(l.Path, "requirements.txt")
+	if err := ctx.WriteFile(r, []byte(strings.Join(reqs, "\n")+"\n"), 0644); err != nil {
+		return err
+	}
+
+	// The pip install is performed by the pip buildpack; see python.InstallRequirements.
+	ctx.Logf("Adding requirements from %s to the list of requirements files to install.", python.PipfileLock)
+	l.BuildEnvironment.Append(python.RequirementsFilesEnv, string(os.PathListSeparator), r)
+	return nil
+}
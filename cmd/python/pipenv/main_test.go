@@ -0,0 +1,68 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	buildpacktest "github.com/GoogleCloudPlatform/buildpacks/internal/buildpacktest"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		want  int
+	}{
+		{
+			name: "pipfile lock",
+			files: map[string]string{
+				"main.py":      "",
+				"Pipfile":      "",
+				"Pipfile.lock": "",
+			},
+			want: 0,
+		},
+		{
+			name: "pipfile without lock",
+			files: map[string]string{
+				"main.py": "",
+				"Pipfile": "",
+			},
+			want: 0,
+		},
+		{
+			name: "requirements file",
+			files: map[string]string{
+				"main.py":          "",
+				"Pipfile.lock":     "",
+				"requirements.txt": "",
+			},
+			want: 100,
+		},
+		{
+			name: "no pipfile",
+			files: map[string]string{
+				"main.py": "",
+			},
+			want: 100,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buildpacktest.TestDetect(t, detectFn, tc.name, tc.files, []string{}, tc.want)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Implements python/pyproject buildpack.
+// The pyproject buildpack provides the dependencies declared in pyproject.toml, pinned by
+// poetry.lock for Poetry projects, as a requirements file installed by the pip buildpack.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/python"
+)
+
+const (
+	layerName = "pyproject"
+)
+
+func main() {
+	gcp.Main(detectFn, buildFn)
+}
+
+func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
+	requirementsExists, err := ctx.FileExists("requirements.txt")
+	if err != nil {
+		return nil, err
+	}
+	if requirementsExists {
+		return gcp.OptOut("requirements.txt found, dependencies are installed from it"), nil
+	}
+	uvLockExists, err := ctx.FileExists(python.UVLock)
+	if err != nil {
+		return nil, err
+	}
+	if uvLockExists {
+		usesUV, err := python.UsesUV(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if usesUV {
+			return gcp.OptOut("uv.lock found, dependencies are installed by uv"), nil
+		}
+	}
+	p, err := python.ReadPyProject(ctx, ctx.ApplicationRoot())
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return gcp.OptOutFileNotFound(python.PyprojectTOML), nil
+	}
+	lockExists, err := ctx.FileExists(python.PoetryLock)
+	if err != nil {
+		return nil, err
+	}
+	if lockExists || p.IsPoetry() {
+		return gcp.OptIn("found Poetry project", gcp.WithBuildPlans(python.RequirementsProvidesPlan)), nil
+	}
+	if len(p.Project.Dependencies) > 0 {
+		return gcp.OptIn("found dependencies in pyproject.toml", gcp.WithBuildPlans(python.RequirementsProvidesPlan)), nil
+	}
+	return gcp.OptOut("no dependencies declared in pyproject.toml"), nil
+}
+
+func buildFn(ctx *gcp.Context) error {
+	reqs, err := python.PyProjectRequirements(ctx, ctx.ApplicationRoot())
+	if err != nil {
+		return err
+	}
+
+	l, err := ctx.Layer(layerName, gcp.BuildLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", layerName, err)
+	}
+	// The generated file only depends on the lock file, so the cache key of the pip layer changes
+	// only when the locked dependencies do.
+	r := filepath.Join(l.Path, "requirements.txt")
+	if err := ctx.WriteFile(r, []byte(strings.Join(reqs, "\n")+"\n"), 0644); err != nil {
+		return err
+	}
+
+	// The pip install is performed by the pip buildpack; see python.InstallRequirements.
+	ctx.Logf("Adding requirements from %s to the list of requirements files to install.", python.PyprojectTOML)
+	l.BuildEnvironment.Append(python.RequirementsFilesEnv, string(os.PathListSeparator), r)
+	return nil
+}
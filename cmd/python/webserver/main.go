@@ -58,6 +58,13 @@ func detectFn(ctx *gcp.Context) (gcp.DetectResult, error) {
 		}
 		return gcp.OptIn("gunicorn missing from requirements.txt", gcp.WithBuildPlans(python.RequirementsProvidesPlan)), nil
 	}
+	present, err := python.PyProjectDependencyPresent(ctx, "gunicorn")
+	if err != nil {
+		return nil, fmt.Errorf("error detecting gunicorn: %w", err)
+	}
+	if present {
+		return gcp.OptOut("gunicorn present in pyproject.toml"), nil
+	}
 	return gcp.OptIn("requirements.txt with gunicorn not found", gcp.WithBuildPlans(python.RequirementsProvidesPlan)), nil
 }
 
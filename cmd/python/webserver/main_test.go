@@ -56,6 +56,20 @@ func TestDetect(t *testing.T) {
 				"requirements.txt": "gunicorn==19.3.0"},
 			want: 100,
 		},
+		{
+			name: "has gunicorn in pyproject",
+			files: map[string]string{
+				"main.py":        "",
+				"pyproject.toml": "[project]\ndependencies = [\"gunicorn>=22.0\"]\n"},
+			want: 100,
+		},
+		{
+			name: "has pyproject without gunicorn",
+			files: map[string]string{
+				"main.py":        "",
+				"pyproject.toml": "[project]\ndependencies = [\"flask\"]\n"},
+			want: 0,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"path/filepath"
 
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/ar"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/buildererror"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/cache"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
@@ -105,6 +106,10 @@ func buildFn(ctx *gcp.Context) error {
 		return fmt.Errorf("checking cache: %w", err)
 	}
 
+	if err := ar.GenerateBundlerConfig(ctx); err != nil {
+		return fmt.Errorf("configuring Bundler credentials: %w", err)
+	}
+
 	localGemsDir := filepath.Join(".bundle", "gems")
 	localBinDir := filepath.Join(".bundle", "bin")
 
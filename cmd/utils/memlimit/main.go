@@ -0,0 +1,56 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Implements the memlimit exec.d binary.
+// The memlimit binary is installed by runtime buildpacks in the exec.d directory of a launch layer
+// as memlimit-RUNTIME, for example memlimit-nodejs. When the container starts, it sizes the memory
+// settings of the runtime from the memory limit of the container. For more details see
+// https://buildpacks.io/docs/for-buildpack-authors/how-to/write-buildpacks/use-exec.d/
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/memlimit"
+)
+
+func main() {
+	if err := run(); err != nil {
+		// The application must start even if its memory settings cannot be sized.
+		fmt.Fprintf(os.Stderr, "memlimit: %v\n", err)
+	}
+}
+
+func run() error {
+	runtime, ok := strings.CutPrefix(filepath.Base(os.Args[0]), memlimit.BinaryName+"-")
+	if !ok {
+		return fmt.Errorf("unexpected binary name %q, want %s-RUNTIME", os.Args[0], memlimit.BinaryName)
+	}
+	limit, err := memlimit.Read("/", os.Getenv)
+	if err != nil {
+		return err
+	}
+	vars, err := memlimit.Env(runtime, limit, os.Getenv, goruntime.NumCPU())
+	if err != nil {
+		return err
+	}
+	// exec.d binaries write the environment variables to file descriptor 3.
+	out := os.NewFile(3, "/dev/fd/3")
+	defer out.Close()
+	return memlimit.WriteExecD(out, vars)
+}
@@ -12,7 +12,8 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package ar implements functions for working with Google Artifact Registry.
+// Package ar implements functions for working with Google Artifact Registry and other private
+// package repositories.
 package ar
 
 import (
@@ -34,6 +35,8 @@ import (
 
 const (
 	pythonConfigName = ".netrc"
+	pipConfigDir     = ".config/pip"
+	pipConfigName    = "pip.conf"
 	npmConfigName    = ".npmrc"
 	yarnConfigName   = ".yarnrc.yml"
 )
@@ -127,9 +130,22 @@ func arRepositories() []string {
 }
 
 // GeneratePythonConfig generates a netrc file in the user's HOME directory with the credentials
-// necessary for PIP to make authenticated requests to Artifact Registry (see
-// https://pip.pypa.io/en/stable/topics/authentication/#netrc-support).
+// necessary for PIP to make authenticated requests to Artifact Registry and the configured
+// repositories (see https://pip.pypa.io/en/stable/topics/authentication/#netrc-support). The
+// configured repositories that are indexes are added to the user's pip.conf.
 func GeneratePythonConfig(ctx *gcp.Context) error {
+	creds, _, err := credentialsFor(ctx, Python)
+	if err != nil {
+		return err
+	}
+	if err := generateNetrc(ctx, creds); err != nil {
+		return err
+	}
+	return generatePipConfig(ctx, creds)
+}
+
+// generateNetrc writes the credentials to the user's .netrc file.
+func generateNetrc(ctx *gcp.Context, creds []Credential) error {
 	netrcPath := filepath.Join(ctx.HomeDir(), pythonConfigName)
 	netrcExists, err := ctx.FileExists(netrcPath)
 	if err != nil {
@@ -140,13 +156,8 @@ func GeneratePythonConfig(ctx *gcp.Context) error {
 		// If a .netrc file already exists we should not override it.
 		return nil
 	}
-
-	tok, err := findDefaultCredentials()
-	if err != nil {
-		// findDefaultCredentials will return an error any time Application Default Credentials are
-		// missing (e.g. running the buildpacks locally outside of GCB). Credentials might not
-		// be required for the pip install to succeed so we should not fail the build here.
-		ctx.Debugf("Unable to find Application Default Credentials. Skipping .netrc creation.")
+	if len(creds) == 0 {
+		ctx.Debugf("No repository credentials found. Skipping .netrc creation.")
 		return nil
 	}
 
@@ -156,21 +167,22 @@ func GeneratePythonConfig(ctx *gcp.Context) error {
 	}
 	defer f.Close()
 
-	return writePythonConfig(f, tok)
+	return writePythonConfig(f, creds)
 }
 
-// writePythonConfig writes the .netrc contents for authenticating to AR.
-func writePythonConfig(wr io.Writer, tok string) error {
+// writePythonConfig writes the .netrc contents for authenticating to the repositories.
+func writePythonConfig(wr io.Writer, creds []Credential) error {
 	// pythonConfig is the template for python's .netrc file.
 	// A sample config is in token_injector_test.
 	const pythonConfig = `
-{{- range $entry := .Hosts}}
-machine {{$entry}} login oauth2accesstoken password {{$.Token}}
+{{- range $entry := .}}
+machine {{$entry.Host}} login {{$entry.Login}} password {{$entry.Password}}
 {{- end}}
 `
 	type authEntry struct {
-		Token string
-		Hosts []string
+		Host     string
+		Login    string
+		Password string
 	}
 
 	t, err := template.New("netrc").Parse(pythonConfig)
@@ -178,21 +190,52 @@ machine {{$entry}} login oauth2accesstoken password {{$.Token}}
 		return err
 	}
 
-	cfg := authEntry{
-		Token: tok,
-		Hosts: arRepositories(),
+	var entries []authEntry
+	for _, c := range creds {
+		login, password := c.login()
+		entries = append(entries, authEntry{Host: c.host(), Login: login, Password: password})
 	}
 
-	if err := t.Execute(wr, cfg); err != nil {
+	if err := t.Execute(wr, entries); err != nil {
 		return fmt.Errorf("creating python netrc template: %w", err)
 	}
 
 	return nil
 }
 
+// generatePipConfig adds the repositories that are indexes to the user's pip.conf file as extra
+// index URLs. Their credentials are in the .netrc file rather than in the URLs.
+func generatePipConfig(ctx *gcp.Context, creds []Credential) error {
+	var indexes []string
+	for _, c := range creds {
+		if c.Index {
+			indexes = append(indexes, c.URL)
+		}
+	}
+	if len(indexes) == 0 {
+		return nil
+	}
+	pipConfig := filepath.Join(ctx.HomeDir(), pipConfigDir, pipConfigName)
+	pipConfigExists, err := ctx.FileExists(pipConfig)
+	if err != nil {
+		return err
+	}
+	if pipConfigExists {
+		ctx.Warnf("Found an existing %s file. Skipping adding %s to it.", pipConfig, strings.Join(indexes, ", "))
+		return nil
+	}
+	if err := ctx.MkdirAll(filepath.Dir(pipConfig), 0755); err != nil {
+		return err
+	}
+	ctx.Debugf("Adding extra index URLs to %s: %s", pipConfig, strings.Join(indexes, ", "))
+	content := "[global]\nextra-index-url =\n    " + strings.Join(indexes, "\n    ") + "\n"
+	return ctx.WriteFile(pipConfig, []byte(content), 0644)
+}
+
 // GenerateNPMConfig generates an .npmrc file in the user's HOME directory with the credentials
 // necessary for NPM to make authenticated requests to Artifact Registry (see
-// https://cloud.google.com/artifact-registry/docs/nodejs/authentication).
+// https://cloud.google.com/artifact-registry/docs/nodejs/authentication) and the configured
+// repositories.
 func GenerateNPMConfig(ctx *gcp.Context) error {
 	userConfig := filepath.Join(ctx.HomeDir(), npmConfigName)
 	userConfigExists, err := ctx.FileExists(userConfig)
@@ -204,42 +247,22 @@ func GenerateNPMConfig(ctx *gcp.Context) error {
 		return nil
 	}
 
-	projectConfig := filepath.Join(ctx.ApplicationRoot(), npmConfigName)
-	projConfigExists, err := ctx.FileExists(projectConfig)
+	bindingConfig, err := bindingFile(ctx, NPMRCBindingType, npmConfigName)
 	if err != nil {
-		return nil
-	}
-	if !projConfigExists {
-		// Unlike Python, NPM credentials must be configured per repo. If the devoloper has not included
-		// a project-level npmrc, there are no AR repos to set credentials for, so there is nothing
-		// more to do.
-		return nil
+		return err
 	}
-	content, err := ctx.ReadFile(projectConfig)
+	creds, hasAR, err := credentialsFor(ctx, NPM)
 	if err != nil {
 		return err
 	}
-
-	matches := npmRegistryRegexp.FindAllStringSubmatch(string(content), -1)
-	var repos []string
-
-	for _, m := range matches {
-		repos = append(repos, m[2])
-	}
-
-	if len(repos) < 1 {
+	if len(creds) < 1 && bindingConfig == "" {
 		return nil
 	}
 
-	tok, err := findDefaultCredentials()
-	if err != nil {
-		// findDefaultCredentials will return an error any time Application Default Credentials are
-		// missing (e.g. running the buildpacks locally outside of GCB). Credentials might not
-		// be required for the npm install to succeed so we should not fail the build here.
-		ctx.Warnf("Skipping .npmrc creation. Unable to find Application Default Credentials: %v", err)
-		return nil
+	var repos []string
+	for _, c := range creds {
+		repos = append(repos, c.URL)
 	}
-
 	ctx.Debugf("Configuring NPM credentials for: %s", strings.Join(repos, ", "))
 
 	f, err := ctx.CreateFile(userConfig)
@@ -248,20 +271,46 @@ func GenerateNPMConfig(ctx *gcp.Context) error {
 	}
 	defer f.Close()
 
-	return writeNpmConfig(f, repos, tok)
+	if bindingConfig != "" {
+		ctx.Debugf("Adding %s from service binding to %s.", npmConfigName, userConfig)
+		content, err := ctx.ReadFile(bindingConfig)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(f, strings.TrimSpace(string(content))); err != nil {
+			return err
+		}
+	}
+	if err := writeNpmConfig(f, creds); err != nil {
+		return err
+	}
+	if hasAR {
+		buildermetrics.GlobalBuilderMetrics().GetCounter(buildermetrics.ArNpmCredsGenCounterID).Increment(1)
+	}
+	return nil
 }
 
-// writeNpmConfig writes the .npmrc contents for authenticating to AR.
-func writeNpmConfig(wr io.Writer, repos []string, tok string) error {
+// writeNpmConfig writes the .npmrc contents for authenticating to the repositories.
+func writeNpmConfig(wr io.Writer, creds []Credential) error {
 	// npmConfig is the template for user level .npmrc that configures repository access tokens.
 	const npmConfig = `
-{{- range $repo := .Repos}}
-{{$repo}}:_authToken={{$.Token}}
+{{- range $line := .}}
+{{$line}}
 {{- end}}
 `
-	type authEntry struct {
-		Token string
-		Repos []string
+	var lines []string
+	for _, c := range creds {
+		if !c.Index {
+			continue
+		}
+		if c.Scope != "" {
+			lines = append(lines, fmt.Sprintf("%s:registry=%s", c.Scope, c.URL))
+		} else {
+			lines = append(lines, "registry="+c.URL)
+		}
+	}
+	for _, c := range creds {
+		lines = append(lines, c.npmAuth())
 	}
 
 	t, err := template.New("npmrc").Parse(npmConfig)
@@ -269,17 +318,10 @@ func writeNpmConfig(wr io.Writer, repos []string, tok string) error {
 		return err
 	}
 
-	cfg := authEntry{
-		Token: tok,
-		Repos: repos,
-	}
-
-	if err := t.Execute(wr, cfg); err != nil {
+	if err := t.Execute(wr, lines); err != nil {
 		return fmt.Errorf("creating NPM .npmrc template: %w", err)
 	}
 
-	buildermetrics.GlobalBuilderMetrics().GetCounter(buildermetrics.ArNpmCredsGenCounterID).Increment(1)
-
 	return nil
 }
 
@@ -312,6 +354,19 @@ func GenerateYarnConfig(ctx *gcp.Context) error {
 		return nil
 	}
 
+	bindingConfig, err := bindingFile(ctx, YarnRCBindingType, yarnConfigName)
+	if err != nil {
+		return err
+	}
+	if bindingConfig != "" {
+		ctx.Logf("Using %s from service binding as %s.", yarnConfigName, userConfig)
+		content, err := ctx.ReadFile(bindingConfig)
+		if err != nil {
+			return err
+		}
+		return ctx.WriteFile(userConfig, content, 0664)
+	}
+
 	projectConfig := filepath.Join(ctx.ApplicationRoot(), yarnConfigName)
 	projConfigExists, err := ctx.FileExists(projectConfig)
 	if err != nil {
@@ -0,0 +1,118 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ar
+
+import (
+	"path/filepath"
+	"strconv"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb/v2"
+)
+
+const (
+	// RepositoryBindingType is the type of service bindings holding the credentials of a package
+	// repository. The keys of the binding are the JSON fields of Credential, e.g. ecosystem, url and
+	// token.
+	RepositoryBindingType = "package-repository"
+	// NPMRCBindingType is the type of service bindings with a .npmrc key holding a user .npmrc file.
+	NPMRCBindingType = "npmrc"
+	// YarnRCBindingType is the type of service bindings with a .yarnrc.yml key holding a user
+	// .yarnrc.yml file.
+	YarnRCBindingType = "yarnrc"
+	// MavenBindingType is the type of service bindings with a settings.xml key holding Maven settings.
+	MavenBindingType = "maven"
+	// ComposerBindingType is the type of service bindings with an auth.json key holding Composer
+	// authentication.
+	ComposerBindingType = "composer"
+	// BundlerBindingType is the type of service bindings with a config key holding a Bundler config.
+	BundlerBindingType = "bundle-config"
+
+	bindingProviderName = "service bindings"
+)
+
+// bindingProvider provides the credentials of package-repository service bindings.
+type bindingProvider struct{}
+
+func (bindingProvider) Name() string {
+	return bindingProviderName
+}
+
+func (bindingProvider) Credentials(ctx *gcp.Context, ecosystem Ecosystem) ([]Credential, error) {
+	bindings, err := ctx.BindingsOfType(RepositoryBindingType, "")
+	if err != nil {
+		return nil, err
+	}
+	var creds []Credential
+	for _, b := range bindings {
+		c := Credential{
+			Ecosystem: Ecosystem(b.Secret["ecosystem"]),
+			ID:        b.Secret["id"],
+			URL:       b.Secret["url"],
+			Username:  b.Secret["username"],
+			Password:  b.Secret["password"],
+			Token:     b.Secret["token"],
+			Scope:     b.Secret["scope"],
+		}
+		if index, ok := b.Secret["index"]; ok {
+			if c.Index, err = strconv.ParseBool(index); err != nil {
+				return nil, gcp.UserErrorf("parsing index of service binding %s: %w", b.Name, err)
+			}
+		}
+		if err := c.validate(); err != nil {
+			return nil, gcp.UserErrorf("service binding %s: %w", b.Name, err)
+		}
+		if c.Ecosystem == ecosystem {
+			creds = append(creds, c)
+		}
+	}
+	return creds, nil
+}
+
+// bindingFile returns the path of the given key in the first service binding of the given type
+// that has it, or "" if there is none.
+func bindingFile(ctx *gcp.Context, bindingType, key string) (string, error) {
+	bindings, err := ctx.BindingsOfType(bindingType, "")
+	if err != nil {
+		return "", err
+	}
+	for _, b := range bindings {
+		path, ok, err := secretFilePath(ctx, b, key)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return path, nil
+		}
+	}
+	return "", nil
+}
+
+// secretFilePath returns the path of a secret of a binding read from the file system. Hidden keys
+// such as .npmrc are not part of the binding secret, so the binding directory is checked directly.
+func secretFilePath(ctx *gcp.Context, b libcnb.Binding, key string) (string, bool, error) {
+	if b.Path == "" {
+		return "", false, nil
+	}
+	if path, ok := b.SecretFilePath(key); ok {
+		return path, true, nil
+	}
+	path := filepath.Join(b.Path, key)
+	exists, err := ctx.FileExists(path)
+	if err != nil {
+		return "", false, err
+	}
+	return path, exists, nil
+}
@@ -0,0 +1,270 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ar
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb/v2"
+	"github.com/google/go-cmp/cmp"
+)
+
+// setupBindings writes the given binding files, keyed by <binding>/<key>, to a service binding
+// root and returns it.
+func setupBindings(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("creating directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+	t.Setenv(libcnb.EnvServiceBindings, root)
+	return root
+}
+
+func TestBindingProviderCredentials(t *testing.T) {
+	testCases := []struct {
+		name      string
+		files     map[string]string
+		ecosystem Ecosystem
+		want      []Credential
+		wantErr   bool
+	}{
+		{
+			name: "repository binding",
+			files: map[string]string{
+				"devpi/type":      RepositoryBindingType,
+				"devpi/ecosystem": "python",
+				"devpi/url":       "https://devpi.example.com/root/prod/+simple/",
+				"devpi/username":  "ci",
+				"devpi/password":  "secret\n",
+				"devpi/index":     "true",
+				"npm/type":        RepositoryBindingType,
+				"npm/ecosystem":   "npm",
+				"npm/url":         "https://npm.example.com/",
+				"npm/token":       "token",
+				"other/type":      "mysql",
+			},
+			ecosystem: Python,
+			want: []Credential{
+				{Ecosystem: Python, URL: "https://devpi.example.com/root/prod/+simple/", Username: "ci", Password: "secret", Index: true},
+			},
+		},
+		{
+			name:      "no bindings",
+			ecosystem: Python,
+		},
+		{
+			name: "invalid index",
+			files: map[string]string{
+				"devpi/type":      RepositoryBindingType,
+				"devpi/ecosystem": "python",
+				"devpi/url":       "https://devpi.example.com/",
+				"devpi/token":     "token",
+				"devpi/index":     "sometimes",
+			},
+			ecosystem: Python,
+			wantErr:   true,
+		},
+		{
+			name: "missing url",
+			files: map[string]string{
+				"devpi/type":      RepositoryBindingType,
+				"devpi/ecosystem": "python",
+				"devpi/token":     "token",
+			},
+			ecosystem: Python,
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setupBindings(t, tc.files)
+
+			got, err := bindingProvider{}.Credentials(gcp.NewContext(), tc.ecosystem)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("Credentials() got error: %v, want error? %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Credentials() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGenerateNPMConfigWithBinding(t *testing.T) {
+	stubDefaultCredentials(t, fmt.Errorf("no credentials"))
+	setupBindings(t, map[string]string{
+		"npmrc/type":     NPMRCBindingType,
+		"npmrc/.npmrc":   "//registry.example.com/:_authToken=secret\n",
+		"repo/type":      RepositoryBindingType,
+		"repo/url":       "https://npm.example.com/",
+		"repo/token":     "token",
+		"repo/ecosystem": "npm",
+	})
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if err := GenerateNPMConfig(gcp.NewContext(gcp.WithApplicationRoot(t.TempDir()))); err != nil {
+		t.Fatalf("GenerateNPMConfig() got error: %v", err)
+	}
+
+	want := `//registry.example.com/:_authToken=secret
+
+//npm.example.com/:_authToken=token
+`
+	got, err := os.ReadFile(filepath.Join(home, ".npmrc"))
+	if err != nil {
+		t.Fatalf("reading .npmrc: %v", err)
+	}
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("unexpected .npmrc (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateYarnConfigWithBinding(t *testing.T) {
+	want := "npmRegistryServer: \"https://npm.example.com\"\nnpmAuthToken: secret\n"
+	setupBindings(t, map[string]string{
+		"yarn/type":        YarnRCBindingType,
+		"yarn/.yarnrc.yml": want,
+	})
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if err := GenerateYarnConfig(gcp.NewContext(gcp.WithApplicationRoot(t.TempDir()))); err != nil {
+		t.Fatalf("GenerateYarnConfig() got error: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(home, ".yarnrc.yml"))
+	if err != nil {
+		t.Fatalf("reading .yarnrc.yml: %v", err)
+	}
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("unexpected .yarnrc.yml (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateMavenSettingsWithBinding(t *testing.T) {
+	t.Setenv(CredentialsEnv, testCredentials)
+	root := setupBindings(t, map[string]string{
+		"maven/type":         MavenBindingType,
+		"maven/settings.xml": "<settings/>",
+	})
+
+	got, err := GenerateMavenSettings(gcp.NewContext())
+	if err != nil {
+		t.Fatalf("GenerateMavenSettings() got error: %v", err)
+	}
+	if want := filepath.Join(root, "maven", "settings.xml"); got != want {
+		t.Errorf("GenerateMavenSettings() = %q, want %q", got, want)
+	}
+}
+
+func TestGenerateComposerAuth(t *testing.T) {
+	setupBindings(t, map[string]string{
+		"composer/type":      ComposerBindingType,
+		"composer/auth.json": `{"github-oauth": {"github.com": "gh-token"}}`,
+		"repo/type":          RepositoryBindingType,
+		"repo/ecosystem":     "composer",
+		"repo/url":           "https://repo.packagist.com/acme/",
+		"repo/username":      "token",
+		"repo/password":      "secret",
+	})
+	t.Setenv(composerAuthEnv, "")
+	os.Unsetenv(composerAuthEnv)
+
+	if err := GenerateComposerAuth(gcp.NewContext()); err != nil {
+		t.Fatalf("GenerateComposerAuth() got error: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal([]byte(os.Getenv(composerAuthEnv)), &got); err != nil {
+		t.Fatalf("parsing %s=%q: %v", composerAuthEnv, os.Getenv(composerAuthEnv), err)
+	}
+	want := map[string]any{
+		"github-oauth": map[string]any{"github.com": "gh-token"},
+		"http-basic":   map[string]any{"repo.packagist.com": map[string]any{"username": "token", "password": "secret"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected %s (-want +got):\n%s", composerAuthEnv, diff)
+	}
+}
+
+func TestGenerateBundlerConfig(t *testing.T) {
+	root := setupBindings(t, map[string]string{
+		"bundler/type":   BundlerBindingType,
+		"bundler/config": "BUNDLE_JOBS: \"4\"\n",
+		"gems/type":      RepositoryBindingType,
+		"gems/ecosystem": "rubygems",
+		"gems/url":       "https://gems.my-company.example.com",
+		"gems/username":  "ci",
+		"gems/password":  "secret",
+	})
+	t.Setenv(bundlerConfigEnv, "")
+	os.Unsetenv(bundlerConfigEnv)
+	t.Setenv("BUNDLE_GEMS__MY___COMPANY__EXAMPLE__COM", "")
+
+	if err := GenerateBundlerConfig(gcp.NewContext()); err != nil {
+		t.Fatalf("GenerateBundlerConfig() got error: %v", err)
+	}
+
+	for env, want := range map[string]string{
+		bundlerConfigEnv: filepath.Join(root, "bundler", "config"),
+		"BUNDLE_GEMS__MY___COMPANY__EXAMPLE__COM": "ci:secret",
+	} {
+		if got := os.Getenv(env); got != want {
+			t.Errorf("%s = %q, want %q", env, got, want)
+		}
+	}
+}
+
+func TestGenerateGoConfig(t *testing.T) {
+	setupBindings(t, map[string]string{
+		"github/type":      RepositoryBindingType,
+		"github/ecosystem": "go",
+		"github/url":       "https://github.com/my-org/",
+		"github/username":  "ci",
+		"github/password":  "ghp_secret",
+	})
+	t.Setenv(goPrivateEnv, "example.com/private")
+	t.Setenv(goNetrcEnv, "")
+	ctx := gcp.NewContext(gcp.WithBuildContext(libcnb.BuildContext{Layers: libcnb.Layers{Path: t.TempDir()}}))
+
+	if err := GenerateGoConfig(ctx); err != nil {
+		t.Fatalf("GenerateGoConfig() got error: %v", err)
+	}
+
+	if got, want := os.Getenv(goPrivateEnv), "example.com/private,github.com/my-org"; got != want {
+		t.Errorf("%s = %q, want %q", goPrivateEnv, got, want)
+	}
+	netrc, err := os.ReadFile(os.Getenv(goNetrcEnv))
+	if err != nil {
+		t.Fatalf("reading %s: %v", goNetrcEnv, err)
+	}
+	if got, want := string(netrc), "\nmachine github.com login ci password ghp_secret\n"; got != want {
+		t.Errorf("netrc = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,337 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ar
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const (
+	mavenSettingsName = "settings.xml"
+	// mavenProfileID is the settings.xml profile that adds the repositories that are indexes.
+	mavenProfileID  = "google-repositories"
+	nugetConfigPath = ".nuget/NuGet/NuGet.Config"
+
+	composerAuthName  = "auth.json"
+	composerAuthEnv   = "COMPOSER_AUTH"
+	bundlerConfigName = "config"
+	bundlerConfigEnv  = "BUNDLE_USER_CONFIG"
+	goPrivateEnv      = "GOPRIVATE"
+	goNetrcEnv        = "NETRC"
+	goNetrcDir        = "go-netrc"
+)
+
+type mavenSettings struct {
+	XMLName        xml.Name       `xml:"settings"`
+	Xmlns          string         `xml:"xmlns,attr"`
+	Servers        []mavenServer  `xml:"servers>server"`
+	Profiles       []mavenProfile `xml:"profiles>profile,omitempty"`
+	ActiveProfiles []string       `xml:"activeProfiles>activeProfile,omitempty"`
+}
+
+type mavenServer struct {
+	ID            string             `xml:"id"`
+	Username      string             `xml:"username,omitempty"`
+	Password      string             `xml:"password,omitempty"`
+	Configuration *mavenServerConfig `xml:"configuration,omitempty"`
+}
+
+type mavenServerConfig struct {
+	HTTPHeaders []mavenProperty `xml:"httpHeaders>property"`
+}
+
+type mavenProperty struct {
+	Name  string `xml:"name"`
+	Value string `xml:"value"`
+}
+
+type mavenProfile struct {
+	ID           string            `xml:"id"`
+	Repositories []mavenRepository `xml:"repositories>repository"`
+}
+
+type mavenRepository struct {
+	ID  string `xml:"id"`
+	URL string `xml:"url"`
+}
+
+// GenerateMavenSettings generates a Maven settings.xml file with the credentials of the configured
+// Maven repositories and returns its path, or "" if there are no credentials. The file is created
+// outside of the Maven cache so that the credentials are not cached, and is meant to be passed as
+// the global settings, which Maven merges with the user settings. The settings.xml of a maven
+// service binding is used as is instead, if there is one.
+func GenerateMavenSettings(ctx *gcp.Context) (string, error) {
+	bindingSettings, err := bindingFile(ctx, MavenBindingType, mavenSettingsName)
+	if err != nil {
+		return "", err
+	}
+	if bindingSettings != "" {
+		ctx.Logf("Using Maven settings from service binding: %s", bindingSettings)
+		return bindingSettings, nil
+	}
+	creds, _, err := credentialsFor(ctx, Maven)
+	if err != nil || len(creds) == 0 {
+		return "", err
+	}
+	dir, err := ctx.TempDir("maven-settings")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, mavenSettingsName)
+	f, err := ctx.CreateFile(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if err := writeMavenSettings(f, creds); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// writeMavenSettings writes the settings.xml contents for authenticating to the repositories.
+func writeMavenSettings(wr io.Writer, creds []Credential) error {
+	settings := mavenSettings{Xmlns: "http://maven.apache.org/SETTINGS/1.0.0"}
+	var repos []mavenRepository
+	for _, c := range creds {
+		server := mavenServer{ID: c.id()}
+		if c.Username == "" {
+			// Bearer tokens are sent as a header, Maven only supports basic authentication otherwise.
+			server.Configuration = &mavenServerConfig{HTTPHeaders: []mavenProperty{{Name: "Authorization", Value: "Bearer " + c.Token}}}
+		} else {
+			server.Username, server.Password = c.Username, c.Password
+		}
+		settings.Servers = append(settings.Servers, server)
+		if c.Index {
+			repos = append(repos, mavenRepository{ID: c.id(), URL: c.URL})
+		}
+	}
+	if len(repos) > 0 {
+		settings.Profiles = []mavenProfile{{ID: mavenProfileID, Repositories: repos}}
+		settings.ActiveProfiles = []string{mavenProfileID}
+	}
+	return writeXML(wr, settings)
+}
+
+type nugetConfig struct {
+	XMLName                  xml.Name           `xml:"configuration"`
+	PackageSources           []nugetAdd         `xml:"packageSources>add,omitempty"`
+	PackageSourceCredentials []nugetCredentials `xml:"packageSourceCredentials>source,omitempty"`
+}
+
+type nugetAdd struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// nugetCredentials are the credentials of a package source, in an element named after its key.
+type nugetCredentials struct {
+	XMLName xml.Name
+	Add     []nugetAdd `xml:"add"`
+}
+
+// GenerateNuGetConfig generates a NuGet.Config file in the user's HOME directory with the
+// credentials of the configured NuGet repositories. Repositories that are not indexes must be
+// added as package sources by the project nuget.config with their ID as key.
+func GenerateNuGetConfig(ctx *gcp.Context) error {
+	userConfig := filepath.Join(ctx.HomeDir(), nugetConfigPath)
+	userConfigExists, err := ctx.FileExists(userConfig)
+	if err != nil {
+		return err
+	}
+	if userConfigExists {
+		ctx.Debugf("Found an existing user-level %s file. Skipping %s creation.", userConfig, userConfig)
+		return nil
+	}
+	creds, _, err := credentialsFor(ctx, NuGet)
+	if err != nil || len(creds) == 0 {
+		return err
+	}
+	if err := ctx.MkdirAll(filepath.Dir(userConfig), 0755); err != nil {
+		return err
+	}
+	f, err := ctx.CreateFile(userConfig)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return writeNuGetConfig(f, creds)
+}
+
+// writeNuGetConfig writes the NuGet.Config contents for authenticating to the repositories.
+func writeNuGetConfig(wr io.Writer, creds []Credential) error {
+	var cfg nugetConfig
+	for _, c := range creds {
+		if c.Index {
+			cfg.PackageSources = append(cfg.PackageSources, nugetAdd{Key: c.id(), Value: c.URL})
+		}
+		login, password := c.login()
+		cfg.PackageSourceCredentials = append(cfg.PackageSourceCredentials, nugetCredentials{
+			XMLName: xml.Name{Local: c.id()},
+			Add: []nugetAdd{
+				{Key: "Username", Value: login},
+				{Key: "ClearTextPassword", Value: password},
+			},
+		})
+	}
+	return writeXML(wr, cfg)
+}
+
+func writeXML(wr io.Writer, v any) error {
+	if _, err := io.WriteString(wr, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(wr)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("encoding %T: %w", v, err)
+	}
+	_, err := io.WriteString(wr, "\n")
+	return err
+}
+
+// GenerateGoConfig configures the go command to download private modules from the configured Go
+// repositories: their credentials are written to a netrc file and their module paths are added to
+// GOPRIVATE, e.g. https://github.com/myorg adds github.com/myorg. The environment of the current
+// process is updated, so commands run afterwards by the buildpack use the configuration.
+func GenerateGoConfig(ctx *gcp.Context) error {
+	creds, _, err := credentialsFor(ctx, Go)
+	if err != nil || len(creds) == 0 {
+		return err
+	}
+	dir, err := ctx.TempDir(goNetrcDir)
+	if err != nil {
+		return err
+	}
+	netrc := filepath.Join(dir, pythonConfigName)
+	f, err := ctx.CreateFile(netrc)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := writePythonConfig(f, creds); err != nil {
+		return err
+	}
+	if err := ctx.Setenv(goNetrcEnv, netrc); err != nil {
+		return err
+	}
+
+	var private []string
+	if v := os.Getenv(goPrivateEnv); v != "" {
+		private = append(private, v)
+	}
+	for _, c := range creds {
+		modulePath := c.host()
+		if u, err := url.Parse(c.URL); err == nil {
+			modulePath += strings.TrimSuffix(u.Path, "/")
+		}
+		private = append(private, modulePath)
+	}
+	ctx.Debugf("Setting %s=%s", goPrivateEnv, strings.Join(private, ","))
+	return ctx.Setenv(goPrivateEnv, strings.Join(private, ","))
+}
+
+// GenerateComposerAuth sets the COMPOSER_AUTH env var of the current process from the auth.json of
+// a composer service binding and the credentials of the configured Composer repositories. It does
+// nothing if COMPOSER_AUTH is already set.
+func GenerateComposerAuth(ctx *gcp.Context) error {
+	if _, ok := os.LookupEnv(composerAuthEnv); ok {
+		ctx.Debugf("%s is already set. Skipping Composer authentication.", composerAuthEnv)
+		return nil
+	}
+	auth := map[string]map[string]any{}
+	bindingAuth, err := bindingFile(ctx, ComposerBindingType, composerAuthName)
+	if err != nil {
+		return err
+	}
+	if bindingAuth != "" {
+		content, err := ctx.ReadFile(bindingAuth)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(content, &auth); err != nil {
+			return gcp.UserErrorf("parsing %s from service binding: %w", composerAuthName, err)
+		}
+	}
+	creds, _, err := credentialsFor(ctx, Composer)
+	if err != nil {
+		return err
+	}
+	if bindingAuth == "" && len(creds) == 0 {
+		return nil
+	}
+	for _, c := range creds {
+		method, value := "http-basic", any(map[string]string{"username": c.Username, "password": c.Password})
+		if c.Username == "" {
+			method, value = "bearer", c.Token
+		}
+		if auth[method] == nil {
+			auth[method] = map[string]any{}
+		}
+		auth[method][c.host()] = value
+	}
+	content, err := json.Marshal(auth)
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", composerAuthEnv, err)
+	}
+	return ctx.Setenv(composerAuthEnv, string(content))
+}
+
+// GenerateBundlerConfig configures Bundler with the config of a bundle-config service binding and
+// the credentials of the configured gem sources, set as BUNDLE_<HOST> env vars of the current
+// process (see https://bundler.io/man/bundle-config.1.html#CREDENTIALS-FOR-GEM-SOURCES).
+func GenerateBundlerConfig(ctx *gcp.Context) error {
+	bindingConfig, err := bindingFile(ctx, BundlerBindingType, bundlerConfigName)
+	if err != nil {
+		return err
+	}
+	if bindingConfig != "" {
+		if _, ok := os.LookupEnv(bundlerConfigEnv); ok {
+			ctx.Warnf("%s is already set. Ignoring Bundler config from service binding.", bundlerConfigEnv)
+		} else if err := ctx.Setenv(bundlerConfigEnv, bindingConfig); err != nil {
+			return err
+		}
+	}
+	creds, _, err := credentialsFor(ctx, RubyGems)
+	if err != nil {
+		return err
+	}
+	for _, c := range creds {
+		value := c.Token
+		if c.Username != "" {
+			value = c.Username + ":" + c.Password
+		}
+		if err := ctx.Setenv(bundlerCredentialsEnv(c.host()), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bundlerCredentialsEnv returns the env var Bundler reads the credentials of a host from, e.g.
+// BUNDLE_GEMS__EXAMPLE__COM for gems.example.com.
+func bundlerCredentialsEnv(host string) string {
+	key := strings.NewReplacer("-", "___", ".", "__").Replace(strings.ToUpper(host))
+	return "BUNDLE_" + key
+}
@@ -0,0 +1,294 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ar
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const (
+	// CredentialsEnv is the env var containing a JSON list of package repository credentials.
+	CredentialsEnv = "GOOGLE_REPOSITORY_CREDENTIALS"
+	// CredentialsFileEnv is the env var with the path of a file containing a JSON list of package
+	// repository credentials, e.g. a secret mounted at build time.
+	CredentialsFileEnv = "GOOGLE_REPOSITORY_CREDENTIALS_FILE"
+
+	artifactRegistryProviderName = "Artifact Registry"
+	configuredProviderName       = "configured repositories"
+)
+
+// Ecosystem is a package ecosystem that repository credentials apply to.
+type Ecosystem string
+
+const (
+	// Python is the ecosystem of pip, uv and other Python package managers.
+	Python Ecosystem = "python"
+	// NPM is the ecosystem of npm, yarn and pnpm.
+	NPM Ecosystem = "npm"
+	// Maven is the ecosystem of Maven repositories.
+	Maven Ecosystem = "maven"
+	// NuGet is the ecosystem of .NET packages.
+	NuGet Ecosystem = "nuget"
+	// Go is the ecosystem of private Go modules.
+	Go Ecosystem = "go"
+	// Composer is the ecosystem of PHP packages.
+	Composer Ecosystem = "composer"
+	// RubyGems is the ecosystem of Bundler gem sources.
+	RubyGems Ecosystem = "rubygems"
+)
+
+// ecosystems are all the valid ecosystems.
+var ecosystems = []Ecosystem{Python, NPM, Maven, NuGet, Go, Composer, RubyGems}
+
+// Credential authenticates requests to a package repository.
+type Credential struct {
+	// Ecosystem is the package ecosystem of the repository.
+	Ecosystem Ecosystem `json:"ecosystem"`
+	// ID identifies the repository in Maven settings.xml and nuget.config, defaults to the host.
+	ID string `json:"id,omitempty"`
+	// URL is the URL of the repository, e.g. https://devpi.example.com/root/prod/+simple/.
+	URL string `json:"url"`
+	// Username and Password are basic authentication credentials.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Token is a bearer token, used when there is no Username.
+	Token string `json:"token,omitempty"`
+	// Index adds the repository to the package sources, not only to the authenticated hosts.
+	Index bool `json:"index,omitempty"`
+	// Scope is the npm scope served by the repository when it is an index, e.g. @myorg.
+	Scope string `json:"scope,omitempty"`
+}
+
+// host returns the host of the repository URL, or the URL itself if it cannot be parsed.
+func (c Credential) host() string {
+	u, err := url.Parse(c.URL)
+	if err != nil || u.Host == "" {
+		return c.URL
+	}
+	return u.Hostname()
+}
+
+// id returns the configured ID of the repository, or its host.
+func (c Credential) id() string {
+	if c.ID != "" {
+		return c.ID
+	}
+	return c.host()
+}
+
+// login returns the username and password of the credential. Tokens are sent as the password of
+// the __token__ user, which is the convention of PyPI compatible repositories.
+func (c Credential) login() (string, string) {
+	if c.Username == "" && c.Token != "" {
+		return "__token__", c.Token
+	}
+	return c.Username, c.Password
+}
+
+// npmAuth returns the .npmrc auth setting of the credential, e.g.
+// //registry.example.com/npm/:_authToken=token.
+func (c Credential) npmAuth() string {
+	prefix := strings.TrimPrefix(strings.TrimPrefix(c.URL, "https:"), "http:")
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	if c.Username == "" {
+		return prefix + ":_authToken=" + c.Token
+	}
+	return prefix + ":_auth=" + base64.StdEncoding.EncodeToString([]byte(c.Username+":"+c.Password))
+}
+
+// Provider provides the credentials of package repositories.
+type Provider interface {
+	// Name describes the provider in logs.
+	Name() string
+	// Credentials returns the credentials of the repositories of an ecosystem.
+	Credentials(ctx *gcp.Context, ecosystem Ecosystem) ([]Credential, error)
+}
+
+// providers are the credential providers used to generate package manager configs, in order of
+// precedence.
+var providers = []Provider{configuredProvider{}, bindingProvider{}, artifactRegistryProvider{}}
+
+// credentialsFor returns the credentials of all providers for an ecosystem, and whether any of
+// them is an Artifact Registry credential.
+func credentialsFor(ctx *gcp.Context, ecosystem Ecosystem) ([]Credential, bool, error) {
+	var all []Credential
+	hasAR := false
+	for _, p := range providers {
+		creds, err := p.Credentials(ctx, ecosystem)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(creds) == 0 {
+			continue
+		}
+		ctx.Debugf("Found %d %s repository credentials from %s.", len(creds), ecosystem, p.Name())
+		if p.Name() == artifactRegistryProviderName {
+			hasAR = true
+		}
+		all = append(all, creds...)
+	}
+	return all, hasAR, nil
+}
+
+// configuredProvider provides the credentials set in the CredentialsEnv env var and in the file
+// named by the CredentialsFileEnv env var.
+type configuredProvider struct{}
+
+func (configuredProvider) Name() string {
+	return configuredProviderName
+}
+
+func (configuredProvider) Credentials(ctx *gcp.Context, ecosystem Ecosystem) ([]Credential, error) {
+	var all []Credential
+	if path := os.Getenv(CredentialsFileEnv); path != "" {
+		content, err := ctx.ReadFile(path)
+		if err != nil {
+			return nil, gcp.UserErrorf("reading %s file %s: %w", CredentialsFileEnv, path, err)
+		}
+		creds, err := parseCredentials(content)
+		if err != nil {
+			return nil, gcp.UserErrorf("parsing %s file %s: %w", CredentialsFileEnv, path, err)
+		}
+		all = append(all, creds...)
+	}
+	if v := os.Getenv(CredentialsEnv); v != "" {
+		creds, err := parseCredentials([]byte(v))
+		if err != nil {
+			return nil, gcp.UserErrorf("parsing %s: %w", CredentialsEnv, err)
+		}
+		all = append(all, creds...)
+	}
+	var result []Credential
+	for _, c := range all {
+		if c.Ecosystem == ecosystem {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// parseCredentials parses and validates a JSON list of credentials.
+func parseCredentials(content []byte) ([]Credential, error) {
+	var creds []Credential
+	if err := json.Unmarshal(content, &creds); err != nil {
+		return nil, err
+	}
+	for i, c := range creds {
+		if err := c.validate(); err != nil {
+			return nil, fmt.Errorf("credential %d: %w", i, err)
+		}
+	}
+	return creds, nil
+}
+
+// validate returns an error if the credential is incomplete.
+func (c Credential) validate() error {
+	valid := false
+	for _, e := range ecosystems {
+		valid = valid || c.Ecosystem == e
+	}
+	if !valid {
+		return fmt.Errorf("invalid ecosystem %q, must be one of %q", c.Ecosystem, ecosystems)
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid url %q", c.URL)
+	}
+	if c.Username == "" && c.Token == "" {
+		return fmt.Errorf("%s has neither a username nor a token", u.Host)
+	}
+	return nil
+}
+
+// artifactRegistryProvider provides Application Default Credentials for Artifact Registry.
+type artifactRegistryProvider struct{}
+
+func (artifactRegistryProvider) Name() string {
+	return artifactRegistryProviderName
+}
+
+func (artifactRegistryProvider) Credentials(ctx *gcp.Context, ecosystem Ecosystem) ([]Credential, error) {
+	var urls []string
+	switch ecosystem {
+	case Python:
+		for _, host := range arRepositories() {
+			urls = append(urls, "https://"+host)
+		}
+	case NPM:
+		repos, err := npmRepositories(ctx)
+		if err != nil || len(repos) == 0 {
+			return nil, err
+		}
+		for _, repo := range repos {
+			urls = append(urls, "https:"+repo)
+		}
+	default:
+		return nil, nil
+	}
+
+	tok, err := findDefaultCredentials()
+	if err != nil {
+		// findDefaultCredentials will return an error any time Application Default Credentials are
+		// missing (e.g. running the buildpacks locally outside of GCB). Credentials might not
+		// be required for the install to succeed so we should not fail the build here.
+		if ecosystem == NPM {
+			ctx.Warnf("Skipping Artifact Registry credentials. Unable to find Application Default Credentials: %v", err)
+		} else {
+			ctx.Debugf("Unable to find Application Default Credentials. Skipping Artifact Registry credentials.")
+		}
+		return nil, nil
+	}
+
+	var creds []Credential
+	for _, u := range urls {
+		c := Credential{Ecosystem: ecosystem, URL: u, Token: tok}
+		if ecosystem == Python {
+			c.Username, c.Password = "oauth2accesstoken", tok
+		}
+		creds = append(creds, c)
+	}
+	return creds, nil
+}
+
+// npmRepositories returns the Artifact Registry repositories configured in the project .npmrc,
+// e.g. //us-west1-npm.pkg.dev/my-project/my-repo/.
+func npmRepositories(ctx *gcp.Context) ([]string, error) {
+	projectConfig := filepath.Join(ctx.ApplicationRoot(), npmConfigName)
+	projConfigExists, err := ctx.FileExists(projectConfig)
+	if err != nil || !projConfigExists {
+		// Unlike Python, NPM credentials must be configured per repo. If the devoloper has not included
+		// a project-level npmrc, there are no AR repos to set credentials for.
+		return nil, nil
+	}
+	content, err := ctx.ReadFile(projectConfig)
+	if err != nil {
+		return nil, err
+	}
+	var repos []string
+	for _, m := range npmRegistryRegexp.FindAllStringSubmatch(string(content), -1) {
+		repos = append(repos, m[2])
+	}
+	return repos, nil
+}
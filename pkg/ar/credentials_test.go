@@ -0,0 +1,252 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ar
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb/v2"
+	"github.com/google/go-cmp/cmp"
+)
+
+const testCredentials = `[
+  {"ecosystem": "python", "url": "https://devpi.example.com/root/prod/+simple/", "username": "ci", "password": "secret", "index": true},
+  {"ecosystem": "python", "url": "https://pypi.example.com/simple/", "token": "pypi-token"},
+  {"ecosystem": "npm", "url": "https://jfrog.example.com/artifactory/api/npm/npm/", "token": "npm-token", "index": true, "scope": "@myorg"},
+  {"ecosystem": "npm", "url": "https://npm.example.com", "username": "ci", "password": "secret"},
+  {"ecosystem": "maven", "id": "jfrog", "url": "https://jfrog.example.com/artifactory/maven/", "username": "ci", "password": "secret", "index": true},
+  {"ecosystem": "nuget", "id": "jfrog", "url": "https://jfrog.example.com/artifactory/api/nuget/v3/nuget", "token": "nuget-token", "index": true}
+]`
+
+// stubDefaultCredentials stubs out the logic for fetching Application Default Credentials.
+func stubDefaultCredentials(t *testing.T, err error) {
+	t.Helper()
+	orig := findDefaultCredentials
+	findDefaultCredentials = func() (string, error) {
+		return "token", err
+	}
+	t.Cleanup(func() {
+		findDefaultCredentials = orig
+	})
+}
+
+func TestConfiguredProviderCredentials(t *testing.T) {
+	testCases := []struct {
+		name      string
+		env       string
+		file      string
+		ecosystem Ecosystem
+		want      []Credential
+		wantErr   bool
+	}{
+		{
+			name:      "from env",
+			env:       testCredentials,
+			ecosystem: Maven,
+			want: []Credential{
+				{Ecosystem: Maven, ID: "jfrog", URL: "https://jfrog.example.com/artifactory/maven/", Username: "ci", Password: "secret", Index: true},
+			},
+		},
+		{
+			name:      "from file and env",
+			file:      `[{"ecosystem": "nuget", "url": "https://nuget.example.com/v3/index.json", "token": "file-token"}]`,
+			env:       testCredentials,
+			ecosystem: NuGet,
+			want: []Credential{
+				{Ecosystem: NuGet, URL: "https://nuget.example.com/v3/index.json", Token: "file-token"},
+				{Ecosystem: NuGet, ID: "jfrog", URL: "https://jfrog.example.com/artifactory/api/nuget/v3/nuget", Token: "nuget-token", Index: true},
+			},
+		},
+		{
+			name:      "none configured",
+			ecosystem: Python,
+		},
+		{
+			name:    "invalid json",
+			env:     `{"ecosystem": "python"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid ecosystem",
+			env:     `[{"ecosystem": "cargo", "url": "https://example.com", "token": "t"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid url",
+			env:     `[{"ecosystem": "python", "url": "example.com/simple", "token": "t"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "missing secret",
+			env:     `[{"ecosystem": "python", "url": "https://example.com/simple"}]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(CredentialsEnv, tc.env)
+			t.Setenv(CredentialsFileEnv, "")
+			if tc.file != "" {
+				path := filepath.Join(t.TempDir(), "credentials.json")
+				if err := os.WriteFile(path, []byte(tc.file), 0600); err != nil {
+					t.Fatalf("writing %s: %v", path, err)
+				}
+				t.Setenv(CredentialsFileEnv, path)
+			}
+
+			got, err := configuredProvider{}.Credentials(gcp.NewContext(), tc.ecosystem)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("Credentials() got error: %v, want error? %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Credentials() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGeneratePythonConfigWithConfiguredRepositories(t *testing.T) {
+	stubDefaultCredentials(t, fmt.Errorf("no credentials"))
+	t.Setenv(CredentialsEnv, testCredentials)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if err := GeneratePythonConfig(gcp.NewContext()); err != nil {
+		t.Fatalf("GeneratePythonConfig() got error: %v", err)
+	}
+
+	wantFiles := map[string]string{
+		".netrc": `
+machine devpi.example.com login ci password secret
+machine pypi.example.com login __token__ password pypi-token
+`,
+		".config/pip/pip.conf": `[global]
+extra-index-url =
+    https://devpi.example.com/root/prod/+simple/
+`,
+	}
+	for name, want := range wantFiles {
+		got, err := os.ReadFile(filepath.Join(home, name))
+		if err != nil {
+			t.Fatalf("reading %s: %v", name, err)
+		}
+		if diff := cmp.Diff(want, string(got)); diff != "" {
+			t.Errorf("unexpected %s (-want +got):\n%s", name, diff)
+		}
+	}
+}
+
+func TestGenerateNPMConfigWithConfiguredRepositories(t *testing.T) {
+	stubDefaultCredentials(t, nil)
+	t.Setenv(CredentialsEnv, testCredentials)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ".npmrc"), []byte("registry=https://us-west1-npm.pkg.dev/my-project/my-repo/\n"), 0644); err != nil {
+		t.Fatalf("writing .npmrc: %v", err)
+	}
+
+	if err := GenerateNPMConfig(gcp.NewContext(gcp.WithApplicationRoot(root))); err != nil {
+		t.Fatalf("GenerateNPMConfig() got error: %v", err)
+	}
+
+	want := `
+@myorg:registry=https://jfrog.example.com/artifactory/api/npm/npm/
+//jfrog.example.com/artifactory/api/npm/npm/:_authToken=npm-token
+//npm.example.com/:_auth=Y2k6c2VjcmV0
+//us-west1-npm.pkg.dev/my-project/my-repo/:_authToken=token
+`
+	got, err := os.ReadFile(filepath.Join(home, ".npmrc"))
+	if err != nil {
+		t.Fatalf("reading .npmrc: %v", err)
+	}
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("unexpected .npmrc (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateMavenSettings(t *testing.T) {
+	t.Setenv(CredentialsEnv, testCredentials)
+
+	ctx := gcp.NewContext(gcp.WithBuildContext(libcnb.BuildContext{Layers: libcnb.Layers{Path: t.TempDir()}}))
+
+	path, err := GenerateMavenSettings(ctx)
+	if err != nil {
+		t.Fatalf("GenerateMavenSettings() got error: %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+
+	for _, want := range []string{
+		"<id>jfrog</id>\n      <username>ci</username>\n      <password>secret</password>",
+		"<url>https://jfrog.example.com/artifactory/maven/</url>",
+		"<activeProfile>google-repositories</activeProfile>",
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("settings.xml does not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestGenerateMavenSettingsWithoutCredentials(t *testing.T) {
+	t.Setenv(CredentialsEnv, "")
+
+	path, err := GenerateMavenSettings(gcp.NewContext())
+	if err != nil {
+		t.Fatalf("GenerateMavenSettings() got error: %v", err)
+	}
+	if path != "" {
+		t.Errorf("GenerateMavenSettings() = %q, want no settings", path)
+	}
+}
+
+func TestGenerateNuGetConfig(t *testing.T) {
+	t.Setenv(CredentialsEnv, testCredentials)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if err := GenerateNuGetConfig(gcp.NewContext()); err != nil {
+		t.Fatalf("GenerateNuGetConfig() got error: %v", err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<configuration>
+  <packageSources>
+    <add key="jfrog" value="https://jfrog.example.com/artifactory/api/nuget/v3/nuget"></add>
+  </packageSources>
+  <packageSourceCredentials>
+    <jfrog>
+      <add key="Username" value="__token__"></add>
+      <add key="ClearTextPassword" value="nuget-token"></add>
+    </jfrog>
+  </packageSourceCredentials>
+</configuration>
+`
+	got, err := os.ReadFile(filepath.Join(home, nugetConfigPath))
+	if err != nil {
+		t.Fatalf("reading %s: %v", nugetConfigPath, err)
+	}
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf("unexpected NuGet.Config (-want +got):\n%s", diff)
+	}
+}
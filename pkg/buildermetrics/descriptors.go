@@ -42,6 +42,7 @@ const (
 	PipInstallLatencyID                   MetricID = "10"
 	JavaGAEWebXMLConfigUsageCounterID     MetricID = "11"
 	JavaGAESessionsEnabledCounterID       MetricID = "12"
+	PipPrunedBytesCounterID               MetricID = "13"
 )
 
 var (
@@ -106,5 +107,10 @@ var (
 			"java_gae_session_handler_uses",
 			"The number of times the session handler is used by developers",
 		),
+		PipPrunedBytesCounterID: newDescriptor(
+			PipPrunedBytesCounterID,
+			"pip_pruned_bytes",
+			"The number of bytes pruned from Python dependencies layers",
+		),
 	}
 )
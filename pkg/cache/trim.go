@@ -0,0 +1,84 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// TrimResult reports the files removed by Trim.
+type TrimResult struct {
+	// Bytes is the total size of the files before trimming.
+	Bytes int64
+	// RemovedFiles is the number of files removed.
+	RemovedFiles int
+	// RemovedBytes is the total size of the files removed.
+	RemovedBytes int64
+}
+
+// Trim removes the regular files under dir which were not modified for maxAge, then, if the
+// remaining files exceed maxBytes, removes the least recently modified files until they are at
+// most targetBytes. A zero maxAge or maxBytes disables the corresponding limit. Tools refreshing
+// the modification time of the files they reuse, like the Go build cache, make it a least
+// recently used eviction.
+func Trim(dir string, maxAge time.Duration, maxBytes, targetBytes int64) (TrimResult, error) {
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []file
+	var res TrimResult
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, file{path: path, size: info.Size(), modTime: info.ModTime()})
+		res.Bytes += info.Size()
+		return nil
+	})
+	if err != nil {
+		return res, fmt.Errorf("measuring %s: %w", dir, err)
+	}
+
+	total := res.Bytes
+	sort.SliceStable(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	overSize := maxBytes > 0 && total > maxBytes
+	for _, f := range files {
+		expired := maxAge > 0 && time.Since(f.modTime) > maxAge
+		if !expired && (!overSize || total <= targetBytes) {
+			break
+		}
+		if err := os.Remove(f.path); err != nil {
+			return res, fmt.Errorf("trimming %s: %w", f.path, err)
+		}
+		total -= f.size
+		res.RemovedFiles++
+		res.RemovedBytes += f.size
+	}
+	return res, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrim(t *testing.T) {
+	// Files of 100 bytes with their age.
+	files := map[string]time.Duration{
+		"00/a-a": 10 * 24 * time.Hour,
+		"00/b-d": 3 * 24 * time.Hour,
+		"01/c-a": 2 * time.Hour,
+		"01/d-d": time.Minute,
+	}
+	testCases := []struct {
+		name        string
+		maxAge      time.Duration
+		maxBytes    int64
+		targetBytes int64
+		want        []string
+		wantResult  TrimResult
+	}{
+		{
+			name:       "no limits",
+			want:       []string{"00/a-a", "00/b-d", "01/c-a", "01/d-d"},
+			wantResult: TrimResult{Bytes: 400},
+		},
+		{
+			name:       "by age",
+			maxAge:     5 * 24 * time.Hour,
+			want:       []string{"00/b-d", "01/c-a", "01/d-d"},
+			wantResult: TrimResult{Bytes: 400, RemovedFiles: 1, RemovedBytes: 100},
+		},
+		{
+			name:        "under the maximum size",
+			maxBytes:    400,
+			targetBytes: 300,
+			want:        []string{"00/a-a", "00/b-d", "01/c-a", "01/d-d"},
+			wantResult:  TrimResult{Bytes: 400},
+		},
+		{
+			name:        "by size",
+			maxBytes:    350,
+			targetBytes: 250,
+			want:        []string{"01/c-a", "01/d-d"},
+			wantResult:  TrimResult{Bytes: 400, RemovedFiles: 2, RemovedBytes: 200},
+		},
+		{
+			name:        "by age and size",
+			maxAge:      time.Hour,
+			maxBytes:    1000,
+			targetBytes: 500,
+			want:        []string{"01/d-d"},
+			wantResult:  TrimResult{Bytes: 400, RemovedFiles: 3, RemovedBytes: 300},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for f, age := range files {
+				path := filepath.Join(dir, f)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(strings.Repeat("x", 100)), 0644); err != nil {
+					t.Fatal(err)
+				}
+				mtime := time.Now().Add(-age)
+				if err := os.Chtimes(path, mtime, mtime); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			res, err := Trim(dir, tc.maxAge, tc.maxBytes, tc.targetBytes)
+			if err != nil {
+				t.Fatalf("Trim() got error: %v", err)
+			}
+			if res != tc.wantResult {
+				t.Errorf("Trim() = %+v, want %+v", res, tc.wantResult)
+			}
+
+			var got []string
+			err = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				rel, err := filepath.Rel(dir, path)
+				got = append(got, rel)
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Trim() remaining files = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
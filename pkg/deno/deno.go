@@ -0,0 +1,338 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package deno contains Deno buildpack library code.
+package deno
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/fetch"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/version"
+	"github.com/Masterminds/semver"
+)
+
+const (
+	// EnvDenoVersion can be used to specify the version of Deno to install.
+	EnvDenoVersion = "GOOGLE_DENO_VERSION"
+	// EnvCompile enables compiling the application into a self-contained binary with deno compile.
+	EnvCompile = "GOOGLE_DENO_COMPILE"
+	// LockFile is the name of the Deno lock file.
+	LockFile = "deno.lock"
+	// versionFile is the Deno version file read by dvm and the setup-deno GitHub action.
+	versionFile = ".dvmrc"
+	// defaultPermissionSet is the permission set applied to deno run and deno serve.
+	defaultPermissionSet = "default"
+)
+
+var (
+	// ConfigFiles are the names of the Deno configuration files, in order of precedence.
+	ConfigFiles = []string{"deno.json", "deno.jsonc"}
+	// latestVersionURL returns the tag of the latest stable Deno release, e.g. v2.1.4.
+	latestVersionURL = "https://dl.deno.land/release-latest.txt"
+	// entrypointCandidates are the modules used as entrypoint when the configuration does not
+	// export one, in order of precedence.
+	entrypointCandidates = []string{"main.ts", "main.js", "server.ts", "server.js", "mod.ts", "index.ts", "index.js"}
+	// permissionNames are the permissions that can be granted with --allow-<name>.
+	permissionNames = map[string]bool{"all": true, "read": true, "write": true, "net": true, "env": true, "sys": true, "run": true, "ffi": true, "import": true}
+	// defaultExportRegexp matches modules declaring the default export served by deno serve.
+	defaultExportRegexp = regexp.MustCompile(`(?m)^\s*export\s+default\b`)
+)
+
+// Config is the subset of a deno.json or deno.jsonc configuration file used by the buildpack.
+type Config struct {
+	// Tasks maps task names to their definitions, which are either a command or an object.
+	Tasks map[string]any `json:"tasks"`
+	// Exports is the module exported by the package, or a map of exported modules.
+	Exports any `json:"exports"`
+	// Permissions maps permission set names to the permissions they grant.
+	Permissions map[string]map[string]json.RawMessage `json:"permissions"`
+}
+
+// RuntimeVersion returns the version of Deno to install, read from GOOGLE_DENO_VERSION,
+// GOOGLE_RUNTIME_VERSION or the .dvmrc file. It defaults to the latest stable release.
+func RuntimeVersion(ctx *gcp.Context) (string, error) {
+	for _, e := range []string{EnvDenoVersion, env.RuntimeVersion} {
+		if v := os.Getenv(e); v != "" {
+			ctx.Logf("Using Deno version from %s: %s", e, v)
+			return exactVersion(v)
+		}
+	}
+	exists, err := ctx.FileExists(ctx.ApplicationRoot(), versionFile)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		content, err := ctx.ReadFile(filepath.Join(ctx.ApplicationRoot(), versionFile))
+		if err != nil {
+			return "", err
+		}
+		v := strings.TrimSpace(string(content))
+		ctx.Logf("Using Deno version from %s: %s", versionFile, v)
+		return exactVersion(v)
+	}
+	var buf bytes.Buffer
+	if err := fetch.GetURL(latestVersionURL, &buf); err != nil {
+		return "", gcp.InternalErrorf("fetching the latest Deno version: %w", err)
+	}
+	ctx.Logf("Deno version not specified, using the latest stable release")
+	return exactVersion(strings.TrimSpace(buf.String()))
+}
+
+// exactVersion strips the "v" prefix of Deno release tags and validates that the result is an
+// exact version, Deno releases are downloaded without a version index to resolve constraints.
+func exactVersion(v string) (string, error) {
+	v = strings.TrimPrefix(v, "v")
+	if !version.IsExactSemver(v) {
+		return "", gcp.UserErrorf("invalid Deno version %q: an exact version such as 2.1.4 is required", v)
+	}
+	return v, nil
+}
+
+// ReadConfig reads the Deno configuration file of the application. It returns nil if there is
+// none.
+func ReadConfig(ctx *gcp.Context) (*Config, error) {
+	for _, name := range ConfigFiles {
+		path := filepath.Join(ctx.ApplicationRoot(), name)
+		exists, err := ctx.FileExists(path)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		content, err := ctx.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var cfg Config
+		if err := json.Unmarshal(stripJSONC(content), &cfg); err != nil {
+			return nil, gcp.UserErrorf("parsing %s: %w", name, err)
+		}
+		return &cfg, nil
+	}
+	return nil, nil
+}
+
+// stripJSONC converts JSON with comments and trailing commas, as accepted in deno.jsonc, to JSON.
+func stripJSONC(in []byte) []byte {
+	var out []byte
+	inString := false
+	for i := 0; i < len(in); i++ {
+		c := in[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(in) {
+				i++
+				out = append(out, in[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(in) && in[i+1] == '/':
+			for i < len(in) && in[i] != '\n' {
+				i++
+			}
+			if i < len(in) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(in) && in[i+1] == '*':
+			end := bytes.Index(in[i+2:], []byte("*/"))
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+			out = append(out, ' ')
+		case c == '}' || c == ']':
+			// Drop a trailing comma before the closing bracket.
+			trimmed := bytes.TrimRight(out, " \t\r\n")
+			if len(trimmed) > 0 && trimmed[len(trimmed)-1] == ',' {
+				out = append(trimmed[:len(trimmed)-1], out[len(trimmed):]...)
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// HasTask returns true if the configuration defines the given task.
+func HasTask(cfg *Config, name string) bool {
+	if cfg == nil {
+		return false
+	}
+	_, ok := cfg.Tasks[name]
+	return ok
+}
+
+// Entrypoint returns the main module of the application: the module exported by the
+// configuration, or the first well-known module found in the application root. It returns an
+// empty string if there is none.
+func Entrypoint(ctx *gcp.Context, cfg *Config) (string, error) {
+	candidates := entrypointCandidates
+	if cfg != nil {
+		switch e := cfg.Exports.(type) {
+		case string:
+			candidates = append([]string{e}, candidates...)
+		case map[string]any:
+			if main, ok := e["."].(string); ok {
+				candidates = append([]string{main}, candidates...)
+			}
+		}
+	}
+	for _, c := range candidates {
+		exists, err := ctx.FileExists(ctx.ApplicationRoot(), c)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return filepath.Clean(c), nil
+		}
+	}
+	return "", nil
+}
+
+// IsServeModule returns true if the module declares a default export without starting a server
+// itself, i.e. it is meant to be run with deno serve.
+func IsServeModule(ctx *gcp.Context, entrypoint string) (bool, error) {
+	content, err := ctx.ReadFile(filepath.Join(ctx.ApplicationRoot(), entrypoint))
+	if err != nil {
+		return false, err
+	}
+	return defaultExportRegexp.Match(content) && !bytes.Contains(content, []byte("Deno.serve(")), nil
+}
+
+// PermissionFlags returns the flags granting the permissions of the default permission set
+// declared in the configuration, e.g. {"net": true, "read": ["./data"]} is converted to
+// --allow-net --allow-read=./data.
+func PermissionFlags(cfg *Config) ([]string, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	set := cfg.Permissions[defaultPermissionSet]
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var flags []string
+	for _, name := range names {
+		if !permissionNames[name] {
+			return nil, gcp.UserErrorf("unknown Deno permission %q in the %q permission set", name, defaultPermissionSet)
+		}
+		var rule struct {
+			Allow json.RawMessage `json:"allow"`
+			Deny  json.RawMessage `json:"deny"`
+		}
+		value := set[name]
+		if err := json.Unmarshal(value, &rule); err != nil {
+			// The permission is not an object with allow and deny rules, the value is the allow rule.
+			rule.Allow = value
+		}
+		allow, err := permissionFlag("--allow-"+name, rule.Allow)
+		if err != nil {
+			return nil, err
+		}
+		deny, err := permissionFlag("--deny-"+name, rule.Deny)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, allow...)
+		flags = append(flags, deny...)
+	}
+	return flags, nil
+}
+
+// permissionFlag returns the flag for a permission rule, which is either a boolean or a list of
+// values the permission is restricted to.
+func permissionFlag(flag string, rule json.RawMessage) ([]string, error) {
+	if len(rule) == 0 {
+		return nil, nil
+	}
+	var granted bool
+	if err := json.Unmarshal(rule, &granted); err == nil {
+		if granted {
+			return []string{flag}, nil
+		}
+		return nil, nil
+	}
+	var values []string
+	if err := json.Unmarshal(rule, &values); err != nil {
+		return nil, gcp.UserErrorf("invalid value %s for Deno permission %s: want a boolean or a list of strings", rule, flag)
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return []string{flag + "=" + strings.Join(values, ",")}, nil
+}
+
+// InstallCommand returns the command installing the dependencies of the application, or nil if
+// there is nothing to install. Deno 2 installs the dependencies declared in the configuration
+// with deno install, earlier versions can only cache the module graph of the entrypoint.
+func InstallCommand(denoVersion, entrypoint string, hasConfig, lockExists bool) ([]string, error) {
+	v, err := semver.NewVersion(denoVersion)
+	if err != nil {
+		return nil, gcp.InternalErrorf("parsing Deno version %q: %w", denoVersion, err)
+	}
+	if v.Major() < 2 {
+		if entrypoint == "" {
+			return nil, nil
+		}
+		return []string{"deno", "cache", entrypoint}, nil
+	}
+	if !hasConfig && entrypoint == "" {
+		return nil, nil
+	}
+	cmd := []string{"deno", "install"}
+	if lockExists {
+		cmd = append(cmd, "--frozen")
+	}
+	if entrypoint != "" {
+		cmd = append(cmd, "--entrypoint", entrypoint)
+	}
+	return cmd, nil
+}
+
+// RunCommand returns the command running the entrypoint with the given permission flags. Modules
+// meant for deno serve listen on the port of the PORT environment variable, the command must be
+// run in a shell.
+func RunCommand(entrypoint string, permissions []string, serve bool) []string {
+	cmd := []string{"deno", "run"}
+	if serve {
+		cmd = []string{"deno", "serve", "--port", "$PORT"}
+	}
+	cmd = append(cmd, permissions...)
+	return append(cmd, entrypoint)
+}
+
+// CompileCommand returns the command compiling the entrypoint into a self-contained binary
+// granted the given permissions.
+func CompileCommand(entrypoint, output string, permissions []string) []string {
+	cmd := []string{"deno", "compile", fmt.Sprintf("--output=%s", output)}
+	cmd = append(cmd, permissions...)
+	return append(cmd, entrypoint)
+}
@@ -0,0 +1,409 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deno
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/buildpacks/internal/testserver"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuntimeVersion(t *testing.T) {
+	testCases := []struct {
+		name           string
+		denoVersion    string
+		runtimeVersion string
+		files          map[string]string
+		want           string
+		wantErr        bool
+	}{
+		{
+			name:        "GOOGLE_DENO_VERSION",
+			denoVersion: "2.1.4",
+			want:        "2.1.4",
+		},
+		{
+			name:           "GOOGLE_DENO_VERSION takes precedence",
+			denoVersion:    "2.1.4",
+			runtimeVersion: "1.46.3",
+			want:           "2.1.4",
+		},
+		{
+			name:           "GOOGLE_RUNTIME_VERSION",
+			runtimeVersion: "v1.46.3",
+			want:           "1.46.3",
+		},
+		{
+			name:  ".dvmrc",
+			files: map[string]string{".dvmrc": "2.0.6\n"},
+			want:  "2.0.6",
+		},
+		{
+			name: "latest",
+			want: "2.2.0",
+		},
+		{
+			name:        "version constraint",
+			denoVersion: "2.x",
+			wantErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testserver.New(
+				t,
+				testserver.WithJSON("v2.2.0\n"),
+				testserver.WithMockURL(&latestVersionURL),
+			)
+			t.Setenv(EnvDenoVersion, tc.denoVersion)
+			t.Setenv(env.RuntimeVersion, tc.runtimeVersion)
+			dir := writeFiles(t, tc.files)
+
+			got, err := RuntimeVersion(gcp.NewContext(gcp.WithApplicationRoot(dir)))
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("RuntimeVersion() got error: %v, want error? %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("RuntimeVersion() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReadConfig(t *testing.T) {
+	testCases := []struct {
+		name    string
+		files   map[string]string
+		want    *Config
+		wantErr bool
+	}{
+		{
+			name: "no config",
+		},
+		{
+			name: "deno.json",
+			files: map[string]string{
+				"deno.json": `{"tasks": {"start": "deno run -A main.ts"}, "exports": "./mod.ts"}`,
+			},
+			want: &Config{
+				Tasks:   map[string]any{"start": "deno run -A main.ts"},
+				Exports: "./mod.ts",
+			},
+		},
+		{
+			name: "deno.jsonc with comments and trailing commas",
+			files: map[string]string{
+				"deno.jsonc": `{
+					// The web server.
+					"tasks": {
+						"start": "deno run --allow-net=https://example.com main.ts", /* not a comment: // */
+					},
+					"exports": {".": "./main.ts", "./url": "https://example.com/,"},
+				}`,
+			},
+			want: &Config{
+				Tasks:   map[string]any{"start": "deno run --allow-net=https://example.com main.ts"},
+				Exports: map[string]any{".": "./main.ts", "./url": "https://example.com/,"},
+			},
+		},
+		{
+			name: "deno.json takes precedence",
+			files: map[string]string{
+				"deno.json":  `{"exports": "./a.ts"}`,
+				"deno.jsonc": `{"exports": "./b.ts"}`,
+			},
+			want: &Config{Exports: "./a.ts"},
+		},
+		{
+			name:    "invalid config",
+			files:   map[string]string{"deno.json": `{"tasks": `},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, tc.files)
+
+			got, err := ReadConfig(gcp.NewContext(gcp.WithApplicationRoot(dir)))
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("ReadConfig() got error: %v, want error? %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ReadConfig() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEntrypoint(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		cfg   *Config
+		want  string
+	}{
+		{
+			name:  "main.ts",
+			files: map[string]string{"main.ts": "", "server.ts": ""},
+			want:  "main.ts",
+		},
+		{
+			name:  "server.js",
+			files: map[string]string{"server.js": "", "mod.ts": ""},
+			want:  "server.js",
+		},
+		{
+			name:  "exports",
+			files: map[string]string{"main.ts": "", "src/app.ts": ""},
+			cfg:   &Config{Exports: "./src/app.ts"},
+			want:  "src/app.ts",
+		},
+		{
+			name:  "exports map",
+			files: map[string]string{"main.ts": "", "src/app.ts": ""},
+			cfg:   &Config{Exports: map[string]any{".": "./src/app.ts"}},
+			want:  "src/app.ts",
+		},
+		{
+			name:  "missing export",
+			files: map[string]string{"main.ts": ""},
+			cfg:   &Config{Exports: "./src/app.ts"},
+			want:  "main.ts",
+		},
+		{
+			name:  "no entrypoint",
+			files: map[string]string{"app.ts": ""},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, tc.files)
+
+			got, err := Entrypoint(gcp.NewContext(gcp.WithApplicationRoot(dir)), tc.cfg)
+			if err != nil {
+				t.Fatalf("Entrypoint() got error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Entrypoint() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsServeModule(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{
+			name:    "default export",
+			content: "export default {\n  fetch(req) { return new Response(\"hi\"); },\n} satisfies Deno.ServeDefaultExport;\n",
+			want:    true,
+		},
+		{
+			name:    "Deno.serve",
+			content: "Deno.serve((req) => new Response(\"hi\"));\n",
+		},
+		{
+			name:    "default export and Deno.serve",
+			content: "export default function handler(req) { return new Response(\"hi\"); }\nDeno.serve(handler);\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{"main.ts": tc.content})
+
+			got, err := IsServeModule(gcp.NewContext(gcp.WithApplicationRoot(dir)), "main.ts")
+			if err != nil {
+				t.Fatalf("IsServeModule() got error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("IsServeModule() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPermissionFlags(t *testing.T) {
+	testCases := []struct {
+		name    string
+		config  string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "no permissions",
+			config: `{}`,
+		},
+		{
+			name: "booleans and lists",
+			config: `{"permissions": {"default": {
+				"net": true,
+				"read": ["./data", "./static"],
+				"write": false,
+				"env": []
+			}}}`,
+			want: []string{"--allow-net", "--allow-read=./data,./static"},
+		},
+		{
+			name: "allow and deny rules",
+			config: `{"permissions": {"default": {
+				"net": {"allow": true, "deny": ["169.254.169.254"]}
+			}}}`,
+			want: []string{"--allow-net", "--deny-net=169.254.169.254"},
+		},
+		{
+			name:   "other permission sets are ignored",
+			config: `{"permissions": {"test": {"all": true}}}`,
+		},
+		{
+			name:    "unknown permission",
+			config:  `{"permissions": {"default": {"hrtime": true}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			config:  `{"permissions": {"default": {"net": "example.com"}}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{"deno.json": tc.config})
+			cfg, err := ReadConfig(gcp.NewContext(gcp.WithApplicationRoot(dir)))
+			if err != nil {
+				t.Fatalf("ReadConfig() got error: %v", err)
+			}
+
+			got, err := PermissionFlags(cfg)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("PermissionFlags() got error: %v, want error? %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("PermissionFlags() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestInstallCommand(t *testing.T) {
+	testCases := []struct {
+		name       string
+		version    string
+		entrypoint string
+		hasConfig  bool
+		lockExists bool
+		want       []string
+	}{
+		{
+			name:       "deno 2 with lock file",
+			version:    "2.1.4",
+			entrypoint: "main.ts",
+			hasConfig:  true,
+			lockExists: true,
+			want:       []string{"deno", "install", "--frozen", "--entrypoint", "main.ts"},
+		},
+		{
+			name:      "deno 2 without entrypoint",
+			version:   "2.1.4",
+			hasConfig: true,
+			want:      []string{"deno", "install"},
+		},
+		{
+			name:    "deno 2 without config nor entrypoint",
+			version: "2.1.4",
+		},
+		{
+			name:       "deno 1",
+			version:    "1.46.3",
+			entrypoint: "main.ts",
+			hasConfig:  true,
+			lockExists: true,
+			want:       []string{"deno", "cache", "main.ts"},
+		},
+		{
+			name:      "deno 1 without entrypoint",
+			version:   "1.46.3",
+			hasConfig: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := InstallCommand(tc.version, tc.entrypoint, tc.hasConfig, tc.lockExists)
+			if err != nil {
+				t.Fatalf("InstallCommand() got error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("InstallCommand() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	testCases := []struct {
+		name        string
+		permissions []string
+		serve       bool
+		want        []string
+	}{
+		{
+			name:        "run",
+			permissions: []string{"--allow-net"},
+			want:        []string{"deno", "run", "--allow-net", "main.ts"},
+		},
+		{
+			name:        "serve",
+			permissions: []string{"--allow-read=./static"},
+			serve:       true,
+			want:        []string{"deno", "serve", "--port", "$PORT", "--allow-read=./static", "main.ts"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, RunCommand("main.ts", tc.permissions, tc.serve)); diff != "" {
+				t.Errorf("RunCommand() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("creating directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+	return dir
+}
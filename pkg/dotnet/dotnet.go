@@ -35,6 +35,9 @@ const (
 	googleMin22   = "google.min.22"
 	// EnvRuntimeVersion is the environment variable key for storing the target dotnet runtime version.
 	EnvRuntimeVersion = "GOOGLE_ASP_NET_CORE_VERSION"
+	// EnvBuildShell is the environment variable key to run `dotnet publish` and GOOGLE_BUILD_ARGS with
+	// bash -c, to support shell operators and expansions in the build arguments.
+	EnvBuildShell = "GOOGLE_DOTNET_BUILD_SHELL"
 	// PublishLayerName is the name of the directory containing the publish layer
 	PublishLayerName = "publish"
 	// PublishOutputDirName is passed as the output directory for `dotnet publish`.
@@ -102,6 +102,19 @@ const (
 	// GoLDFlags is an env var used to pass through linker flags to the Go linker.
 	// Example: `-s -w` is sometimes used to strip and reduce binary size.
 	GoLDFlags = "GOOGLE_GOLDFLAGS"
+	// GoBuildAll is an env var used to build every main package of a Go app into its own binary,
+	// each registered as a process named after the binary.
+	// Example: `true`, `True`, `1` will build every main package.
+	GoBuildAll = "GOOGLE_GO_BUILD_ALL"
+	// GoWebProcess is an env var used to select the binary run as the web process when every main
+	// package is built with GoBuildAll.
+	// Example: `api` runs the binary of the ./cmd/api package as the web process.
+	GoWebProcess = "GOOGLE_GO_WEB_PROCESS"
+
+	// SizeHeap is an env var used to size the heap of the Java and .NET runtimes from the memory
+	// limit of the container when the application starts. The Node.js heap is sized by default.
+	// Example: `true`, `True`, `1` will size the heap.
+	SizeHeap = "GOOGLE_SIZE_HEAP"
 
 	// UseNativeImage is used to enable the GraalVM Java buildpack for native image compilation.
 	// Example: `true`, `True`, `1` will enable development mode.
@@ -155,6 +168,10 @@ const (
 	// ServerlessRuntimesTarballs is an experiment flag to fetch tarballs from serverless-runtimes AR
 	ServerlessRuntimesTarballs = "GOOGLE_USE_SERVERLESS_RUNTIMES_TARBALLS"
 
+	// RuntimeSources is the path to a JSON file that overrides entries of the runtime source
+	// registry embedded in the buildpacks (e.g. to install runtimes from a mirror).
+	RuntimeSources = "GOOGLE_RUNTIME_SOURCES"
+
 	// ColdStartImprovementsBuildStudy is an experiment flag to enable cold start improvements build study.
 	ColdStartImprovementsBuildStudy = "EXPERIMENTAL_RUNTIMES_COLD_START_BUILD"
 
@@ -17,8 +17,11 @@ package fetch
 
 import (
 	"archive/tar"
+	"archive/zip"
+	"bytes"
 	"compress/gzip"
 	"encoding/json"
+	"hash"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -44,6 +47,33 @@ func Tarball(url, dir string, stripComponents int) error {
 	return untar(dir, response.Body, stripComponents)
 }
 
+// VerifiedTarball downloads a tarball from a URL, verifies that its digest computed with h is
+// wantDigest and extracts it into the provided directory.
+func VerifiedTarball(url, dir string, stripComponents int, h hash.Hash, wantDigest []byte) error {
+	var buf bytes.Buffer
+	if err := GetURL(url, io.MultiWriter(&buf, h)); err != nil {
+		return err
+	}
+	if got := h.Sum(nil); !bytes.Equal(got, wantDigest) {
+		return gcp.UserErrorf("verifying integrity of %s: got digest %x, want %x", url, got, wantDigest)
+	}
+	return untar(dir, &buf, stripComponents)
+}
+
+// Zip downloads a zip archive from a URL and extracts it into the provided directory.
+func Zip(url, dir string, stripComponents int) error {
+	f, err := os.CreateTemp("", "fetch-*.zip")
+	if err != nil {
+		return gcp.InternalErrorf("creating temp file: %v", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if err := GetURL(url, f); err != nil {
+		return err
+	}
+	return unzip(dir, f.Name(), stripComponents)
+}
+
 // ARVersions downloads list of versions from artifact registry.
 var ARVersions = func(url, fallbackURL string, ctx *gcp.Context) ([]string, error) {
 	versions, err := crane.ListTags(url)
@@ -214,6 +244,63 @@ func untar(dir string, r io.Reader, stripComponents int) error {
 	}
 }
 
+// unzip extracts a zip archive and writes it to the given directory.
+func unzip(dir, path string, stripComponents int) error {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return gcp.InternalErrorf("opening zip archive: %v", err)
+	}
+	defer zr.Close()
+
+	for _, zf := range zr.File {
+		typ := byte(tar.TypeReg)
+		if zf.FileInfo().IsDir() {
+			typ = tar.TypeDir
+		}
+		target, err := tarDestination(zf.Name, dir, typ, stripComponents)
+		if err != nil {
+			return err
+		}
+		if typ == tar.TypeDir {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return gcp.InternalErrorf("creating directory %q: %v", target, err)
+			}
+			continue
+		}
+		if !zf.Mode().IsRegular() {
+			return gcp.InternalErrorf("invalid zip entry %q", zf.Name)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return gcp.InternalErrorf("creating directory %q: %v", target, err)
+		}
+		if err := extractZipFile(zf, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractZipFile writes a zip archive entry to target.
+func extractZipFile(zf *zip.File, target string) error {
+	r, err := zf.Open()
+	if err != nil {
+		return gcp.InternalErrorf("opening zip entry %q: %v", zf.Name, err)
+	}
+	defer r.Close()
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR|os.O_TRUNC, zf.Mode().Perm())
+	if err != nil {
+		return gcp.InternalErrorf("opening file %q: %v", target, err)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return gcp.InternalErrorf("copying file %q: %v", target, err)
+	}
+	if err := f.Close(); err != nil {
+		return gcp.InternalErrorf("closing file %q: %v", target, err)
+	}
+	return nil
+}
+
 // tarDestination returns the filepath that a tar entry should be written to when extracted.
 func tarDestination(tarPath, rootDir string, tarType byte, stripComponents int) (string, error) {
 	rootDir = filepath.Clean(rootDir)
@@ -16,6 +16,7 @@ package fetch
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"io"
 	"net/http"
 	"os"
@@ -89,6 +90,116 @@ func TestTarball(t *testing.T) {
 	}
 }
 
+func TestVerifiedTarball(t *testing.T) {
+	content, err := os.ReadFile(testdata.MustGetPath("testdata/test.tar.gz"))
+	if err != nil {
+		t.Fatalf("reading test.tar.gz: %v", err)
+	}
+	digest := sha256.Sum256(content)
+	testCases := []struct {
+		name       string
+		wantDigest []byte
+		wantFile   string
+		wantError  bool
+	}{
+		{
+			name:       "matching digest",
+			wantDigest: digest[:],
+			wantFile:   "foo.txt",
+		},
+		{
+			name:       "mismatching digest",
+			wantDigest: make([]byte, sha256.Size),
+			wantError:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := testserver.New(t, testserver.WithFile(testdata.MustGetPath("testdata/test.tar.gz")))
+
+			dir := t.TempDir()
+			err := VerifiedTarball(server.URL, dir, 1, sha256.New(), tc.wantDigest)
+			if tc.wantError == (err == nil) {
+				t.Fatalf("VerifiedTarball(%q, %q, 1) got error: %v, want error? %v", server.URL, dir, err, tc.wantError)
+			}
+
+			if tc.wantFile != "" {
+				fp := filepath.Join(dir, tc.wantFile)
+				if _, err := os.Stat(fp); err != nil {
+					t.Errorf("Failed to extract. Missing file: %s (%v)", fp, err)
+				}
+			} else if entries, _ := os.ReadDir(dir); len(entries) > 0 {
+				t.Errorf("VerifiedTarball() extracted %d entries, want none", len(entries))
+			}
+		})
+	}
+}
+
+func TestZip(t *testing.T) {
+	testCases := []struct {
+		name            string
+		httpStatus      int
+		stripComponents int
+		responseFile    string
+		wantFile        string
+		wantExecutable  string
+		wantError       bool
+	}{
+		{
+			name:           "simple unzip",
+			responseFile:   "testdata/test.zip",
+			wantFile:       "lib/foo.txt",
+			wantExecutable: "bin/run",
+		},
+		{
+			name:            "strip components",
+			responseFile:    "testdata/test.zip",
+			stripComponents: 1,
+			wantFile:        "foo.txt",
+		},
+		{
+			name:       "not found",
+			httpStatus: http.StatusNotFound,
+			wantError:  true,
+		},
+		{
+			name:         "corrupt zip file",
+			responseFile: "testdata/test.json",
+			httpStatus:   http.StatusOK,
+			wantError:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := testserver.New(
+				t,
+				testserver.WithStatus(tc.httpStatus),
+				testserver.WithFile(testdata.MustGetPath(tc.responseFile)))
+
+			dir := t.TempDir()
+			err := Zip(server.URL, dir, tc.stripComponents)
+			if tc.wantError == (err == nil) {
+				t.Fatalf("Zip(%q, %q, %v) got error: %v, want error? %v", server.URL, dir, tc.stripComponents, err, tc.wantError)
+			}
+
+			if tc.wantFile != "" {
+				fp := filepath.Join(dir, tc.wantFile)
+				if _, err := os.Stat(fp); err != nil {
+					t.Errorf("Failed to extract. Missing file: %s (%v)", fp, err)
+				}
+			}
+			if tc.wantExecutable != "" {
+				fp := filepath.Join(dir, tc.wantExecutable)
+				if info, err := os.Stat(fp); err != nil || info.Mode().Perm()&0100 == 0 {
+					t.Errorf("Failed to extract executable %s: %v, %v", fp, info, err)
+				}
+			}
+		})
+	}
+}
+
 func TestJSON(t *testing.T) {
 	testCases := []struct {
 		name       string
@@ -43,6 +43,20 @@ func TestWriteBuildDirectoryContext(t *testing.T) {
 			wantRelativeProjectDirectory: "apps/my-app",
 			files:                        []string{"monorepo/nx.json", "monorepo/apps/my-app/project.json"},
 		},
+		{
+			name:                         "turborepo_is_not_a_monorepo_build",
+			appDirectoryPath:             "apps/web",
+			wantBuildDirectory:           "apps/web",
+			wantRelativeProjectDirectory: "",
+			files:                        []string{"apps/web/package.json", "turbo.json", "package.json"},
+		},
+		{
+			name:                         "pnpm_workspace_is_not_a_monorepo_build",
+			appDirectoryPath:             "apps/web",
+			wantBuildDirectory:           "apps/web",
+			wantRelativeProjectDirectory: "",
+			files:                        []string{"apps/web/package.json", "pnpm-workspace.yaml", "package.json"},
+		},
 		{
 			name:                         "invalid_app_directory_path",
 			appDirectoryPath:             "path/to/nowhere",
@@ -0,0 +1,63 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcpbuildpack
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/buildpacks/libcnb/v2"
+)
+
+// Bindings returns the service bindings available to the build (see
+// https://github.com/buildpacks/spec/blob/main/extensions/bindings.md). Bindings are read from the
+// directory in the SERVICE_BINDING_ROOT env var, or from the bindings directory of the platform.
+func (ctx *Context) Bindings() (libcnb.Bindings, error) {
+	if ctx.buildContext.Platform.Bindings != nil {
+		return ctx.buildContext.Platform.Bindings, nil
+	}
+	platformDir := ctx.buildContext.Platform.Path
+	if platformDir == "" {
+		platformDir = filepath.Dir(libcnb.DefaultPlatformBindingsLocation)
+	}
+	bindings, err := libcnb.NewBindings(platformDir)
+	if err != nil {
+		return nil, UserErrorf("reading service bindings: %w", err)
+	}
+	return bindings, nil
+}
+
+// BindingsOfType returns the service bindings of the given type and, if provider is not empty,
+// of the given provider. Types and providers are compared case-insensitively.
+func (ctx *Context) BindingsOfType(bindingType, provider string) (libcnb.Bindings, error) {
+	bindings, err := ctx.Bindings()
+	if err != nil {
+		return nil, err
+	}
+	var result libcnb.Bindings
+	for _, b := range bindings {
+		if !strings.EqualFold(b.Type, bindingType) {
+			continue
+		}
+		if provider != "" && !strings.EqualFold(b.Provider, provider) {
+			continue
+		}
+		result = append(result, b)
+	}
+	if len(result) > 0 {
+		ctx.Debugf("Found %d service bindings of type %q.", len(result), bindingType)
+	}
+	return result, nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcpbuildpack
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/buildpacks/libcnb/v2"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBindingsOfType(t *testing.T) {
+	root := t.TempDir()
+	for path, content := range map[string]string{
+		"devpi/type":         "package-repository",
+		"devpi/provider":     "devpi",
+		"devpi/url":          "https://devpi.example.com/root/prod/+simple/",
+		"jfrog/type":         "Package-Repository\n",
+		"jfrog/provider":     "jfrog",
+		"jfrog/url":          "https://example.jfrog.io/artifactory/api/pypi/pypi/simple",
+		"my-npmrc/type":      "npmrc",
+		"my-npmrc/.npmrc":    "always-auth=true",
+		".hidden/type":       "package-repository",
+		"no-type/irrelevant": "",
+	} {
+		p := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("creating directory for %s: %v", path, err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatalf("writing %s: %v", path, err)
+		}
+	}
+	t.Setenv(libcnb.EnvServiceBindings, root)
+
+	testCases := []struct {
+		name        string
+		bindingType string
+		provider    string
+		want        []string
+	}{
+		{
+			name:        "by type",
+			bindingType: "package-repository",
+			want:        []string{"devpi", "jfrog"},
+		},
+		{
+			name:        "by type and provider",
+			bindingType: "package-repository",
+			provider:    "JFrog",
+			want:        []string{"jfrog"},
+		},
+		{
+			name:        "other type",
+			bindingType: "npmrc",
+			want:        []string{"my-npmrc"},
+		},
+		{
+			name:        "no bindings",
+			bindingType: "maven",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := NewContext()
+
+			bindings, err := ctx.BindingsOfType(tc.bindingType, tc.provider)
+			if err != nil {
+				t.Fatalf("BindingsOfType(%q, %q) got error: %v", tc.bindingType, tc.provider, err)
+			}
+			var got []string
+			for _, b := range bindings {
+				got = append(got, b.Name)
+			}
+			sort.Strings(got)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("BindingsOfType(%q, %q) mismatch (-want +got):\n%s", tc.bindingType, tc.provider, diff)
+			}
+		})
+	}
+}
+
+func TestBindingsFromBuildContext(t *testing.T) {
+	want := libcnb.Bindings{{Name: "maven", Type: "maven", Secret: map[string]string{"settings.xml": "<settings/>"}}}
+	ctx := NewContext(WithBuildContext(libcnb.BuildContext{Platform: libcnb.Platform{Bindings: want}}))
+
+	got, err := ctx.Bindings()
+	if err != nil {
+		t.Fatalf("Bindings() got error: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Bindings() mismatch (-want +got):\n%s", diff)
+	}
+}
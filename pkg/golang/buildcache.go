@@ -0,0 +1,80 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/cache"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/runtime"
+	"github.com/buildpacks/libcnb/v2"
+)
+
+const (
+	// buildCacheLayerName is the name of the layer where GOCACHE is stored.
+	buildCacheLayerName = "gocache"
+	// buildCacheKey is the key of the layer metadata identifying the toolchain and flags the cache
+	// was built with.
+	buildCacheKey = "go-build-cache-key"
+	// buildCacheMaxAge is the age after which unused build cache entries are removed. Go refreshes
+	// the modification time of the entries it reuses.
+	buildCacheMaxAge = 7 * 24 * time.Hour
+	// buildCacheMaxBytes is the size above which the least recently used build cache entries are
+	// removed, down to buildCacheTargetBytes.
+	buildCacheMaxBytes    = 4 << 30
+	buildCacheTargetBytes = 3 << 30
+)
+
+// NewBuildCacheLayer returns a layer for GOCACHE kept across builds. The cache is cleared when the
+// Go version, the stack, the target architecture or the given build flags change, as the entries
+// built with the previous ones would not be reused. The layer is also launched in dev mode for
+// faster rebuilds.
+func NewBuildCacheLayer(ctx *gcp.Context, buildFlags ...string) (*libcnb.Layer, error) {
+	l, err := ctx.Layer(buildCacheLayerName, gcp.BuildLayer, gcp.CacheLayer, gcp.LaunchLayerIfDevMode)
+	if err != nil {
+		return nil, fmt.Errorf("creating %v layer: %w", buildCacheLayerName, err)
+	}
+	goVersion, err := GoVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{goVersion, ctx.StackID(), runtime.TargetArch(), os.Getenv("GOFLAGS"), os.Getenv("CGO_ENABLED")}
+	hash, cached, err := cache.HashAndCheck(ctx, l, buildCacheKey, cache.WithStrings(append(keys, buildFlags...)...))
+	if err != nil {
+		return nil, err
+	}
+	if cached {
+		return l, nil
+	}
+	if err := ctx.ClearLayer(l); err != nil {
+		return nil, fmt.Errorf("clearing layer %q: %w", l.Name, err)
+	}
+	cache.Add(ctx, l, buildCacheKey, hash)
+	return l, nil
+}
+
+// TrimBuildCache removes the build cache entries unused for a week, then the least recently used
+// entries if the cache exceeds its maximum size.
+func TrimBuildCache(ctx *gcp.Context, l *libcnb.Layer) error {
+	res, err := cache.Trim(l.Path, buildCacheMaxAge, buildCacheMaxBytes, buildCacheTargetBytes)
+	if err != nil {
+		return fmt.Errorf("trimming Go build cache: %w", err)
+	}
+	ctx.Debugf("Go build cache size %d MiB, trimmed %d files of %d MiB.", res.Bytes>>20, res.RemovedFiles, res.RemovedBytes>>20)
+	return nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb/v2"
+)
+
+func TestNewBuildCacheLayer(t *testing.T) {
+	testCases := []struct {
+		name       string
+		goVersion  string
+		targetArch string
+		flags      []string
+		wantKept   bool
+	}{
+		{
+			name:      "same toolchain and flags",
+			goVersion: "go version go1.23.2 linux/amd64",
+			flags:     []string{"-ldflags", "-s -w"},
+			wantKept:  true,
+		},
+		{
+			name:      "Go version changed",
+			goVersion: "go version go1.24.0 linux/amd64",
+			flags:     []string{"-ldflags", "-s -w"},
+		},
+		{
+			name:      "build flags changed",
+			goVersion: "go version go1.23.2 linux/amd64",
+			flags:     []string{"-gcflags", "-N -l"},
+		},
+		{
+			name:       "target architecture changed",
+			goVersion:  "go version go1.23.2 linux/amd64",
+			targetArch: "arm64",
+			flags:      []string{"-ldflags", "-s -w"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			layersDir := t.TempDir()
+			newContext := func() *gcp.Context {
+				return gcp.NewContext(gcp.WithBuildContext(libcnb.BuildContext{Layers: libcnb.Layers{Path: layersDir}}))
+			}
+
+			// Populate the cache in a previous build.
+			t.Setenv(libcnb.EnvTargetArch, "amd64")
+			mockReadGoVersion(t, "go version go1.23.2 linux/amd64")
+			l, err := NewBuildCacheLayer(newContext(), "-ldflags", "-s -w")
+			if err != nil {
+				t.Fatalf("NewBuildCacheLayer() got error: %v", err)
+			}
+			entry := filepath.Join(l.Path, "00", "0123-a")
+			if err := os.MkdirAll(filepath.Dir(entry), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(entry, []byte("entry"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			metadata := fmt.Sprintf("[metadata]\n  %s = %q\n", buildCacheKey, l.Metadata[buildCacheKey])
+			if err := os.WriteFile(filepath.Join(layersDir, buildCacheLayerName+".toml"), []byte(metadata), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.targetArch != "" {
+				t.Setenv(libcnb.EnvTargetArch, tc.targetArch)
+			}
+			mockReadGoVersion(t, tc.goVersion)
+			l, err = NewBuildCacheLayer(newContext(), tc.flags...)
+			if err != nil {
+				t.Fatalf("NewBuildCacheLayer() got error: %v", err)
+			}
+			if !l.Cache || !l.Build {
+				t.Errorf("NewBuildCacheLayer() cache=%t build=%t, want both true", l.Cache, l.Build)
+			}
+			_, err = os.Stat(entry)
+			if kept := err == nil; kept != tc.wantKept {
+				t.Errorf("cache entry kept = %t, want %t", kept, tc.wantKept)
+			}
+		})
+	}
+}
@@ -228,7 +228,8 @@ var cleanModCache = func(ctx *gcp.Context) error {
 	return err
 }
 
-// readGoMod reads the go.mod file if present. If not present, returns an empty string.
+// readGoMod reads the go.mod file if present, or the go.work file of a workspace without a module
+// at its root, which has the same go directive. If neither is present, returns an empty string.
 // It can be overridden for testing.
 var readGoMod = func(ctx *gcp.Context) (string, error) {
 	goModPath := goModPath(ctx)
@@ -237,7 +238,11 @@ var readGoMod = func(ctx *gcp.Context) (string, error) {
 		return "", err
 	}
 	if !goModExists {
-		return "", nil
+		ws, err := ReadWorkspace(ctx)
+		if err != nil || ws == nil {
+			return "", err
+		}
+		goModPath = filepath.Join(ctx.ApplicationRoot(), GoWork)
 	}
 	bytes, err := ctx.ReadFile(goModPath)
 	if err != nil {
@@ -269,7 +274,18 @@ func NewGoWorkspaceLayer(ctx *gcp.Context) (*libcnb.Layer, error) {
 		return l, nil
 	}
 
-	hash, cached, err := cache.HashAndCheck(ctx, l, goModCacheKey, cache.WithFiles(goModPath(ctx)))
+	files := []string{goModPath(ctx)}
+	ws, err := ReadWorkspace(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ws != nil {
+		// The modules downloaded for a workspace depend on all its modules.
+		if files, err = ws.cacheFiles(ctx); err != nil {
+			return nil, err
+		}
+	}
+	hash, cached, err := cache.HashAndCheck(ctx, l, goModCacheKey, cache.WithFiles(files...))
 	if err != nil {
 		if os.IsNotExist(err) {
 			// when go.mod doesn't exist, clear any previously cached bits and return an empty layer
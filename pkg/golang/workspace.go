@@ -0,0 +1,174 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const (
+	// GoWork is the name of the file declaring a Go workspace.
+	GoWork = "go.work"
+	// goWorkSum is the name of the checksum file of the modules of a Go workspace which are not in
+	// the go.sum files of the workspace modules.
+	goWorkSum = "go.work.sum"
+)
+
+// Workspace is a Go workspace declared by a go.work file at the application root.
+type Workspace struct {
+	// Modules are the directories of the modules in the use directives, relative to the application
+	// root.
+	Modules []string
+}
+
+// ReadWorkspace returns the Go workspace declared at the application root, or nil if there is no
+// go.work file or workspace mode is disabled with GOWORK=off.
+func ReadWorkspace(ctx *gcp.Context) (*Workspace, error) {
+	if os.Getenv("GOWORK") == "off" {
+		return nil, nil
+	}
+	path := filepath.Join(ctx.ApplicationRoot(), GoWork)
+	exists, err := ctx.FileExists(path)
+	if err != nil || !exists {
+		return nil, err
+	}
+	content, err := ctx.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	modules, err := parseGoWorkUse(string(content))
+	if err != nil {
+		return nil, err
+	}
+	return &Workspace{Modules: modules}, nil
+}
+
+// parseGoWorkUse returns the cleaned directories of the use directives of a go.work file, both
+// single line and in blocks.
+func parseGoWorkUse(content string) ([]string, error) {
+	var modules []string
+	inBlock := false
+	for i, line := range strings.Split(content, "\n") {
+		if c := strings.Index(line, "//"); c >= 0 {
+			line = line[:c]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		var dir string
+		switch {
+		case inBlock && fields[0] == ")":
+			inBlock = false
+			continue
+		case inBlock:
+			dir = fields[0]
+		case fields[0] != "use":
+			continue
+		case len(fields) == 2 && fields[1] == "(":
+			inBlock = true
+			continue
+		case len(fields) == 2:
+			dir = fields[1]
+		default:
+			return nil, gcp.UserErrorf("parsing %s line %d: invalid use directive %q", GoWork, i+1, strings.TrimSpace(line))
+		}
+		if strings.HasPrefix(dir, `"`) || strings.HasPrefix(dir, "`") {
+			unquoted, err := strconv.Unquote(dir)
+			if err != nil {
+				return nil, gcp.UserErrorf("parsing %s line %d: invalid path %s: %v", GoWork, i+1, dir, err)
+			}
+			dir = unquoted
+		}
+		modules = append(modules, filepath.Clean(dir))
+	}
+	return modules, nil
+}
+
+// Patterns returns the package patterns matching all the packages of the workspace modules.
+func (w *Workspace) Patterns() []string {
+	var patterns []string
+	for _, m := range w.Modules {
+		if filepath.IsAbs(m) {
+			patterns = append(patterns, filepath.Join(m, "..."))
+		} else {
+			patterns = append(patterns, "./"+filepath.ToSlash(filepath.Join(m, "...")))
+		}
+	}
+	return patterns
+}
+
+// cacheFiles returns the files the downloaded modules of the workspace depend on.
+func (w *Workspace) cacheFiles(ctx *gcp.Context) ([]string, error) {
+	files := []string{filepath.Join(ctx.ApplicationRoot(), GoWork)}
+	candidates := []string{filepath.Join(ctx.ApplicationRoot(), goWorkSum)}
+	for _, m := range w.Modules {
+		if !filepath.IsAbs(m) {
+			m = filepath.Join(ctx.ApplicationRoot(), m)
+		}
+		candidates = append(candidates, filepath.Join(m, "go.mod"), filepath.Join(m, "go.sum"))
+	}
+	for _, f := range candidates {
+		exists, err := ctx.FileExists(f)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// CheckWorkspaceModFlag returns a user error if the -mod flag in GOFLAGS cannot be used in
+// workspace mode. Workspace mode only accepts -mod=readonly and -mod=vendor, the latter with
+// Go 1.22+ and a vendor directory created by `go work vendor` at the workspace root. It returns
+// true if the dependencies are vendored.
+func CheckWorkspaceModFlag(ctx *gcp.Context) (bool, error) {
+	var mod string
+	for _, f := range strings.Fields(os.Getenv("GOFLAGS")) {
+		if v, ok := strings.CutPrefix(f, "-mod="); ok {
+			mod = v
+		}
+	}
+	switch mod {
+	case "", "readonly":
+		return false, nil
+	case "vendor":
+	default:
+		return false, gcp.UserErrorf("GOFLAGS=-mod=%s cannot be used with %s, workspace mode only supports -mod=readonly and -mod=vendor. Set GOWORK=off to build a single module.", mod, GoWork)
+	}
+
+	supported, err := VersionMatches(ctx, ">=1.22.0")
+	if err != nil {
+		return false, err
+	}
+	if !supported {
+		return false, gcp.UserErrorf("GOFLAGS=-mod=vendor requires Go 1.22+ with %s. Upgrade the go directive of %s or set GOWORK=off to build a single module with its vendor directory.", GoWork, GoWork)
+	}
+	vendored, err := ctx.FileExists(ctx.ApplicationRoot(), "vendor", "modules.txt")
+	if err != nil {
+		return false, err
+	}
+	if !vendored {
+		return false, gcp.UserErrorf("GOFLAGS=-mod=vendor requires a vendor directory created by `go work vendor` at the root of the workspace, the vendor directories of the modules are ignored in workspace mode. Set GOWORK=off to build a single module with its vendor directory.")
+	}
+	return true, nil
+}
@@ -0,0 +1,175 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golang
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadWorkspace(t *testing.T) {
+	testCases := []struct {
+		name      string
+		goWork    string
+		goWorkEnv string
+		want      *Workspace
+		wantErr   bool
+	}{
+		{
+			name: "no go.work",
+		},
+		{
+			name: "use block",
+			goWork: `go 1.23
+
+// The services and their shared libraries.
+use (
+	./services/api
+	./libs/common // Shared code.
+	"./libs/quoted"
+)
+
+replace example.com/old => ./libs/common
+`,
+			want: &Workspace{Modules: []string{"services/api", "libs/common", "libs/quoted"}},
+		},
+		{
+			name: "use lines",
+			goWork: `go 1.22
+use .
+use ./tools/
+`,
+			want: &Workspace{Modules: []string{".", "tools"}},
+		},
+		{
+			name:      "workspace mode disabled",
+			goWork:    "go 1.23\nuse ./api\n",
+			goWorkEnv: "off",
+		},
+		{
+			name:    "invalid use directive",
+			goWork:  "go 1.23\nuse ./api ./web\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.goWorkEnv != "" {
+				t.Setenv("GOWORK", tc.goWorkEnv)
+			}
+			dir := t.TempDir()
+			if tc.goWork != "" {
+				if err := os.WriteFile(filepath.Join(dir, GoWork), []byte(tc.goWork), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := ReadWorkspace(gcp.NewContext(gcp.WithApplicationRoot(dir)))
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("ReadWorkspace() got error: %v, want error? %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ReadWorkspace() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWorkspacePatterns(t *testing.T) {
+	ws := &Workspace{Modules: []string{".", "services/api", "/src/shared"}}
+	want := []string{"./...", "./services/api/...", "/src/shared/..."}
+
+	if diff := cmp.Diff(want, ws.Patterns()); diff != "" {
+		t.Errorf("Patterns() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCheckWorkspaceModFlag(t *testing.T) {
+	testCases := []struct {
+		name         string
+		goFlags      string
+		goVersion    string
+		goMod        string
+		vendor       bool
+		wantVendored bool
+		wantErr      bool
+	}{
+		{
+			name: "no flag",
+		},
+		{
+			name:    "readonly",
+			goFlags: "-v -mod=readonly",
+		},
+		{
+			name:    "mod",
+			goFlags: "-mod=mod",
+			wantErr: true,
+		},
+		{
+			name:         "vendor",
+			goFlags:      "-mod=vendor",
+			goVersion:    "go version go1.23.0 linux/amd64",
+			goMod:        "go 1.23\n",
+			vendor:       true,
+			wantVendored: true,
+		},
+		{
+			name:      "vendor without vendor directory",
+			goFlags:   "-mod=vendor",
+			goVersion: "go version go1.23.0 linux/amd64",
+			goMod:     "go 1.23\n",
+			wantErr:   true,
+		},
+		{
+			name:      "vendor before Go 1.22",
+			goFlags:   "-mod=vendor",
+			goVersion: "go version go1.21.5 linux/amd64",
+			goMod:     "go 1.21\n",
+			vendor:    true,
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("GOFLAGS", tc.goFlags)
+			mockReadGoVersion(t, tc.goVersion)
+			mockReadGoMod(t, tc.goMod)
+			dir := t.TempDir()
+			if tc.vendor {
+				if err := os.MkdirAll(filepath.Join(dir, "vendor"), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, "vendor", "modules.txt"), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := CheckWorkspaceModFlag(gcp.NewContext(gcp.WithApplicationRoot(dir)))
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("CheckWorkspaceModFlag() got error: %v, want error? %v", err, tc.wantErr)
+			}
+			if got != tc.wantVendored {
+				t.Errorf("CheckWorkspaceModFlag() = %v, want %v", got, tc.wantVendored)
+			}
+		})
+	}
+}
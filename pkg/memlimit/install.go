@@ -0,0 +1,49 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memlimit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const layerName = "memlimit"
+
+// Install copies the memlimit binary, packaged in the exec directory of the buildpack, into the
+// exec.d directory of a launch layer as memlimit-RUNTIME. When the container starts, it sizes the
+// memory settings of the runtime from the memory limit of the container.
+func Install(ctx *gcp.Context, runtime string) error {
+	l, err := ctx.Layer(layerName, gcp.LaunchLayer)
+	if err != nil {
+		return fmt.Errorf("creating %v layer: %w", layerName, err)
+	}
+	ctx.Logf("Installing the %s exec.d binary.", BinaryName)
+	binPath := filepath.Join(ctx.BuildpackRoot(), "exec", BinaryName)
+	destPath := filepath.Join(l.Exec.Path, BinaryName+"-"+runtime)
+	data, err := os.ReadFile(binPath)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", binPath, err)
+	}
+	if err := ctx.MkdirAll(l.Exec.Path, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(destPath, data, 0755); err != nil {
+		return fmt.Errorf("writing %s: %w", destPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memlimit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb/v2"
+)
+
+func TestInstall(t *testing.T) {
+	bpRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(bpRoot, "exec"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(bpRoot, "exec", BinaryName), []byte("binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	layersDir := t.TempDir()
+	ctx := gcp.NewContext(gcp.WithBuildpackRoot(bpRoot), gcp.WithBuildContext(libcnb.BuildContext{Layers: libcnb.Layers{Path: layersDir}}))
+
+	if err := Install(ctx, Java); err != nil {
+		t.Fatalf("Install() got error: %v", err)
+	}
+	got, err := os.Stat(filepath.Join(layersDir, layerName, "exec.d", "memlimit-java"))
+	if err != nil {
+		t.Fatalf("Install() did not write the exec.d binary: %v", err)
+	}
+	if got.Mode().Perm()&0111 == 0 {
+		t.Errorf("Install() wrote the exec.d binary with mode %v, want executable", got.Mode())
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package memlimit sizes the memory settings of language runtimes from the memory limit of the
+// container. It is used by an exec.d binary which sets the environment of the application when
+// the container starts.
+package memlimit
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+)
+
+const (
+	// Nodejs sizes the V8 old space and the number of web workers.
+	Nodejs = "nodejs"
+	// Java sizes the JVM heap.
+	Java = "java"
+	// Dotnet sizes the .NET garbage collector heap.
+	Dotnet = "dotnet"
+
+	// BinaryName is the name of the exec.d binary, followed by a dash and the runtime, for example
+	// memlimit-nodejs.
+	BinaryName = "memlimit"
+
+	// Source values of a Limit.
+	SourceCgroupV2 = "cgroup v2"
+	SourceCgroupV1 = "cgroup v1"
+	SourceHint     = env.ContainerMemoryHintMB
+
+	mib = 1024 * 1024
+	// unlimitedV1 is the smallest cgroup v1 limit considered as unlimited, the kernel reports the
+	// maximum page-aligned 64 bits value when no limit is set.
+	unlimitedV1 = int64(1) << 62
+	// nodeHeapPercent is the share of the memory limit used by the V8 old space.
+	nodeHeapPercent = 80
+	// NodeHeadroomMiB is the memory left outside the V8 old space in small containers.
+	NodeHeadroomMiB = 64
+	// workerMemoryMiB is the memory allotted to each web worker.
+	workerMemoryMiB = 512
+	// javaRAMPercentage is the share of the memory limit used by the JVM heap.
+	javaRAMPercentage = 75
+	// dotnetHeapPercent is the share of the memory limit used by the .NET GC heap.
+	dotnetHeapPercent = 75
+)
+
+// Limit is the memory limit of the container.
+type Limit struct {
+	// Bytes is the memory limit in bytes.
+	Bytes int64
+	// Source is where the limit was read from.
+	Source string
+}
+
+// Read returns the memory limit of the container from the cgroup v2 memory.max file, the cgroup v1
+// memory.limit_in_bytes file or GOOGLE_CONTAINER_MEMORY_HINT_MB, in that order, skipping the
+// cgroups without a limit. The cgroup files are read under root, which is / outside of tests. It
+// returns nil if there is no limit.
+func Read(root string, getenv func(string) string) (*Limit, error) {
+	limit, err := readCgroupV2(root)
+	if limit != nil || err != nil {
+		return limit, err
+	}
+	limit, err = readCgroupV1(root)
+	if limit != nil || err != nil {
+		return limit, err
+	}
+	return readHint(getenv)
+}
+
+// readCgroupV2 reads memory.max in the cgroup of the current process, then at the root of the
+// cgroup hierarchy, which is the cgroup of the container when cgroup namespaces are used.
+func readCgroupV2(root string) (*Limit, error) {
+	dirs := []string{filepath.Join(root, "sys", "fs", "cgroup")}
+	if path, err := cgroupV2Path(root); err != nil {
+		return nil, err
+	} else if path != "" && path != "/" {
+		dirs = append([]string{filepath.Join(root, "sys", "fs", "cgroup", path)}, dirs...)
+	}
+	for _, dir := range dirs {
+		content, err := os.ReadFile(filepath.Join(dir, "memory.max"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading cgroup v2 memory limit: %w", err)
+		}
+		value := strings.TrimSpace(string(content))
+		if value == "max" {
+			return nil, nil
+		}
+		bytes, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing cgroup v2 memory limit %q: %w", value, err)
+		}
+		return &Limit{Bytes: bytes, Source: SourceCgroupV2}, nil
+	}
+	return nil, nil
+}
+
+// cgroupV2Path returns the path of the cgroup v2 of the current process from /proc/self/cgroup.
+func cgroupV2Path(root string) (string, error) {
+	f, err := os.Open(filepath.Join(root, "proc", "self", "cgroup"))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading cgroups: %w", err)
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		// The cgroup v2 line is 0::PATH.
+		if path, ok := strings.CutPrefix(s.Text(), "0::"); ok {
+			return path, nil
+		}
+	}
+	return "", s.Err()
+}
+
+func readCgroupV1(root string) (*Limit, error) {
+	content, err := os.ReadFile(filepath.Join(root, "sys", "fs", "cgroup", "memory", "memory.limit_in_bytes"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading cgroup v1 memory limit: %w", err)
+	}
+	value := strings.TrimSpace(string(content))
+	bytes, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parsing cgroup v1 memory limit %q: %w", value, err)
+	}
+	if bytes >= unlimitedV1 {
+		return nil, nil
+	}
+	return &Limit{Bytes: bytes, Source: SourceCgroupV1}, nil
+}
+
+func readHint(getenv func(string) string) (*Limit, error) {
+	value := getenv(env.ContainerMemoryHintMB)
+	if value == "" {
+		return nil, nil
+	}
+	mb, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || mb <= 0 {
+		return nil, fmt.Errorf("%s=%q must be a positive integer", env.ContainerMemoryHintMB, value)
+	}
+	return &Limit{Bytes: mb * mib, Source: SourceHint}, nil
+}
+
+// Env returns the environment variables sizing the given runtime for the memory limit. Values set
+// by the user are kept: options are appended to existing NODE_OPTIONS or JAVA_TOOL_OPTIONS only if
+// they do not already configure the heap size.
+func Env(runtime string, limit *Limit, getenv func(string) string, cpus int) (map[string]string, error) {
+	vars := map[string]string{}
+	if limit == nil || limit.Bytes <= 0 {
+		return vars, nil
+	}
+	limitMiB := limit.Bytes / mib
+	switch runtime {
+	case Nodejs:
+		// The heap is divided between the web workers, the user setting WEB_CONCURRENCY to a value
+		// other than a number of workers gets the heap of a single process.
+		n := workers(limitMiB, cpus)
+		if v := getenv("WEB_CONCURRENCY"); v == "" {
+			vars["WEB_CONCURRENCY"] = strconv.FormatInt(n, 10)
+		} else if userN, err := strconv.ParseInt(v, 10, 64); err == nil && userN > 0 {
+			n = userN
+		} else {
+			n = 1
+		}
+		if opts := getenv("NODE_OPTIONS"); !strings.Contains(opts, "--max-old-space-size") {
+			if heap := nodeHeapMiB(limitMiB, n); heap > 0 {
+				vars["NODE_OPTIONS"] = appendOption(opts, fmt.Sprintf("--max-old-space-size=%d", heap))
+			}
+		}
+	case Java:
+		opts := getenv("JAVA_TOOL_OPTIONS")
+		if !strings.Contains(opts, "-Xmx") && !strings.Contains(opts, "MaxRAM") {
+			vars["JAVA_TOOL_OPTIONS"] = appendOption(opts, fmt.Sprintf("-XX:MaxRAMPercentage=%d", javaRAMPercentage))
+		}
+	case Dotnet:
+		if getenv("DOTNET_GCHeapHardLimit") == "" && getenv("DOTNET_GCHeapHardLimitPercent") == "" {
+			// The .NET runtime reads GC settings as hexadecimal values.
+			vars["DOTNET_GCHeapHardLimit"] = fmt.Sprintf("0x%X", limit.Bytes*dotnetHeapPercent/100)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported runtime %q", runtime)
+	}
+	return vars, nil
+}
+
+// workers returns the number of web workers fitting in the memory limit, at least one and at most
+// one per CPU.
+func workers(limitMiB int64, cpus int) int64 {
+	n := limitMiB / workerMemoryMiB
+	if cpus > 0 && n > int64(cpus) {
+		n = int64(cpus)
+	}
+	return max(n, 1)
+}
+
+// nodeHeapMiB returns the V8 old space size of each Node.js process: a share of the memory limit
+// leaving at least NodeHeadroomMiB, divided between the given number of web workers. It returns 0
+// if the limit is too small to leave the headroom.
+func nodeHeapMiB(limitMiB, workers int64) int64 {
+	heap := min(limitMiB*nodeHeapPercent/100, limitMiB-NodeHeadroomMiB)
+	return max(heap/max(workers, 1), 0)
+}
+
+func appendOption(opts, opt string) string {
+	if strings.TrimSpace(opts) == "" {
+		return opt
+	}
+	return opts + " " + opt
+}
+
+// WriteExecD writes the environment variables in the TOML format expected from exec.d binaries
+// on file descriptor 3, sorted by name.
+func WriteExecD(w io.Writer, vars map[string]string) error {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := fmt.Fprintf(w, "%s = %s\n", name, strconv.Quote(vars[name])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
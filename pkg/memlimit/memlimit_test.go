@@ -0,0 +1,275 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memlimit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRead(t *testing.T) {
+	testCases := []struct {
+		name    string
+		files   map[string]string
+		env     map[string]string
+		want    *Limit
+		wantErr bool
+	}{
+		{
+			name:  "cgroup v2",
+			files: map[string]string{"sys/fs/cgroup/memory.max": "536870912\n"},
+			want:  &Limit{Bytes: 512 * mib, Source: SourceCgroupV2},
+		},
+		{
+			name: "cgroup v2 of the process",
+			files: map[string]string{
+				"proc/self/cgroup":                           "0::/kubepods/pod1/app\n",
+				"sys/fs/cgroup/kubepods/pod1/app/memory.max": "1073741824\n",
+				"sys/fs/cgroup/memory.max":                   "max\n",
+			},
+			want: &Limit{Bytes: 1024 * mib, Source: SourceCgroupV2},
+		},
+		{
+			name: "cgroup v2 namespace root",
+			files: map[string]string{
+				"proc/self/cgroup":         "0::/\n",
+				"sys/fs/cgroup/memory.max": "2147483648\n",
+			},
+			want: &Limit{Bytes: 2048 * mib, Source: SourceCgroupV2},
+		},
+		{
+			name: "cgroup v2 unlimited",
+			files: map[string]string{
+				"sys/fs/cgroup/memory.max": "max\n",
+			},
+			env:  map[string]string{"GOOGLE_CONTAINER_MEMORY_HINT_MB": "256"},
+			want: &Limit{Bytes: 256 * mib, Source: SourceHint},
+		},
+		{
+			name:  "cgroup v1",
+			files: map[string]string{"sys/fs/cgroup/memory/memory.limit_in_bytes": "268435456\n"},
+			want:  &Limit{Bytes: 256 * mib, Source: SourceCgroupV1},
+		},
+		{
+			name:  "cgroup v1 unlimited",
+			files: map[string]string{"sys/fs/cgroup/memory/memory.limit_in_bytes": "9223372036854771712\n"},
+			env:   map[string]string{"GOOGLE_CONTAINER_MEMORY_HINT_MB": "256"},
+			want:  &Limit{Bytes: 256 * mib, Source: SourceHint},
+		},
+		{
+			name: "memory hint",
+			env:  map[string]string{"GOOGLE_CONTAINER_MEMORY_HINT_MB": "2048"},
+			want: &Limit{Bytes: 2048 * mib, Source: SourceHint},
+		},
+		{
+			name: "no limit",
+		},
+		{
+			name:    "invalid cgroup v2 limit",
+			files:   map[string]string{"sys/fs/cgroup/memory.max": "lots\n"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid memory hint",
+			env:     map[string]string{"GOOGLE_CONTAINER_MEMORY_HINT_MB": "2G"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			for name, content := range tc.files {
+				path := filepath.Join(root, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("creating directory for %s: %v", name, err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("writing %s: %v", name, err)
+				}
+			}
+
+			got, err := Read(root, getenv(tc.env))
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("Read() got error: %v, want error? %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Read() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEnv(t *testing.T) {
+	testCases := []struct {
+		name    string
+		runtime string
+		limit   *Limit
+		env     map[string]string
+		cpus    int
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "nodejs",
+			runtime: Nodejs,
+			limit:   &Limit{Bytes: 2048 * mib},
+			cpus:    2,
+			want:    map[string]string{"NODE_OPTIONS": "--max-old-space-size=819", "WEB_CONCURRENCY": "2"},
+		},
+		{
+			name:    "nodejs single CPU",
+			runtime: Nodejs,
+			limit:   &Limit{Bytes: 2048 * mib},
+			cpus:    1,
+			want:    map[string]string{"NODE_OPTIONS": "--max-old-space-size=1638", "WEB_CONCURRENCY": "1"},
+		},
+		{
+			name:    "nodejs workers limited by memory",
+			runtime: Nodejs,
+			limit:   &Limit{Bytes: 1024 * mib},
+			cpus:    4,
+			want:    map[string]string{"NODE_OPTIONS": "--max-old-space-size=409", "WEB_CONCURRENCY": "2"},
+		},
+		{
+			name:    "nodejs small container keeps the headroom",
+			runtime: Nodejs,
+			limit:   &Limit{Bytes: 256 * mib},
+			cpus:    4,
+			want:    map[string]string{"NODE_OPTIONS": "--max-old-space-size=192", "WEB_CONCURRENCY": "1"},
+		},
+		{
+			name:    "nodejs container smaller than the headroom",
+			runtime: Nodejs,
+			limit:   &Limit{Bytes: 64 * mib},
+			cpus:    1,
+			want:    map[string]string{"WEB_CONCURRENCY": "1"},
+		},
+		{
+			name:    "nodejs heap divided between the web workers set by the user",
+			runtime: Nodejs,
+			limit:   &Limit{Bytes: 2048 * mib},
+			env:     map[string]string{"WEB_CONCURRENCY": "4"},
+			cpus:    1,
+			want:    map[string]string{"NODE_OPTIONS": "--max-old-space-size=409"},
+		},
+		{
+			name:    "nodejs invalid web concurrency",
+			runtime: Nodejs,
+			limit:   &Limit{Bytes: 2048 * mib},
+			env:     map[string]string{"WEB_CONCURRENCY": "auto"},
+			cpus:    4,
+			want:    map[string]string{"NODE_OPTIONS": "--max-old-space-size=1638"},
+		},
+		{
+			name:    "nodejs appends to NODE_OPTIONS",
+			runtime: Nodejs,
+			limit:   &Limit{Bytes: 1024 * mib},
+			env:     map[string]string{"NODE_OPTIONS": "--enable-source-maps", "WEB_CONCURRENCY": "1"},
+			cpus:    1,
+			want:    map[string]string{"NODE_OPTIONS": "--enable-source-maps --max-old-space-size=819"},
+		},
+		{
+			name:    "nodejs keeps the heap size set by the user",
+			runtime: Nodejs,
+			limit:   &Limit{Bytes: 1024 * mib},
+			env:     map[string]string{"NODE_OPTIONS": "--max-old-space-size=100"},
+			cpus:    1,
+			want:    map[string]string{"WEB_CONCURRENCY": "1"},
+		},
+		{
+			name:    "java",
+			runtime: Java,
+			limit:   &Limit{Bytes: 1024 * mib},
+			env:     map[string]string{"JAVA_TOOL_OPTIONS": "-Dfile.encoding=UTF-8"},
+			want:    map[string]string{"JAVA_TOOL_OPTIONS": "-Dfile.encoding=UTF-8 -XX:MaxRAMPercentage=75"},
+		},
+		{
+			name:    "java keeps the heap size set by the user",
+			runtime: Java,
+			limit:   &Limit{Bytes: 1024 * mib},
+			env:     map[string]string{"JAVA_TOOL_OPTIONS": "-Xmx512m"},
+			want:    map[string]string{},
+		},
+		{
+			name:    "java keeps the RAM percentage set by the user",
+			runtime: Java,
+			limit:   &Limit{Bytes: 1024 * mib},
+			env:     map[string]string{"JAVA_TOOL_OPTIONS": "-XX:MaxRAMPercentage=50"},
+			want:    map[string]string{},
+		},
+		{
+			name:    "dotnet",
+			runtime: Dotnet,
+			limit:   &Limit{Bytes: 1024 * mib},
+			want:    map[string]string{"DOTNET_GCHeapHardLimit": "0x30000000"},
+		},
+		{
+			name:    "dotnet keeps the heap limit set by the user",
+			runtime: Dotnet,
+			limit:   &Limit{Bytes: 1024 * mib},
+			env:     map[string]string{"DOTNET_GCHeapHardLimitPercent": "0x32"},
+			want:    map[string]string{},
+		},
+		{
+			name:    "no limit",
+			runtime: Nodejs,
+			want:    map[string]string{},
+		},
+		{
+			name:    "unsupported runtime",
+			runtime: "cobol",
+			limit:   &Limit{Bytes: 1024 * mib},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Env(tc.runtime, tc.limit, getenv(tc.env), tc.cpus)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("Env() got error: %v, want error? %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Env() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWriteExecD(t *testing.T) {
+	var got strings.Builder
+	vars := map[string]string{"WEB_CONCURRENCY": "2", "NODE_OPTIONS": `--require "./tracing.js" --max-old-space-size=1638`}
+
+	if err := WriteExecD(&got, vars); err != nil {
+		t.Fatalf("WriteExecD() got error: %v", err)
+	}
+	want := `NODE_OPTIONS = "--require \"./tracing.js\" --max-old-space-size=1638"
+WEB_CONCURRENCY = "2"
+`
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Errorf("WriteExecD() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func getenv(vars map[string]string) func(string) string {
+	return func(name string) string {
+		return vars[name]
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nginx
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+// StaticTemplate is a template that produces a complete nginx config serving the static files of
+// a single page application. Unknown paths fall back to index.html for client-side routing, hashed
+// assets are cached for a year and files precompressed at build time with gzip or brotli are served
+// without requiring the gzip_static or brotli modules.
+var StaticTemplate = template.Must(template.New("static").Parse(`
+daemon off;
+worker_processes auto;
+pid {{.TempPath}}/nginx.pid;
+error_log stderr warn;
+
+events {
+	worker_connections 1024;
+}
+
+http {
+	include {{.MimeTypesPath}};
+	default_type application/octet-stream;
+
+	access_log off;
+	server_tokens off;
+	sendfile on;
+	tcp_nopush on;
+	keepalive_timeout 65s;
+
+	client_body_temp_path {{.TempPath}}/client_body;
+	proxy_temp_path {{.TempPath}}/proxy;
+	fastcgi_temp_path {{.TempPath}}/fastcgi;
+	uwsgi_temp_path {{.TempPath}}/uwsgi;
+	scgi_temp_path {{.TempPath}}/scgi;
+
+	gzip on;
+	gzip_vary on;
+	gzip_comp_level 5;
+	gzip_proxied any;
+	gzip_types
+		text/plain
+		text/css
+		text/javascript
+		application/javascript
+		application/json
+		application/wasm
+		application/xml
+		image/svg+xml;
+
+	# Vite and Create React App put hashed file names under assets/ and static/, Angular at the root.
+	map $uri $static_cache_control {
+		default "no-cache";
+		"~^/(assets|static)/.+[.-][A-Za-z0-9_-]{8,}\.\w+(\.br|\.gz)?$" "public, max-age=31536000, immutable";
+		"~^/[^/]+-[A-Z0-9]{8}\.(js|css)(\.br|\.gz)?$" "public, max-age=31536000, immutable";
+	}
+
+	server {
+		listen {{.Port}} default_server;
+		listen [::]:{{.Port}} default_server;
+		server_name "";
+		root {{.Root}};
+		index index.html;
+
+		add_header Cache-Control $static_cache_control;
+
+		# Serve the files precompressed at build time when the client accepts their encoding.
+		set $br_file "";
+		if ($http_accept_encoding ~* "\bbr\b") {
+			set $br_file $request_filename.br;
+		}
+		if (-f $br_file) {
+			rewrite ^ $uri.br last;
+		}
+		set $gz_file "";
+		if ($http_accept_encoding ~* "\bgzip\b") {
+			set $gz_file $request_filename.gz;
+		}
+		if (-f $gz_file) {
+			rewrite ^ $uri.gz last;
+		}
+		{{range .Precompressed}}
+		location ~ "\.({{.Extensions}})\.{{.Suffix}}$" {
+			types { }
+			default_type {{.ContentType}};
+			gzip off;
+			add_header Content-Encoding {{.Encoding}};
+			add_header Vary Accept-Encoding;
+			add_header Cache-Control $static_cache_control;
+		}
+		{{end}}
+		# Missing assets are not routes of the application.
+		location ~ ^/(assets|static)/ {
+			try_files $uri =404;
+		}
+
+		location / {
+			try_files $uri $uri/ /index.html;
+		}
+	}
+}
+`))
+
+// StaticConfig represents the content values of a nginx config file serving static files.
+type StaticConfig struct {
+	// Port is the port to listen on, it can be a placeholder replaced when the server starts.
+	Port string
+	// Root is the directory of the static files.
+	Root string
+	// MimeTypesPath is the path of the mime.types file of the nginx installation.
+	MimeTypesPath string
+	// TempPath is a writable directory for the pid and temporary files.
+	TempPath string
+}
+
+// precompressed is a location serving files precompressed with an encoding.
+type precompressed struct {
+	Extensions  string
+	ContentType string
+	Suffix      string
+	Encoding    string
+}
+
+// precompressedTypes are the content types of the files commonly precompressed by bundlers,
+// keyed by the extensions matched by a location regular expression.
+var precompressedTypes = []struct {
+	extensions  string
+	contentType string
+}{
+	{extensions: "js|mjs", contentType: "application/javascript"},
+	{extensions: "css", contentType: "text/css"},
+	{extensions: "html", contentType: "text/html"},
+	{extensions: "json", contentType: "application/json"},
+	{extensions: "svg", contentType: "image/svg+xml"},
+	{extensions: "wasm", contentType: "application/wasm"},
+	{extensions: "txt", contentType: "text/plain"},
+	{extensions: "xml", contentType: "application/xml"},
+}
+
+const (
+	// nginxStaticConf is the name of the config file serving static files.
+	nginxStaticConf = "nginx-static.conf"
+)
+
+// WriteStaticConfigToPath writes the configuration for the nginx server serving static files to
+// the given directory and returns the path of the file.
+func WriteStaticConfigToPath(path string, conf StaticConfig) (string, error) {
+	data := struct {
+		StaticConfig
+		Precompressed []precompressed
+	}{StaticConfig: conf}
+	for _, enc := range []struct{ suffix, encoding string }{{"br", "br"}, {"gz", "gzip"}} {
+		for _, t := range precompressedTypes {
+			data.Precompressed = append(data.Precompressed, precompressed{
+				Extensions:  t.extensions,
+				ContentType: t.contentType,
+				Suffix:      enc.suffix,
+				Encoding:    enc.encoding,
+			})
+		}
+	}
+
+	confPath := filepath.Join(path, nginxStaticConf)
+	f, err := os.Create(confPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if err := StaticTemplate.Execute(f, data); err != nil {
+		return "", fmt.Errorf("writing nginx static config file: %w", err)
+	}
+	return confPath, nil
+}
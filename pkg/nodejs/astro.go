@@ -0,0 +1,82 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodejs
+
+import (
+	"sort"
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const (
+	// astroNodeAdapter is the Astro adapter building a server run with Node.js.
+	astroNodeAdapter = "@astrojs/node"
+	// astroServerEntry is the server built by the Node.js adapter in standalone mode.
+	astroServerEntry = "dist/server/entry.mjs"
+)
+
+// astroPlatformAdapters are the Astro adapters building the server for another hosting platform.
+var astroPlatformAdapters = []string{"@astrojs/cloudflare", "@astrojs/netlify", "@astrojs/vercel"}
+
+// IsAstro returns true if the given package.json file depends on Astro.
+func IsAstro(p *PackageJSON) bool {
+	return hasDependency(p, "astro")
+}
+
+// DetectAstroNodeAdapter returns true if the given package.json file contains the @astrojs/node
+// adapter, the only Astro adapter building a server that can be run with Node.js.
+func DetectAstroNodeAdapter(p *PackageJSON) bool {
+	return hasDependency(p, astroNodeAdapter)
+}
+
+// AstroStartCommand determines if this is an Astro application built with the Node.js adapter
+// and returns the command to start its standalone server. If it is not an Astro application, or
+// it cannot be served with Node.js, it returns nil.
+func AstroStartCommand(ctx *gcp.Context, pjs *PackageJSON) ([]string, error) {
+	if !IsAstro(pjs) {
+		return nil, nil
+	}
+	if !DetectAstroNodeAdapter(pjs) {
+		if adapters := matchingDependencies(pjs, astroPlatformAdapters); len(adapters) > 0 {
+			ctx.Warnf("The Astro adapter %s builds the application for another hosting platform, use the %s adapter to serve it with Node.js.", strings.Join(adapters, ", "), astroNodeAdapter)
+		} else {
+			ctx.Warnf("No Astro server adapter found, the application is built as a static site. Add the %s adapter to render pages on the server.", astroNodeAdapter)
+		}
+		return nil, nil
+	}
+	exists, err := ctx.FileExists(ctx.ApplicationRoot(), astroServerEntry)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		ctx.Warnf("The Astro server %s was not built, configure the %s adapter with mode: 'standalone' to serve the application with Node.js.", astroServerEntry, astroNodeAdapter)
+		return nil, nil
+	}
+	// The standalone server listens on localhost unless HOST is set.
+	return []string{"env", "HOST=0.0.0.0", "node", astroServerEntry}, nil
+}
+
+// matchingDependencies returns the packages of the given list the package.json file depends on.
+func matchingDependencies(p *PackageJSON, names []string) []string {
+	var found []string
+	for _, n := range names {
+		if hasDependency(p, n) {
+			found = append(found, n)
+		}
+	}
+	sort.Strings(found)
+	return found
+}
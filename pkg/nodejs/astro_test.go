@@ -0,0 +1,80 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodejs
+
+import (
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAstroStartCommand(t *testing.T) {
+	testCases := []struct {
+		name  string
+		pjs   *PackageJSON
+		files map[string]string
+		want  []string
+	}{
+		{
+			name: "not astro",
+			pjs:  &PackageJSON{Dependencies: map[string]string{"express": "^4.21.0"}},
+		},
+		{
+			name:  "node adapter",
+			pjs:   &PackageJSON{Dependencies: map[string]string{"astro": "^5.0.0", "@astrojs/node": "^9.0.0"}},
+			files: map[string]string{"dist/server/entry.mjs": ""},
+			want:  []string{"env", "HOST=0.0.0.0", "node", "dist/server/entry.mjs"},
+		},
+		{
+			name: "node adapter in devDependencies",
+			pjs: &PackageJSON{
+				Dependencies:    map[string]string{"astro": "^5.0.0"},
+				DevDependencies: map[string]string{"@astrojs/node": "^9.0.0"},
+			},
+			files: map[string]string{"dist/server/entry.mjs": ""},
+			want:  []string{"env", "HOST=0.0.0.0", "node", "dist/server/entry.mjs"},
+		},
+		{
+			name:  "no server build",
+			pjs:   &PackageJSON{Dependencies: map[string]string{"astro": "^5.0.0", "@astrojs/node": "^9.0.0"}},
+			files: map[string]string{"dist/client/index.html": ""},
+		},
+		{
+			name:  "static site",
+			pjs:   &PackageJSON{Dependencies: map[string]string{"astro": "^5.0.0"}},
+			files: map[string]string{"dist/index.html": ""},
+		},
+		{
+			name:  "platform adapter",
+			pjs:   &PackageJSON{Dependencies: map[string]string{"astro": "^5.0.0", "@astrojs/vercel": "^8.0.0"}},
+			files: map[string]string{"dist/server/entry.mjs": ""},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeWorkspace(t, tc.files)
+
+			got, err := AstroStartCommand(gcp.NewContext(gcp.WithApplicationRoot(dir)), tc.pjs)
+			if err != nil {
+				t.Fatalf("AstroStartCommand() got error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("AstroStartCommand() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
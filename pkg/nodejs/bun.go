@@ -0,0 +1,135 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodejs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/fetch"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/runtime"
+	"github.com/buildpacks/libcnb/v2"
+)
+
+var (
+	// BunLock is the name of the text lock file of Bun 1.2 and later.
+	BunLock = "bun.lock"
+	// BunLockb is the name of the binary lock file of earlier Bun versions.
+	BunLockb = "bun.lockb"
+	// bunDownloadURL is the template used to generate a Bun download URL. Bun publishes its
+	// binaries to the npm registry as platform specific packages, e.g. @oven/bun-linux-x64.
+	bunDownloadURL = "https://registry.npmjs.org/@oven/bun-linux-%s/-/bun-linux-%s-%s.tgz"
+)
+
+// UsesBun returns true if the application is managed with Bun, i.e. it has a Bun lock file or
+// sets bun in the packageManager package.json field.
+func UsesBun(ctx *gcp.Context, pjs *PackageJSON) (bool, error) {
+	lockExists, err := BunLockExists(ctx)
+	if err != nil || lockExists {
+		return lockExists, err
+	}
+	return pjs != nil && strings.HasPrefix(pjs.PackageManager, "bun@"), nil
+}
+
+// BunLockExists returns true if the application has a Bun lock file.
+func BunLockExists(ctx *gcp.Context) (bool, error) {
+	for _, lock := range []string{BunLock, BunLockb} {
+		exists, err := ctx.FileExists(ctx.ApplicationRoot(), lock)
+		if err != nil || exists {
+			return exists, err
+		}
+	}
+	return false, nil
+}
+
+// InstallBun installs Bun in the given layer if it is not already cached.
+func InstallBun(ctx *gcp.Context, bunLayer *libcnb.Layer, pjs *PackageJSON) error {
+	version, err := detectBunVersion(pjs)
+	if err != nil {
+		return err
+	}
+	return installPackageManager(ctx, bunLayer, "bun", version, pjs, downloadBun)
+}
+
+// downloadBun downloads a given version of Bun into the provided directory.
+func downloadBun(ctx *gcp.Context, dir, version string) error {
+	arch := bunArch()
+	url := fmt.Sprintf(bunDownloadURL, arch, arch, version)
+	// The package contains the binary in package/bin/bun.
+	if err := fetch.Tarball(url, dir, 1); err != nil {
+		return gcp.InternalErrorf("downloading Bun: %w", err)
+	}
+	fp := filepath.Join(dir, "bin", "bun")
+	if err := os.Chmod(fp, 0777); err != nil {
+		return gcp.InternalErrorf("chmoding %s: %w", fp, err)
+	}
+	return nil
+}
+
+// bunArch returns the architecture name used in the Bun packages for the target architecture.
+func bunArch() string {
+	if runtime.TargetArch() == "arm64" {
+		return "aarch64"
+	}
+	return "x64"
+}
+
+// detectBunVersion determines the version of Bun that should be installed in a Node.js project
+// by examining the "engines.bun" and "packageManager" constraints specified in package.json, if
+// both exist "engines.bun" takes precedence. If neither is set it returns the latest version
+// available.
+func detectBunVersion(pjs *PackageJSON) (string, error) {
+	if pjs == nil || (pjs.Engines.Bun == "" && pjs.PackageManager == "") {
+		version, err := latestPackageVersion("bun")
+		if err != nil {
+			return "", gcp.InternalErrorf("fetching available Bun versions: %w", err)
+		}
+		return version, nil
+	}
+	var requestedVersion string
+	if pjs.Engines.Bun != "" {
+		requestedVersion = pjs.Engines.Bun
+	} else {
+		spec, err := parsePackageManager(pjs.PackageManager)
+		if err != nil {
+			return "", err
+		}
+		if spec.name != "bun" {
+			return "", gcp.UserErrorf("Bun was detected but %s is set in the packageManager package.json field.", spec.name)
+		}
+		requestedVersion = spec.version
+	}
+	version, err := resolvePackageVersion("bun", requestedVersion)
+	if err != nil {
+		return "", gcp.UserErrorf("finding Bun version that matched %q: %w", requestedVersion, err)
+	}
+	return version, nil
+}
+
+// BunStartCommand returns the command of the web process of a Bun application: the start script
+// if there is one, or the main module run with Bun.
+func BunStartCommand(pjs *PackageJSON) []string {
+	if HasScript(pjs, "start") {
+		return []string{"bun", "run", "start"}
+	}
+	main := "index.js"
+	if pjs != nil && pjs.Main != "" {
+		main = pjs.Main
+	}
+	return []string{"bun", main}
+}
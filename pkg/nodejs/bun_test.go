@@ -0,0 +1,238 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodejs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/buildpacks/internal/testserver"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/testdata"
+	"github.com/buildpacks/libcnb/v2"
+	"github.com/google/go-cmp/cmp"
+)
+
+const bunRegistryResponse = `{
+	"name": "bun",
+	"dist-tags": {
+		"latest": "1.2.5"
+	},
+	"versions": {
+		"1.1.38": {
+			"name": "bun",
+			"version": "1.1.38"
+		},
+		"1.2.5": {
+			"name": "bun",
+			"version": "1.2.5"
+		}
+	},
+	"modified": "2025-03-11T21:10:55.626Z"
+}`
+
+func TestUsesBun(t *testing.T) {
+	testCases := []struct {
+		name        string
+		files       []string
+		packageJSON *PackageJSON
+		want        bool
+	}{
+		{
+			name:  "bun.lock",
+			files: []string{"bun.lock"},
+			want:  true,
+		},
+		{
+			name:  "bun.lockb",
+			files: []string{"bun.lockb"},
+			want:  true,
+		},
+		{
+			name:        "packageManager",
+			packageJSON: &PackageJSON{PackageManager: "bun@1.2.5"},
+			want:        true,
+		},
+		{
+			name:        "other packageManager",
+			files:       []string{"package-lock.json"},
+			packageJSON: &PackageJSON{PackageManager: "npm@10.0.0"},
+		},
+		{
+			name: "no bun",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, f := range tc.files {
+				if err := os.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+					t.Fatalf("writing %s: %v", f, err)
+				}
+			}
+
+			got, err := UsesBun(gcp.NewContext(gcp.WithApplicationRoot(dir)), tc.packageJSON)
+			if err != nil {
+				t.Fatalf("UsesBun() got error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("UsesBun() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestInstallBun(t *testing.T) {
+	testserver.New(
+		t,
+		testserver.WithFile(testdata.MustGetPath("testdata/dummy-bun.tgz")),
+		testserver.WithMockURL(&bunDownloadURL),
+	)
+	testserver.New(
+		t,
+		testserver.WithJSON(bunRegistryResponse),
+		testserver.WithMockURL(&npmRegistryURL),
+	)
+	layer := &libcnb.Layer{
+		Name:     "bun_test",
+		Path:     t.TempDir(),
+		Metadata: map[string]any{},
+	}
+
+	if err := InstallBun(gcp.NewContext(), layer, &PackageJSON{PackageManager: "bun@1.2.5"}); err != nil {
+		t.Fatalf("InstallBun() got error: %v", err)
+	}
+
+	fp := filepath.Join(layer.Path, "bin", "bun")
+	if _, err := os.Stat(fp); err != nil {
+		t.Errorf("Missing file: %s (%v)", fp, err)
+	}
+	if got := layer.Metadata[versionKey]; got != "1.2.5" {
+		t.Errorf("layer metadata %s = %v, want %q", versionKey, got, "1.2.5")
+	}
+}
+
+func TestDetectBunVersion(t *testing.T) {
+	testCases := []struct {
+		name        string
+		packageJSON *PackageJSON
+		wantVersion string
+		wantError   bool
+	}{
+		{
+			name:        "no package.json returns latest",
+			wantVersion: "1.2.5",
+		},
+		{
+			name:        "no constraints returns latest",
+			packageJSON: &PackageJSON{},
+			wantVersion: "1.2.5",
+		},
+		{
+			name:        "engines version range",
+			packageJSON: &PackageJSON{Engines: packageEnginesJSON{Bun: "1.1.x"}},
+			wantVersion: "1.1.38",
+		},
+		{
+			name:        "packageManager version",
+			packageJSON: &PackageJSON{PackageManager: "bun@1.1.38"},
+			wantVersion: "1.1.38",
+		},
+		{
+			name: "engines takes precedence over packageManager",
+			packageJSON: &PackageJSON{
+				Engines:        packageEnginesJSON{Bun: "1.2.5"},
+				PackageManager: "bun@1.1.38",
+			},
+			wantVersion: "1.2.5",
+		},
+		{
+			name:        "other packageManager",
+			packageJSON: &PackageJSON{PackageManager: "pnpm@9.0.0"},
+			wantError:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testserver.New(
+				t,
+				testserver.WithJSON(bunRegistryResponse),
+				testserver.WithMockURL(&npmRegistryURL),
+			)
+
+			version, err := detectBunVersion(tc.packageJSON)
+			if tc.wantError != (err != nil) {
+				t.Fatalf("detectBunVersion() got error: %v, want error? %v", err, tc.wantError)
+			}
+			if version != tc.wantVersion {
+				t.Errorf("detectBunVersion() = %q, want %q", version, tc.wantVersion)
+			}
+		})
+	}
+}
+
+func TestBunStartCommand(t *testing.T) {
+	testCases := []struct {
+		name        string
+		packageJSON *PackageJSON
+		want        []string
+	}{
+		{
+			name:        "start script",
+			packageJSON: &PackageJSON{Main: "server.ts", Scripts: map[string]string{"start": "bun server.ts"}},
+			want:        []string{"bun", "run", "start"},
+		},
+		{
+			name:        "main",
+			packageJSON: &PackageJSON{Main: "src/server.ts"},
+			want:        []string{"bun", "src/server.ts"},
+		},
+		{
+			name: "no package.json",
+			want: []string{"bun", "index.js"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, BunStartCommand(tc.packageJSON)); diff != "" {
+				t.Errorf("BunStartCommand() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBunArch(t *testing.T) {
+	testCases := []struct {
+		arch string
+		want string
+	}{
+		{arch: "amd64", want: "x64"},
+		{arch: "arm64", want: "aarch64"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.arch, func(t *testing.T) {
+			t.Setenv(libcnb.EnvTargetArch, tc.arch)
+
+			if got := bunArch(); got != tc.want {
+				t.Errorf("bunArch() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
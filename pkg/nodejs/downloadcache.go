@@ -0,0 +1,204 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodejs
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb/v2"
+)
+
+const (
+	// DownloadCacheLayer is the name of the cache layer keeping the packages downloaded by the
+	// package manager across builds, independently of the installed node_modules.
+	DownloadCacheLayer = "download_cache"
+	// DownloadCacheSizeEnv is an env var setting the maximum size of the download cache in MiB,
+	// 0 disables the cache.
+	DownloadCacheSizeEnv = "GOOGLE_NODE_DOWNLOAD_CACHE_SIZE_MB"
+	// defaultDownloadCacheSizeMiB is the default maximum size of the download cache.
+	defaultDownloadCacheSizeMiB = 2048
+	// installBytesKey is the layer metadata key of the size of the packages downloaded by the last
+	// install into an empty cache.
+	installBytesKey = "install_bytes"
+)
+
+// DownloadCache is the content cache of a package manager kept in a cache layer: the _cacache
+// directory of npm, the pnpm store or the Yarn cache.
+type DownloadCache struct {
+	// Dir is the directory of the cache.
+	Dir string
+	// Env are the environment variables configuring the package manager to use the cache.
+	Env []string
+	// MaxBytes is the maximum size of the cache.
+	MaxBytes int64
+
+	layer   *libcnb.Layer
+	pkgTool string
+	// startBytes is the size of the cache before the install.
+	startBytes int64
+}
+
+// NewDownloadCache creates the download cache layer and returns the cache of the package manager.
+// If the cache is disabled, the returned cache has no environment variables and is never evicted.
+func NewDownloadCache(ctx *gcp.Context, pkgTool string) (*DownloadCache, error) {
+	maxMiB, err := downloadCacheSizeMiB()
+	if err != nil {
+		return nil, err
+	}
+	if maxMiB == 0 {
+		return &DownloadCache{}, nil
+	}
+	l, err := ctx.Layer(DownloadCacheLayer, gcp.CacheLayer)
+	if err != nil {
+		return nil, fmt.Errorf("creating %v layer: %w", DownloadCacheLayer, err)
+	}
+	env, err := downloadCacheEnv(ctx, l, pkgTool)
+	if err != nil {
+		return nil, err
+	}
+	startBytes, err := dirSize(l.Path)
+	if err != nil {
+		return nil, fmt.Errorf("measuring download cache: %w", err)
+	}
+	return &DownloadCache{Dir: l.Path, Env: env, MaxBytes: maxMiB * 1024 * 1024, layer: l, pkgTool: pkgTool, startBytes: startBytes}, nil
+}
+
+// downloadCacheEnv returns the environment variables configuring the package manager to read and
+// write its content cache in the layer.
+func downloadCacheEnv(ctx *gcp.Context, l *libcnb.Layer, pkgTool string) ([]string, error) {
+	switch pkgTool {
+	case "npm":
+		return []string{"npm_config_cache=" + l.Path}, nil
+	case "pnpm":
+		return []string{"npm_config_store_dir=" + filepath.Join(l.Path, "store")}, nil
+	case "yarn":
+		yarn2, err := IsYarn2(ctx.ApplicationRoot())
+		if err != nil {
+			return nil, err
+		}
+		if !yarn2 {
+			return []string{"YARN_CACHE_FOLDER=" + l.Path}, nil
+		}
+		// Yarn 4 keeps its cache in the global folder by default, which is also the mirror of the
+		// project caches.
+		env := []string{"YARN_GLOBAL_FOLDER=" + l.Path}
+		// A cache committed to the repository is kept. Plug'n'Play loads the packages from the cache
+		// at launch time, it is installed in a launch layer by the yarn buildpack.
+		committed, err := ctx.FileExists(ctx.ApplicationRoot(), ".yarn", "cache")
+		if err != nil {
+			return nil, err
+		}
+		pnp, err := IsYarnPnP(ctx.ApplicationRoot())
+		if err != nil {
+			return nil, err
+		}
+		if !committed && !pnp {
+			env = append(env, "YARN_CACHE_FOLDER="+filepath.Join(l.Path, "cache"))
+		}
+		return env, nil
+	default:
+		return nil, gcp.InternalErrorf("unsupported package manager %q", pkgTool)
+	}
+}
+
+func downloadCacheSizeMiB() (int64, error) {
+	v, ok := os.LookupEnv(DownloadCacheSizeEnv)
+	if !ok || v == "" {
+		return defaultDownloadCacheSizeMiB, nil
+	}
+	size, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || size < 0 {
+		return 0, gcp.UserErrorf("%s=%q must be a positive number of MiB or 0", DownloadCacheSizeEnv, v)
+	}
+	return size, nil
+}
+
+// Evict removes packages from the cache when it exceeds its maximum size. The caches are
+// content-addressed stores with an index, and pnpm links the stored files into node_modules without
+// updating their modification time, so single packages cannot be evicted by age:
+//   - the pnpm store is pruned with pnpm store prune, which removes the packages no longer linked
+//     into a project;
+//   - the packages downloaded by an install into an empty cache are kept even past the maximum
+//     size, the size of such an install is the effective limit of the next builds;
+//   - otherwise, the cache is cleared and the package managers download the packages again on the
+//     next install.
+func (c *DownloadCache) Evict(ctx *gcp.Context) error {
+	if c.Dir == "" {
+		return nil
+	}
+	total, err := dirSize(c.Dir)
+	if err != nil {
+		return fmt.Errorf("measuring download cache: %w", err)
+	}
+	if total <= c.MaxBytes {
+		return nil
+	}
+	if c.pkgTool == "pnpm" {
+		if _, err := ctx.Exec([]string{"pnpm", "store", "prune"}, gcp.WithUserAttribution, gcp.WithEnv(c.Env...)); err != nil {
+			return gcp.UserErrorf("pruning the pnpm store: %w", err)
+		}
+		if total, err = dirSize(c.Dir); err != nil {
+			return fmt.Errorf("measuring download cache: %w", err)
+		}
+		if total <= c.MaxBytes {
+			return nil
+		}
+	}
+	if c.startBytes == 0 {
+		ctx.Logf("The packages downloaded by the install take %d MiB, more than the %d MiB download cache size, keeping them for the next builds.", total/1024/1024, c.MaxBytes/1024/1024)
+		ctx.SetMetadata(c.layer, installBytesKey, strconv.FormatInt(total, 10))
+		return nil
+	}
+	if installBytes, err := strconv.ParseInt(ctx.GetMetadata(c.layer, installBytesKey), 10, 64); err == nil && total <= installBytes {
+		return nil
+	}
+	ctx.Logf("Download cache size %d MiB exceeds %d MiB, clearing the download cache.", total/1024/1024, c.MaxBytes/1024/1024)
+	entries, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return fmt.Errorf("reading download cache: %w", err)
+	}
+	for _, e := range entries {
+		if err := ctx.RemoveAll(c.Dir, e.Name()); err != nil {
+			return err
+		}
+	}
+	delete(c.layer.Metadata, installBytesKey)
+	return nil
+}
+
+// dirSize returns the total size of the regular files in dir.
+func dirSize(dir string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+		return nil
+	})
+	return total, err
+}
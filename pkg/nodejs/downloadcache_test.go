@@ -0,0 +1,275 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodejs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb/v2"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDownloadCacheEnv(t *testing.T) {
+	testCases := []struct {
+		name    string
+		pkgTool string
+		files   map[string]string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "npm",
+			pkgTool: "npm",
+			want:    []string{"npm_config_cache=/layers/download_cache"},
+		},
+		{
+			name:    "pnpm",
+			pkgTool: "pnpm",
+			want:    []string{"npm_config_store_dir=/layers/download_cache/store"},
+		},
+		{
+			name:    "yarn classic",
+			pkgTool: "yarn",
+			files:   map[string]string{"yarn.lock": "# yarn lockfile v1\n"},
+			want:    []string{"YARN_CACHE_FOLDER=/layers/download_cache"},
+		},
+		{
+			name:    "yarn berry",
+			pkgTool: "yarn",
+			files: map[string]string{
+				"yarn.lock":   "__metadata:\n  version: 8\n",
+				".yarnrc.yml": "nodeLinker: node-modules\n",
+			},
+			want: []string{
+				"YARN_GLOBAL_FOLDER=/layers/download_cache",
+				"YARN_CACHE_FOLDER=/layers/download_cache/cache",
+			},
+		},
+		{
+			name:    "yarn berry plug'n'play",
+			pkgTool: "yarn",
+			files:   map[string]string{"yarn.lock": "__metadata:\n  version: 8\n"},
+			want:    []string{"YARN_GLOBAL_FOLDER=/layers/download_cache"},
+		},
+		{
+			name:    "yarn berry with committed cache",
+			pkgTool: "yarn",
+			files: map[string]string{
+				"yarn.lock":              "__metadata:\n  version: 8\n",
+				".yarn/cache/.gitignore": "",
+			},
+			want: []string{"YARN_GLOBAL_FOLDER=/layers/download_cache"},
+		},
+		{
+			name:    "unsupported",
+			pkgTool: "bun",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeWorkspace(t, tc.files)
+			l := &libcnb.Layer{Path: "/layers/download_cache"}
+
+			got, err := downloadCacheEnv(gcp.NewContext(gcp.WithApplicationRoot(dir)), l, tc.pkgTool)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("downloadCacheEnv() got error: %v, want error? %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("downloadCacheEnv() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDownloadCacheSizeMiB(t *testing.T) {
+	testCases := []struct {
+		name    string
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "default",
+			want: defaultDownloadCacheSizeMiB,
+		},
+		{
+			name:  "custom",
+			value: "512",
+			want:  512,
+		},
+		{
+			name:  "disabled",
+			value: "0",
+		},
+		{
+			name:    "negative",
+			value:   "-1",
+			wantErr: true,
+		},
+		{
+			name:    "invalid",
+			value:   "1GB",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.value != "" {
+				t.Setenv(DownloadCacheSizeEnv, tc.value)
+			}
+
+			got, err := downloadCacheSizeMiB()
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("downloadCacheSizeMiB() got error: %v, want error? %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("downloadCacheSizeMiB() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDownloadCacheEvict(t *testing.T) {
+	// Files of 100 bytes.
+	files := []string{"a", "b/c", "b/d", "e"}
+	testCases := []struct {
+		name         string
+		pkgTool      string
+		maxBytes     int64
+		startBytes   int64
+		metadata     map[string]any
+		want         []string
+		wantMetadata map[string]any
+	}{
+		{
+			name:         "under the maximum size",
+			pkgTool:      "npm",
+			maxBytes:     400,
+			startBytes:   200,
+			want:         []string{"a", "b/c", "b/d", "e"},
+			wantMetadata: map[string]any{},
+		},
+		{
+			name:         "over the maximum size",
+			pkgTool:      "npm",
+			maxBytes:     350,
+			startBytes:   200,
+			wantMetadata: map[string]any{},
+		},
+		{
+			name:         "install into an empty cache over the maximum size",
+			pkgTool:      "npm",
+			maxBytes:     350,
+			want:         []string{"a", "b/c", "b/d", "e"},
+			wantMetadata: map[string]any{installBytesKey: "400"},
+		},
+		{
+			name:         "within the size of an install into an empty cache",
+			pkgTool:      "yarn",
+			maxBytes:     350,
+			startBytes:   400,
+			metadata:     map[string]any{installBytesKey: "400"},
+			want:         []string{"a", "b/c", "b/d", "e"},
+			wantMetadata: map[string]any{installBytesKey: "400"},
+		},
+		{
+			name:         "over the size of an install into an empty cache",
+			pkgTool:      "yarn",
+			maxBytes:     250,
+			startBytes:   300,
+			metadata:     map[string]any{installBytesKey: "300"},
+			wantMetadata: map[string]any{},
+		},
+		{
+			name:         "pnpm store pruned under the maximum size",
+			pkgTool:      "pnpm",
+			maxBytes:     350,
+			startBytes:   400,
+			want:         []string{"a", "b/c", "b/d"},
+			wantMetadata: map[string]any{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, f := range files {
+				path := filepath.Join(dir, f)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(strings.Repeat("x", 100)), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			metadata := map[string]any{}
+			for k, v := range tc.metadata {
+				metadata[k] = v
+			}
+			l := &libcnb.Layer{Name: DownloadCacheLayer, Path: dir, Metadata: metadata}
+			c := &DownloadCache{Dir: dir, MaxBytes: tc.maxBytes, layer: l, pkgTool: tc.pkgTool, startBytes: tc.startBytes}
+			// pnpm store prune removes the packages which are no longer used, e here.
+			ctx := gcp.NewContext(gcp.WithExecCmd(func(name string, args ...string) *exec.Cmd {
+				if got := strings.Join(append([]string{name}, args...), " "); got != "pnpm store prune" {
+					t.Errorf("Evict() ran %q, want pnpm store prune", got)
+				}
+				return exec.Command("rm", filepath.Join(dir, "e"))
+			}))
+
+			if err := c.Evict(ctx); err != nil {
+				t.Fatalf("Evict() got error: %v", err)
+			}
+
+			var got []string
+			err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				rel, err := filepath.Rel(dir, path)
+				got = append(got, rel)
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(dir); err != nil {
+				t.Errorf("download cache directory was removed: %v", err)
+			}
+			sort.Strings(got)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Evict() remaining files mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantMetadata, l.Metadata); diff != "" {
+				t.Errorf("Evict() layer metadata mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDownloadCacheEvictDisabled(t *testing.T) {
+	c := &DownloadCache{}
+	if err := c.Evict(gcp.NewContext()); err != nil {
+		t.Errorf("Evict() got error: %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodejs
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/buildererror"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const (
+	// ModulesStrategyEnv is an env var selecting how node_modules is restored from and saved to
+	// its cache layer: auto (the default), reflink, hardlink, copy or symlink.
+	ModulesStrategyEnv = "GOOGLE_NODE_MODULES_STRATEGY"
+
+	// ModulesAuto clones the files if the filesystems support reflinks, and copies them otherwise.
+	ModulesAuto = "auto"
+	// ModulesReflink clones the files, sharing their blocks until either copy is modified.
+	ModulesReflink = "reflink"
+	// ModulesHardlink links the files. Lifecycle scripts, patch-package and native module rebuilds
+	// modify installed files in place, which also modifies the linked files of the cache, so it is
+	// only used if selected explicitly.
+	ModulesHardlink = "hardlink"
+	// ModulesCopy copies the files.
+	ModulesCopy = "copy"
+	// ModulesSymlink installs the modules in a launch layer linked from the application
+	// node_modules directory, they are never copied. It is only used if selected explicitly as
+	// some tools do not follow a symlinked node_modules directory.
+	ModulesSymlink = "symlink"
+)
+
+// modulesCopyFlags are the cp flags of each strategy copying node_modules.
+var modulesCopyFlags = map[string][]string{
+	ModulesReflink:  {"--reflink=always"},
+	ModulesHardlink: {"--link"},
+	ModulesCopy:     nil,
+}
+
+// autoModulesStrategies are the strategies tried in order by ModulesAuto.
+var autoModulesStrategies = []string{ModulesReflink, ModulesCopy}
+
+// modulesCopyBytesPerSecond is a conservative estimate of the throughput of a plain copy of
+// node_modules, which is dominated by small files. It is used to report the time saved by the
+// other strategies.
+const modulesCopyBytesPerSecond = 100 * 1024 * 1024
+
+// ModulesStrategy returns the strategy selected with GOOGLE_NODE_MODULES_STRATEGY.
+func ModulesStrategy() (string, error) {
+	v := strings.ToLower(strings.TrimSpace(os.Getenv(ModulesStrategyEnv)))
+	switch v {
+	case "":
+		return ModulesAuto, nil
+	case ModulesAuto, ModulesReflink, ModulesHardlink, ModulesCopy, ModulesSymlink:
+		return v, nil
+	default:
+		return "", gcp.UserErrorf("%s=%q must be one of %s, %s, %s, %s or %s", ModulesStrategyEnv, v, ModulesAuto, ModulesReflink, ModulesHardlink, ModulesCopy, ModulesSymlink)
+	}
+}
+
+// CopyModules copies the src node_modules directory to dst, which must not exist, with the given
+// strategy and returns the strategy used. ModulesAuto falls back to a copy when the filesystems do
+// not support reflinks. The duration is reported in a span named after the strategy used and, for
+// the strategies sharing the data of the files, the time saved compared to a plain copy is
+// reported in a second span.
+func CopyModules(ctx *gcp.Context, strategy, src, dst string) (string, error) {
+	strategies := []string{strategy}
+	if strategy == ModulesAuto {
+		strategies = autoModulesStrategies
+	}
+	start := time.Now()
+	var err error
+	for _, s := range strategies {
+		flags, ok := modulesCopyFlags[s]
+		if !ok {
+			return "", gcp.InternalErrorf("node_modules cannot be copied with strategy %q", s)
+		}
+		cmd := append(append([]string{"cp", "--archive"}, flags...), src, dst)
+		if _, err = ctx.Exec(cmd, gcp.WithUserTimingAttribution); err == nil {
+			elapsed := time.Since(start)
+			ctx.Span(fmt.Sprintf("Copy node_modules (%s)", s), start, buildererror.StatusOk)
+			ctx.Logf("Copied %s to %s with strategy %s in %v.", src, dst, s, elapsed.Round(time.Millisecond))
+			if s != ModulesCopy {
+				reportTimeSaved(ctx, s, dst, elapsed)
+			}
+			return s, nil
+		}
+		// Remove the partial copy before the next attempt.
+		if rmErr := ctx.RemoveAll(dst); rmErr != nil {
+			return "", rmErr
+		}
+	}
+	ctx.Span(fmt.Sprintf("Copy node_modules (%s)", strategy), start, buildererror.StatusInternal)
+	return "", err
+}
+
+// reportTimeSaved reports the estimated time saved by copying the dir node_modules directory with
+// the given strategy rather than with a plain copy, in a span lasting the time saved.
+func reportTimeSaved(ctx *gcp.Context, strategy, dir string, elapsed time.Duration) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	if err != nil {
+		ctx.Debugf("Measuring %s: %v", dir, err)
+		return
+	}
+	saved := modulesCopyTime(size) - elapsed
+	if saved <= 0 {
+		return
+	}
+	ctx.Span(fmt.Sprintf("Copy node_modules time saved (%s)", strategy), time.Now().Add(-saved), buildererror.StatusOk)
+	ctx.Logf("Saved about %v copying %d MiB of node_modules with strategy %s.", saved.Round(time.Millisecond), size/1024/1024, strategy)
+}
+
+// modulesCopyTime returns the estimated duration of a plain copy of size bytes of node_modules.
+func modulesCopyTime(size int64) time.Duration {
+	return time.Duration(float64(size) / modulesCopyBytesPerSecond * float64(time.Second))
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodejs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+func TestModulesStrategy(t *testing.T) {
+	testCases := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "default",
+			want: ModulesAuto,
+		},
+		{
+			name:  "hardlink",
+			value: "hardlink",
+			want:  ModulesHardlink,
+		},
+		{
+			name:  "case insensitive",
+			value: "Symlink",
+			want:  ModulesSymlink,
+		},
+		{
+			name:    "invalid",
+			value:   "rsync",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.value != "" {
+				t.Setenv(ModulesStrategyEnv, tc.value)
+			}
+
+			got, err := ModulesStrategy()
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("ModulesStrategy() got error: %v, want error? %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("ModulesStrategy() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCopyModules(t *testing.T) {
+	testCases := []struct {
+		name     string
+		strategy string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "auto",
+			strategy: ModulesAuto,
+			// Reflinks depend on the filesystem of the test.
+			want: []string{ModulesReflink, ModulesCopy},
+		},
+		{
+			name:     "hardlink",
+			strategy: ModulesHardlink,
+			want:     []string{ModulesHardlink},
+		},
+		{
+			name:     "copy",
+			strategy: ModulesCopy,
+			want:     []string{ModulesCopy},
+		},
+		{
+			name:     "symlink",
+			strategy: ModulesSymlink,
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeWorkspace(t, map[string]string{
+				"src/express/package.json": `{"name": "express"}`,
+				"src/.bin/express":         "#!/bin/sh",
+			})
+			src := filepath.Join(dir, "src")
+			dst := filepath.Join(dir, "dst")
+
+			got, err := CopyModules(gcp.NewContext(), tc.strategy, src, dst)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("CopyModules() got error: %v, want error? %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			found := false
+			for _, w := range tc.want {
+				found = found || got == w
+			}
+			if !found {
+				t.Errorf("CopyModules() = %q, want one of %v", got, tc.want)
+			}
+			content, err := os.ReadFile(filepath.Join(dst, "express", "package.json"))
+			if err != nil {
+				t.Fatalf("reading copied file: %v", err)
+			}
+			if string(content) != `{"name": "express"}` {
+				t.Errorf("copied file content = %q, want %q", content, `{"name": "express"}`)
+			}
+		})
+	}
+}
+
+func TestModulesCopyTime(t *testing.T) {
+	if got, want := modulesCopyTime(250*1024*1024), 2500*time.Millisecond; got != want {
+		t.Errorf("modulesCopyTime(250 MiB) = %v, want %v", got, want)
+	}
+}
@@ -68,6 +68,7 @@ type packageEnginesJSON struct {
 	NPM  string `json:"npm"`
 	Yarn string `json:"yarn"`
 	PNPM string `json:"pnpm"`
+	Bun  string `json:"bun"`
 }
 
 const (
@@ -90,6 +91,7 @@ type PackageJSON struct {
 	Dependencies    map[string]string  `json:"dependencies"`
 	DevDependencies map[string]string  `json:"devDependencies"`
 	PackageManager  string             `json:"packageManager,omitempty"`
+	Workspaces      packageWorkspaces  `json:"workspaces,omitempty"`
 }
 
 // NpmLockfile represents the contents of a lock file generated with npm.
@@ -245,6 +247,17 @@ func DependencyVersion(p *PackageJSON, name string) string {
 	return version
 }
 
+// hasDependency returns true if the given package.json file lists the package in its dependencies
+// or devDependencies.
+func hasDependency(p *PackageJSON, name string) bool {
+	if p == nil {
+		return false
+	}
+	_, dep := p.Dependencies[name]
+	_, devDep := p.DevDependencies[name]
+	return dep || devDep
+}
+
 // RequestedNodejsVersion returns any customer provided Node.js version constraint by inspecting the
 // environment and the package.json.
 func RequestedNodejsVersion(ctx *gcp.Context, pjs *PackageJSON) (string, error) {
@@ -423,16 +436,6 @@ func Version(deps *NodeDependencies, pkg string) (string, error) {
 	return "", gcp.UserErrorf("Failed to find version for package %s", pkg)
 }
 
-// parsePackageManager parses the packageManager field and returns the manager name and version.
-// packageManagerField must have this regex (pnpm|yarn)@\d+\.\d+\.\d+(-.+)?, e.g. pnpm@9.0.0.
-func parsePackageManager(packageManagerField string) (string, string, error) {
-	packageManagerSplit := strings.Split(packageManagerField, "@")
-	if len(packageManagerSplit) != 2 {
-		return "", "", gcp.UserErrorf("parsing packageManager package.json field")
-	}
-	return packageManagerSplit[0], packageManagerSplit[1], nil
-}
-
 // MajorVersion returns the major version of a version string of format "major.minor.patch".
 func MajorVersion(versionString string) (string, error) {
 	parts := strings.Split(versionString, ".")
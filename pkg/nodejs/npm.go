@@ -15,6 +15,7 @@
 package nodejs
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -22,6 +23,8 @@ import (
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/buildermetrics"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/shell"
+	"github.com/buildpacks/libcnb/v2"
 	"github.com/Masterminds/semver"
 )
 
@@ -39,6 +42,9 @@ const (
 	VendorNpmDeps = "GOOGLE_VENDOR_NPM_DEPENDENCIES"
 	// AppHostingBuildEnv is the env var that contains the build command to run for Firebase backends.
 	AppHostingBuildEnv = "APPHOSTING_BUILD"
+	// BuildShellEnv is an env var that runs build commands with sh -c, to support shell operators
+	// and expansions such as `npm run lint && npm run build`.
+	BuildShellEnv = "GOOGLE_NODE_BUILD_SHELL"
 )
 
 var (
@@ -48,19 +54,46 @@ var (
 	minNpmCIVersion = semver.MustParse("6.14.0")
 )
 
-// RequestedNPMVersion returns any customer provided NPM version constraint configured in the
-// "engines" section of the package.json file in the given application dir.
+// RequestedNPMVersion returns any customer provided NPM version configured in the "engines" section
+// or the packageManager field of the package.json file, "engines" takes precedence.
 func RequestedNPMVersion(pjs *PackageJSON) (string, error) {
-	if pjs == nil || pjs.Engines.NPM == "" {
+	if pjs == nil {
+		return "", nil
+	}
+	requestedVersion := pjs.Engines.NPM
+	if requestedVersion == "" && pjs.PackageManager != "" {
+		spec, err := parsePackageManager(pjs.PackageManager)
+		if err != nil {
+			return "", err
+		}
+		if spec.name == "npm" {
+			requestedVersion = spec.version
+		}
+	}
+	if requestedVersion == "" {
 		return "", nil
 	}
-	version, err := resolvePackageVersion("npm", pjs.Engines.NPM)
+	version, err := resolvePackageVersion("npm", requestedVersion)
 	if err != nil {
-		gcp.InternalErrorf("fetching npm metadata: %v", err)
+		return "", gcp.InternalErrorf("fetching npm metadata: %v", err)
 	}
 	return version, nil
 }
 
+// InstallNPM installs the given version of npm in the layer if it is not already cached.
+func InstallNPM(ctx *gcp.Context, npmLayer *libcnb.Layer, version string, pjs *PackageJSON) error {
+	return installPackageManager(ctx, npmLayer, "npm", version, pjs, installNPMWithNPM)
+}
+
+// installNPMWithNPM installs a given version of npm into the provided directory with the npm
+// bundled with Node.js.
+func installNPMWithNPM(ctx *gcp.Context, dir, version string) error {
+	prefix := fmt.Sprintf("--prefix=%s", dir)
+	pkg := fmt.Sprintf("npm@%s", version)
+	_, err := ctx.Exec([]string{"npm", "install", "-g", prefix, pkg}, gcp.WithUserAttribution)
+	return err
+}
+
 // EnsureLockfile returns the name of the lockfile, generating a package-lock.json if necessary.
 func EnsureLockfile(ctx *gcp.Context) (string, error) {
 	npmShrinkwrapExists, err := ctx.FileExists(NPMShrinkwrap)
@@ -202,6 +235,32 @@ func runCommand(pkgTool, command string) string {
 	return fmt.Sprintf("%s run %s", pkgTool, strings.TrimSpace(command))
 }
 
+// ParseBuildCommand returns the arguments of a build command returned by DetermineBuildCommands and
+// the variables assigned before it, e.g. NODE_OPTIONS=--max-old-space-size=4096 npm run build.
+// The command is split into words following the shell quoting rules, or run with sh -c if
+// GOOGLE_NODE_BUILD_SHELL is true.
+func ParseBuildCommand(cmd string) ([]string, []string, error) {
+	useShell, err := env.IsPresentAndTrue(BuildShellEnv)
+	if err != nil {
+		return nil, nil, gcp.UserErrorf("parsing %s: %w", BuildShellEnv, err)
+	}
+	if useShell {
+		return []string{"sh", "-c", cmd}, nil, nil
+	}
+	parsed, err := shell.Parse(cmd)
+	if err != nil {
+		var shellErr *shell.Error
+		if errors.As(err, &shellErr) && shellErr.RequiresShell {
+			return nil, nil, gcp.UserErrorf("%w, set %s=true to run the build command with sh -c", err, BuildShellEnv)
+		}
+		return nil, nil, gcp.UserErrorf("invalid build command: %w", err)
+	}
+	if len(parsed.Args) == 0 {
+		return nil, nil, gcp.UserErrorf("build command %q is empty", cmd)
+	}
+	return parsed.Args, parsed.Env, nil
+}
+
 // DefaultStartCommand returns the default command that should be used to configure the application
 // web process if the user has not explicitly configured one. The algorithm follows the conventions
 // of Nodejs package.json files: https://docs.npmjs.com/cli/v10/configuring-npm/package-json#main
@@ -225,6 +284,12 @@ func DefaultStartCommand(ctx *gcp.Context, pjs *PackageJSON) ([]string, error) {
 	if svelteKit, err := SvelteKitStartCommand(ctx); err != nil || svelteKit != nil {
 		return svelteKit, err
 	}
+	if astro, err := AstroStartCommand(ctx, pjs); err != nil || astro != nil {
+		return astro, err
+	}
+	if remix, err := RemixStartCommand(ctx, pjs); err != nil || remix != nil {
+		return remix, err
+	}
 	exists, err := ctx.FileExists(ctx.ApplicationRoot(), "server.js")
 	if err != nil {
 		return nil, err
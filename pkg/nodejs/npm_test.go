@@ -41,6 +41,21 @@ func TestRequestedNPMVersion(t *testing.T) {
 			packageJSON: `{"engines": {"npm": "2.2.2"}}`,
 			want:        "2.2.2",
 		},
+		{
+			name:        "packageManager npm set",
+			packageJSON: `{"packageManager": "npm@10.8.1+sha512.00"}`,
+			want:        "10.8.1",
+		},
+		{
+			name:        "engines.npm takes precedence over packageManager",
+			packageJSON: `{"engines": {"npm": "2.2.2"}, "packageManager": "npm@10.8.1"}`,
+			want:        "2.2.2",
+		},
+		{
+			name:        "packageManager pnpm set",
+			packageJSON: `{"packageManager": "pnpm@9.1.0"}`,
+			want:        "",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -348,6 +363,69 @@ func TestDetermineBuildCommands(t *testing.T) {
 	}
 }
 
+func TestParseBuildCommand(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cmd      string
+		useShell string
+		wantArgs []string
+		wantEnv  []string
+		wantErr  bool
+	}{
+		{
+			name:     "run script",
+			cmd:      "npm run build",
+			wantArgs: []string{"npm", "run", "build"},
+		},
+		{
+			name:     "quoted arguments and assignments",
+			cmd:      `NODE_OPTIONS="--max-old-space-size=4096" npx ng build --base-href '/my app/'`,
+			wantArgs: []string{"npx", "ng", "build", "--base-href", "/my app/"},
+			wantEnv:  []string{"NODE_OPTIONS=--max-old-space-size=4096"},
+		},
+		{
+			name:    "operators require opt-in",
+			cmd:     "npm run lint && npm run build",
+			wantErr: true,
+		},
+		{
+			name:     "shell opt-in",
+			cmd:      "npm run lint && npm run build",
+			useShell: "true",
+			wantArgs: []string{"sh", "-c", "npm run lint && npm run build"},
+		},
+		{
+			name:    "unterminated quote",
+			cmd:     `npm run "build`,
+			wantErr: true,
+		},
+		{
+			name:    "empty command",
+			cmd:     " ",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.useShell != "" {
+				t.Setenv(BuildShellEnv, tc.useShell)
+			}
+
+			gotArgs, gotEnv, err := ParseBuildCommand(tc.cmd)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("ParseBuildCommand(%q) got error: %v, want error? %v", tc.cmd, err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.wantArgs, gotArgs); diff != "" {
+				t.Errorf("ParseBuildCommand(%q) args mismatch (-want +got):\n%s", tc.cmd, diff)
+			}
+			if diff := cmp.Diff(tc.wantEnv, gotEnv); diff != "" {
+				t.Errorf("ParseBuildCommand(%q) env mismatch (-want +got):\n%s", tc.cmd, diff)
+			}
+		})
+	}
+}
+
 func TestDefaultStartCommand(t *testing.T) {
 	testsCases := []struct {
 		name        string
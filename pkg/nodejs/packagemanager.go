@@ -0,0 +1,255 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodejs
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/fetch"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb/v2"
+	"github.com/Masterminds/semver"
+)
+
+var (
+	// npmTarballURL is the template used to generate the URL of a package archive in the npm
+	// registry from the package name, its unscoped name and its version.
+	npmTarballURL = "https://registry.npmjs.org/%s/-/%s-%s.tgz"
+	// integrityKey is the metadata key used to store the integrity hash of the package manager.
+	integrityKey = "integrity"
+	// corepackKey is the metadata key used to store the Node.js version whose corepack installed the
+	// package manager.
+	corepackKey = "corepack"
+	// minCorepackNodeVersion is the first Node.js version bundling corepack.
+	minCorepackNodeVersion = semver.MustParse("16.9.0")
+	// corepackManagers are the package managers that corepack can provision.
+	corepackManagers = map[string]bool{"pnpm": true, "yarn": true}
+	// hashAlgorithms are the algorithms of the integrity hashes supported in the packageManager field.
+	hashAlgorithms = map[string]func() hash.Hash{
+		"sha1":   sha1.New,
+		"sha224": sha256.New224,
+		"sha256": sha256.New,
+		"sha384": sha512.New384,
+		"sha512": sha512.New,
+	}
+)
+
+// packageManagerSpec is a package manager reference of the packageManager package.json field,
+// e.g. pnpm@9.1.0+sha512.6e5c...
+type packageManagerSpec struct {
+	name    string
+	version string
+	// hash is the "<algorithm>.<hex digest>" integrity hash of the package manager archive, if any.
+	hash string
+}
+
+func (s packageManagerSpec) String() string {
+	if s.hash == "" {
+		return s.name + "@" + s.version
+	}
+	return s.name + "@" + s.version + "+" + s.hash
+}
+
+// parsePackageManager parses the packageManager field, e.g. pnpm@9.0.0 or
+// yarn@4.1.0+sha224.953c8233f7a92884eee2de69a1b92d1f2ec1655e66d08071ba9a02fa.
+func parsePackageManager(packageManagerField string) (packageManagerSpec, error) {
+	name, version, ok := strings.Cut(packageManagerField, "@")
+	if !ok || name == "" || version == "" || strings.Contains(version, "@") {
+		return packageManagerSpec{}, gcp.UserErrorf("parsing packageManager package.json field %q", packageManagerField)
+	}
+	version, hash, _ := strings.Cut(version, "+")
+	return packageManagerSpec{name: name, version: version, hash: hash}, nil
+}
+
+// packageManagerInstaller downloads a version of a package manager into a directory, the package
+// manager executable must be installed in its bin subdirectory.
+type packageManagerInstaller func(ctx *gcp.Context, dir, version string) error
+
+// installPackageManager is the provisioning shared by all package managers. It installs the given
+// version of a package manager in the layer unless the layer already contains it, and puts it
+// first in the PATH. When the version is the one requested in the packageManager package.json
+// field, it is installed with corepack if the installed Node.js bundles it, or from the npm
+// registry if the field includes an integrity hash, which is verified in both cases. Otherwise
+// the package manager is installed with install.
+func installPackageManager(ctx *gcp.Context, l *libcnb.Layer, name, version string, pjs *PackageJSON, install packageManagerInstaller) error {
+	var spec *packageManagerSpec
+	if pjs != nil && pjs.PackageManager != "" {
+		s, err := parsePackageManager(pjs.PackageManager)
+		if err != nil {
+			return err
+		}
+		if s.name == name && s.version == version {
+			spec = &s
+		}
+	}
+	var hash, corepack string
+	if spec != nil {
+		hash = spec.hash
+		if corepackManagers[name] {
+			v, err := corepackNodeVersion(ctx)
+			if err != nil {
+				return err
+			}
+			corepack = v
+		}
+	}
+
+	binDir := filepath.Join(l.Path, "bin")
+	corepackHome := filepath.Join(l.Path, "corepack")
+	if ctx.GetMetadata(l, versionKey) == version && ctx.GetMetadata(l, integrityKey) == hash && ctx.GetMetadata(l, corepackKey) == corepack {
+		ctx.CacheHit(l.Name)
+		ctx.Logf("%s v%s cache hit, skipping installation.", name, version)
+	} else {
+		ctx.CacheMiss(l.Name)
+		if err := ctx.ClearLayer(l); err != nil {
+			return fmt.Errorf("clearing layer %q: %w", l.Name, err)
+		}
+		switch {
+		case corepack != "":
+			ctx.Logf("Installing %s with corepack", spec)
+			if err := installWithCorepack(ctx, binDir, corepackHome, *spec); err != nil {
+				return err
+			}
+		case hash != "":
+			ctx.Logf("Installing %s from the npm registry", spec)
+			if err := installFromRegistry(ctx, l.Path, *spec); err != nil {
+				return err
+			}
+		default:
+			ctx.Logf("Installing %s v%s", name, version)
+			if err := install(ctx, l.Path, version); err != nil {
+				return err
+			}
+		}
+	}
+
+	ctx.SetMetadata(l, versionKey, version)
+	ctx.SetMetadata(l, integrityKey, hash)
+	ctx.SetMetadata(l, corepackKey, corepack)
+	if corepack != "" {
+		// The corepack shims resolve the package manager from COREPACK_HOME.
+		l.SharedEnvironment.Override("COREPACK_HOME", corepackHome)
+		if err := ctx.Setenv("COREPACK_HOME", corepackHome); err != nil {
+			return err
+		}
+	}
+	// We need to update the path here to ensure the version we just installed takes precedence over
+	// anything pre-installed in the base image.
+	return ctx.Setenv("PATH", binDir+":"+os.Getenv("PATH"))
+}
+
+// corepackNodeVersion returns the version of the installed Node.js if it bundles corepack, or an
+// empty string otherwise. It can be overridden for testing.
+var corepackNodeVersion = func(ctx *gcp.Context) (string, error) {
+	if _, err := exec.LookPath("corepack"); err != nil {
+		// Node.js 25 and later no longer bundle corepack.
+		return "", nil
+	}
+	nodeVer, err := nodeVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+	v, err := semver.NewVersion(strings.TrimSpace(nodeVer))
+	if err != nil {
+		return "", gcp.InternalErrorf("parsing Node.js version %q: %v", nodeVer, err)
+	}
+	if v.LessThan(minCorepackNodeVersion) {
+		return "", nil
+	}
+	return v.String(), nil
+}
+
+// installWithCorepack installs the package manager with corepack, which verifies its integrity
+// hash if any, and links its shims in binDir.
+func installWithCorepack(ctx *gcp.Context, binDir, corepackHome string, spec packageManagerSpec) error {
+	if err := ctx.MkdirAll(binDir, 0755); err != nil {
+		return err
+	}
+	env := gcp.WithEnv("COREPACK_HOME="+corepackHome, "COREPACK_ENABLE_DOWNLOAD_PROMPT=0")
+	if _, err := ctx.Exec([]string{"corepack", "enable", "--install-directory", binDir, spec.name}, env); err != nil {
+		return gcp.InternalErrorf("enabling corepack for %s: %w", spec.name, err)
+	}
+	if _, err := ctx.Exec([]string{"corepack", "prepare", spec.String(), "--activate"}, env, gcp.WithUserAttribution); err != nil {
+		return gcp.UserErrorf("installing %s with corepack: %w", spec, err)
+	}
+	return nil
+}
+
+// installFromRegistry installs the package manager from its package archive in the npm registry
+// after verifying the integrity hash of the archive.
+func installFromRegistry(ctx *gcp.Context, dir string, spec packageManagerSpec) error {
+	algorithm, digest, _ := strings.Cut(spec.hash, ".")
+	newHash, ok := hashAlgorithms[algorithm]
+	if !ok {
+		return gcp.UserErrorf("unsupported hash algorithm %q in packageManager %q", algorithm, spec)
+	}
+	wantDigest, err := hex.DecodeString(digest)
+	if err != nil {
+		return gcp.UserErrorf("invalid hash in packageManager %q: %v", spec, err)
+	}
+	pkg, bin, err := registryPackage(spec)
+	if err != nil {
+		return err
+	}
+	libDir := filepath.Join(dir, "lib", spec.name)
+	if err := ctx.MkdirAll(libDir, 0755); err != nil {
+		return err
+	}
+	url := fmt.Sprintf(npmTarballURL, pkg, path.Base(pkg), spec.version)
+	// Package archives contain the package files in a package directory.
+	if err := fetch.VerifiedTarball(url, libDir, 1, newHash(), wantDigest); err != nil {
+		return err
+	}
+	binDir := filepath.Join(dir, "bin")
+	if err := ctx.MkdirAll(binDir, 0755); err != nil {
+		return err
+	}
+	target := filepath.Join(libDir, bin)
+	if err := os.Chmod(target, 0755); err != nil {
+		return gcp.InternalErrorf("chmoding %s: %w", target, err)
+	}
+	return ctx.Symlink(target, filepath.Join(binDir, spec.name))
+}
+
+// registryPackage returns the npm registry package distributing a package manager version and the
+// path of its executable in the package.
+func registryPackage(spec packageManagerSpec) (string, string, error) {
+	switch spec.name {
+	case "npm":
+		return "npm", "bin/npm-cli.js", nil
+	case "pnpm":
+		return "pnpm", "bin/pnpm.cjs", nil
+	case "yarn":
+		v, err := semver.NewVersion(spec.version)
+		if err != nil {
+			return "", "", gcp.UserErrorf("parsing yarn version %q: %v", spec.version, err)
+		}
+		if v.LessThan(version2) {
+			return "yarn", "bin/yarn.js", nil
+		}
+		return "@yarnpkg/cli-dist", "bin/yarn.js", nil
+	}
+	return "", "", gcp.UserErrorf("integrity hashes are not supported for %s in the packageManager package.json field", spec.name)
+}
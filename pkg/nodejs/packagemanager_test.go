@@ -0,0 +1,220 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodejs
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/buildpacks/internal/mockprocess"
+	"github.com/GoogleCloudPlatform/buildpacks/internal/testserver"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/testdata"
+	"github.com/buildpacks/libcnb/v2"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParsePackageManager(t *testing.T) {
+	testCases := []struct {
+		name    string
+		field   string
+		want    packageManagerSpec
+		wantErr bool
+	}{
+		{
+			name:  "version",
+			field: "pnpm@9.1.0",
+			want:  packageManagerSpec{name: "pnpm", version: "9.1.0"},
+		},
+		{
+			name:  "prerelease version",
+			field: "yarn@4.0.0-rc.53",
+			want:  packageManagerSpec{name: "yarn", version: "4.0.0-rc.53"},
+		},
+		{
+			name:  "hash",
+			field: "yarn@4.1.0+sha224.953c8233f7a92884eee2de69a1b92d1f2ec1655e66d08071ba9a02fa",
+			want:  packageManagerSpec{name: "yarn", version: "4.1.0", hash: "sha224.953c8233f7a92884eee2de69a1b92d1f2ec1655e66d08071ba9a02fa"},
+		},
+		{
+			name:    "no version",
+			field:   "pnpm",
+			wantErr: true,
+		},
+		{
+			name:    "scoped name",
+			field:   "@yarnpkg/cli@4.1.0",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parsePackageManager(tc.field)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("parsePackageManager(%q) got error: %v, want error? %v", tc.field, err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(packageManagerSpec{})); diff != "" {
+				t.Errorf("parsePackageManager(%q) mismatch (-want +got):\n%s", tc.field, diff)
+			}
+			if !tc.wantErr && got.String() != tc.field {
+				t.Errorf("parsePackageManager(%q).String() = %q, want %q", tc.field, got.String(), tc.field)
+			}
+		})
+	}
+}
+
+func TestInstallPackageManager(t *testing.T) {
+	archive, err := os.ReadFile(testdata.MustGetPath("testdata/dummy-pnpm.tgz"))
+	if err != nil {
+		t.Fatalf("reading dummy-pnpm.tgz: %v", err)
+	}
+	digest := sha512.Sum512(archive)
+	hash := "sha512." + hex.EncodeToString(digest[:])
+
+	testCases := []struct {
+		name          string
+		packageJSON   *PackageJSON
+		metadata      map[string]any
+		wantInstalled bool
+		wantFile      string
+		wantMetadata  map[string]any
+		wantErr       bool
+	}{
+		{
+			name:          "no packageManager",
+			packageJSON:   &PackageJSON{Engines: packageEnginesJSON{PNPM: "9.1.0"}},
+			wantInstalled: true,
+			wantFile:      "bin/pnpm",
+			wantMetadata:  map[string]any{"version": "9.1.0", "integrity": "", "corepack": ""},
+		},
+		{
+			name:          "packageManager without hash",
+			packageJSON:   &PackageJSON{PackageManager: "pnpm@9.1.0"},
+			wantInstalled: true,
+			wantFile:      "bin/pnpm",
+			wantMetadata:  map[string]any{"version": "9.1.0", "integrity": "", "corepack": ""},
+		},
+		{
+			name:         "packageManager with hash",
+			packageJSON:  &PackageJSON{PackageManager: "pnpm@9.1.0+" + hash},
+			wantFile:     "bin/pnpm",
+			wantMetadata: map[string]any{"version": "9.1.0", "integrity": hash, "corepack": ""},
+		},
+		{
+			name:         "hash for another version is ignored",
+			packageJSON:  &PackageJSON{Engines: packageEnginesJSON{PNPM: "9.1.0"}, PackageManager: "pnpm@9.0.0+sha512.00"},
+			metadata:     map[string]any{"version": "9.1.0", "integrity": "", "corepack": ""},
+			wantMetadata: map[string]any{"version": "9.1.0", "integrity": "", "corepack": ""},
+		},
+		{
+			name:         "hash change invalidates cache",
+			packageJSON:  &PackageJSON{PackageManager: "pnpm@9.1.0+" + hash},
+			metadata:     map[string]any{"version": "9.1.0", "integrity": "", "corepack": ""},
+			wantFile:     "bin/pnpm",
+			wantMetadata: map[string]any{"version": "9.1.0", "integrity": hash, "corepack": ""},
+		},
+		{
+			name:        "hash mismatch",
+			packageJSON: &PackageJSON{PackageManager: "pnpm@9.1.0+sha512." + strings.Repeat("0", 128)},
+			wantErr:     true,
+		},
+		{
+			name:        "unsupported hash algorithm",
+			packageJSON: &PackageJSON{PackageManager: "pnpm@9.1.0+md5.00"},
+			wantErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			testserver.New(
+				t,
+				testserver.WithFile(testdata.MustGetPath("testdata/dummy-pnpm.tgz")),
+				testserver.WithMockURL(&npmTarballURL),
+			)
+			origCorepackNodeVersion := corepackNodeVersion
+			t.Cleanup(func() { corepackNodeVersion = origCorepackNodeVersion })
+			corepackNodeVersion = func(*gcp.Context) (string, error) { return "", nil }
+			metadata := map[string]any{}
+			for k, v := range tc.metadata {
+				metadata[k] = v
+			}
+			layer := &libcnb.Layer{Name: "pnpm", Path: t.TempDir(), Metadata: metadata}
+			installed := false
+			install := func(ctx *gcp.Context, dir, version string) error {
+				installed = true
+				if err := os.MkdirAll(filepath.Join(dir, "bin"), 0755); err != nil {
+					return err
+				}
+				return os.WriteFile(filepath.Join(dir, "bin", "pnpm"), nil, 0755)
+			}
+
+			err := installPackageManager(gcp.NewContext(), layer, "pnpm", "9.1.0", tc.packageJSON, install)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("installPackageManager() got error: %v, want error? %v", err, tc.wantErr)
+			}
+			if installed != tc.wantInstalled {
+				t.Errorf("installPackageManager() called the installer: %v, want %v", installed, tc.wantInstalled)
+			}
+			if tc.wantFile != "" {
+				if _, err := os.Stat(filepath.Join(layer.Path, tc.wantFile)); err != nil {
+					t.Errorf("Missing file: %s (%v)", tc.wantFile, err)
+				}
+			}
+			if tc.wantMetadata != nil {
+				if diff := cmp.Diff(tc.wantMetadata, layer.Metadata); diff != "" {
+					t.Errorf("installPackageManager() layer metadata mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestInstallPackageManagerWithCorepack(t *testing.T) {
+	origCorepackNodeVersion := corepackNodeVersion
+	t.Cleanup(func() { corepackNodeVersion = origCorepackNodeVersion })
+	corepackNodeVersion = func(*gcp.Context) (string, error) { return "20.11.0", nil }
+	t.Setenv("COREPACK_HOME", "")
+	eCmd, err := mockprocess.NewExecCmd(
+		mockprocess.New(`^corepack enable --install-directory .*/bin pnpm$`),
+		mockprocess.New(`^corepack prepare pnpm@9\.1\.0\+sha512\.00 --activate$`),
+	)
+	if err != nil {
+		t.Fatalf("error creating mock exec command: %v", err)
+	}
+	ctx := gcp.NewContext(gcp.WithExecCmd(eCmd))
+	layer := &libcnb.Layer{Name: "pnpm", Path: t.TempDir(), Metadata: map[string]any{}}
+	install := func(ctx *gcp.Context, dir, version string) error {
+		t.Errorf("installPackageManager() called the installer, want corepack")
+		return nil
+	}
+
+	if err := installPackageManager(ctx, layer, "pnpm", "9.1.0", &PackageJSON{PackageManager: "pnpm@9.1.0+sha512.00"}, install); err != nil {
+		t.Fatalf("installPackageManager() got error: %v", err)
+	}
+
+	want := map[string]any{"version": "9.1.0", "integrity": "sha512.00", "corepack": "20.11.0"}
+	if diff := cmp.Diff(want, layer.Metadata); diff != "" {
+		t.Errorf("installPackageManager() layer metadata mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := os.Getenv("COREPACK_HOME"), filepath.Join(layer.Path, "corepack"); got != want {
+		t.Errorf("COREPACK_HOME = %q, want %q", got, want)
+	}
+}
@@ -15,57 +15,33 @@ var (
 	PNPMLock = "pnpm-lock.yaml"
 	// pnpmDownloadURL is the template used to generate a pnpm download URL.
 	pnpmDownloadURL = "https://github.com/pnpm/pnpm/releases/download/v%s/pnpm-linux-x64"
-	// pnpmVersionKey is the metadata key used to store the pnpm version in the pnpn layer.
-	pnpmVersionKey = "version"
 )
 
 // InstallPNPM installs pnpm in the given layer if it is not already cached.
 func InstallPNPM(ctx *gcp.Context, pnpmLayer *libcnb.Layer, pjs *PackageJSON) error {
-	layerName := pnpmLayer.Name
-	installDir := filepath.Join(pnpmLayer.Path, "bin")
 	version, err := detectPNPMVersion(pjs)
 	if err != nil {
 		return err
 	}
-	// Check the metadata in the cache layer to determine if we need to proceed.
-	metaVersion := ctx.GetMetadata(pnpmLayer, pnpmVersionKey)
-	if version == metaVersion {
-		ctx.CacheHit(layerName)
-		ctx.Logf("pnpm cache hit: %q, %q, skipping installation.", version, metaVersion)
-	} else {
-		ctx.CacheMiss(layerName)
-		if err := ctx.ClearLayer(pnpmLayer); err != nil {
-			return fmt.Errorf("clearing layer %q: %w", layerName, err)
-		}
-		// Download and install pnpm in layer.
-		ctx.Logf("Installing pnpm v%s", version)
-		if err := downloadPNPM(ctx, installDir, version); err != nil {
-			return gcp.InternalErrorf("downloading pnpm: %w", err)
-		}
-		fp := filepath.Join(installDir, "pnpm")
-		if err := os.Chmod(fp, 0777); err != nil {
-			return gcp.InternalErrorf("chmoding %s: %w", fp, err)
-		}
-	}
-
-	// Store layer flags and metadata.
-	ctx.SetMetadata(pnpmLayer, versionKey, version)
-	// We need to update the path here to ensure the version we just installed take precedence over
-	// anything pre-installed in the base image.
-	if err := ctx.Setenv("PATH", installDir+":"+os.Getenv("PATH")); err != nil {
-		return err
-	}
-	return nil
+	return installPackageManager(ctx, pnpmLayer, "pnpm", version, pjs, downloadPNPM)
 }
 
-// downloadPNPM downloads a given version of pnpm into the provided directory.
+// downloadPNPM downloads the standalone executable of a given version of pnpm into the bin
+// subdirectory of the provided directory.
 func downloadPNPM(ctx *gcp.Context, dir, version string) error {
-	if err := ctx.MkdirAll(dir, 0755); err != nil {
+	binDir := filepath.Join(dir, "bin")
+	if err := ctx.MkdirAll(binDir, 0755); err != nil {
 		return err
 	}
-	fp := filepath.Join(dir, "pnpm")
+	fp := filepath.Join(binDir, "pnpm")
 	url := fmt.Sprintf(pnpmDownloadURL, version)
-	return fetch.File(url, fp)
+	if err := fetch.File(url, fp); err != nil {
+		return gcp.InternalErrorf("downloading pnpm: %w", err)
+	}
+	if err := os.Chmod(fp, 0777); err != nil {
+		return gcp.InternalErrorf("chmoding %s: %w", fp, err)
+	}
+	return nil
 }
 
 // detectPnpmVersion determines the version of pnpm that should be installed in a Node.js project
@@ -89,14 +65,14 @@ func detectPNPMVersion(pjs *PackageJSON) (string, error) {
 	if pjs.Engines.PNPM != "" {
 		requestedVersion = pjs.Engines.PNPM
 	} else {
-		packageManagerName, packageManagerVersion, err := parsePackageManager(pjs.PackageManager)
+		spec, err := parsePackageManager(pjs.PackageManager)
 		if err != nil {
 			return "", err
 		}
-		if packageManagerName != "pnpm" {
-			return "", gcp.UserErrorf("pnpm was detected but %s is set in the packageManager package.json field.", packageManagerName)
+		if spec.name != "pnpm" {
+			return "", gcp.UserErrorf("pnpm was detected but %s is set in the packageManager package.json field.", spec.name)
 		}
-		requestedVersion = packageManagerVersion
+		requestedVersion = spec.version
 	}
 	version, err := resolvePackageVersion("pnpm", requestedVersion)
 	if err != nil {
@@ -0,0 +1,106 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodejs
+
+import (
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+// remixClientBuild is the directory of the client assets of a React Router or Remix application.
+const remixClientBuild = "build/client"
+
+// remixServerBuilds are the server builds of React Router v7 and Remix v2 with Vite, followed by
+// the server build of the classic Remix compiler.
+var remixServerBuilds = []string{"build/server/index.js", "build/index.js"}
+
+// remixServers are the packages serving a React Router or Remix server build with Node.js, with
+// the binary they provide.
+var remixServers = []struct {
+	pkg string
+	bin string
+}{
+	{pkg: "@react-router/serve", bin: "react-router-serve"},
+	{pkg: "@remix-run/serve", bin: "remix-serve"},
+}
+
+// remixPlatformAdapters are the React Router and Remix adapters building the server for another
+// hosting platform.
+var remixPlatformAdapters = []string{
+	"@netlify/remix-adapter",
+	"@react-router/architect",
+	"@react-router/cloudflare",
+	"@remix-run/architect",
+	"@remix-run/cloudflare",
+	"@remix-run/cloudflare-pages",
+	"@remix-run/deno",
+	"@vercel/remix",
+}
+
+// IsRemix returns true if the given package.json file depends on React Router v7 in framework
+// mode or on Remix.
+func IsRemix(p *PackageJSON) bool {
+	return hasDependency(p, "@react-router/dev") || hasDependency(p, "@remix-run/dev") || hasDependency(p, "@remix-run/react")
+}
+
+// RemixStartCommand determines if this is a React Router or Remix application and returns the
+// command serving its server build with react-router-serve or remix-serve. If it is not a React
+// Router or Remix application, or it cannot be served with Node.js, it returns nil.
+func RemixStartCommand(ctx *gcp.Context, pjs *PackageJSON) ([]string, error) {
+	if !IsRemix(pjs) {
+		return nil, nil
+	}
+	if adapters := matchingDependencies(pjs, remixPlatformAdapters); len(adapters) > 0 {
+		ctx.Warnf("The adapter %s builds the application for another hosting platform and cannot be served with Node.js.", strings.Join(adapters, ", "))
+		return nil, nil
+	}
+	serverBuild, err := remixServerBuild(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if serverBuild == "" {
+		client, err := ctx.FileExists(ctx.ApplicationRoot(), remixClientBuild)
+		if err != nil {
+			return nil, err
+		}
+		if client {
+			ctx.Warnf("No server build found, the application is built as a static single page application (ssr: false) and needs a static file server.")
+		}
+		return nil, nil
+	}
+	for _, s := range remixServers {
+		if hasDependency(pjs, s.pkg) {
+			return []string{s.bin, serverBuild}, nil
+		}
+	}
+	ctx.Warnf("Found the server build %s but no server to run it, add %s or %s to the dependencies.", serverBuild, remixServers[0].pkg, remixServers[1].pkg)
+	return nil, nil
+}
+
+// remixServerBuild returns the path of the server build relative to the application root, or an
+// empty string if there is none.
+func remixServerBuild(ctx *gcp.Context) (string, error) {
+	for _, b := range remixServerBuilds {
+		exists, err := ctx.FileExists(ctx.ApplicationRoot(), b)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return b, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodejs
+
+import (
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRemixStartCommand(t *testing.T) {
+	testCases := []struct {
+		name  string
+		pjs   *PackageJSON
+		files map[string]string
+		want  []string
+	}{
+		{
+			name:  "not remix",
+			pjs:   &PackageJSON{Dependencies: map[string]string{"react-router": "^7.0.0"}},
+			files: map[string]string{"build/server/index.js": ""},
+		},
+		{
+			name: "react router",
+			pjs: &PackageJSON{
+				Dependencies:    map[string]string{"react-router": "^7.0.0", "@react-router/serve": "^7.0.0"},
+				DevDependencies: map[string]string{"@react-router/dev": "^7.0.0"},
+			},
+			files: map[string]string{"build/server/index.js": "", "build/client/assets/root.js": ""},
+			want:  []string{"react-router-serve", "build/server/index.js"},
+		},
+		{
+			name: "remix vite",
+			pjs: &PackageJSON{
+				Dependencies:    map[string]string{"@remix-run/react": "^2.15.0", "@remix-run/serve": "^2.15.0"},
+				DevDependencies: map[string]string{"@remix-run/dev": "^2.15.0"},
+			},
+			files: map[string]string{"build/server/index.js": ""},
+			want:  []string{"remix-serve", "build/server/index.js"},
+		},
+		{
+			name: "remix classic compiler",
+			pjs: &PackageJSON{
+				Dependencies:    map[string]string{"@remix-run/react": "^2.15.0", "@remix-run/serve": "^2.15.0"},
+				DevDependencies: map[string]string{"@remix-run/dev": "^2.15.0"},
+			},
+			files: map[string]string{"build/index.js": ""},
+			want:  []string{"remix-serve", "build/index.js"},
+		},
+		{
+			name: "spa mode",
+			pjs: &PackageJSON{
+				Dependencies:    map[string]string{"react-router": "^7.0.0", "@react-router/serve": "^7.0.0"},
+				DevDependencies: map[string]string{"@react-router/dev": "^7.0.0"},
+			},
+			files: map[string]string{"build/client/index.html": ""},
+		},
+		{
+			name: "no server package",
+			pjs: &PackageJSON{
+				Dependencies:    map[string]string{"react-router": "^7.0.0", "@react-router/express": "^7.0.0"},
+				DevDependencies: map[string]string{"@react-router/dev": "^7.0.0"},
+			},
+			files: map[string]string{"build/server/index.js": ""},
+		},
+		{
+			name: "platform adapter",
+			pjs: &PackageJSON{
+				Dependencies:    map[string]string{"@remix-run/cloudflare": "^2.15.0", "@remix-run/serve": "^2.15.0"},
+				DevDependencies: map[string]string{"@remix-run/dev": "^2.15.0"},
+			},
+			files: map[string]string{"build/server/index.js": ""},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeWorkspace(t, tc.files)
+
+			got, err := RemixStartCommand(gcp.NewContext(gcp.WithApplicationRoot(dir)), tc.pjs)
+			if err != nil {
+				t.Fatalf("RemixStartCommand() got error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("RemixStartCommand() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
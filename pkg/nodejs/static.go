@@ -0,0 +1,190 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodejs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/devmode"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb/v2"
+)
+
+// StaticSiteEnv is an env var that disables serving single page applications with nginx when set
+// to false, the application is then started like any other Node.js application.
+const StaticSiteEnv = "GOOGLE_NODE_STATIC_SITE"
+
+// staticSPABuilders are the packages building a single page application into static files.
+var staticSPABuilders = []string{"vite", "react-scripts", "@angular/build", "@angular-devkit/build-angular"}
+
+// serverFrameworks are the packages of frameworks rendering pages on a server, possibly built
+// with one of the staticSPABuilders.
+var serverFrameworks = []string{
+	"@analogjs/platform",
+	"@angular/ssr",
+	"@react-router/dev",
+	"@remix-run/dev",
+	"@solidjs/start",
+	"@sveltejs/kit",
+	"@tanstack/react-start",
+	"astro",
+	"next",
+	"nuxt",
+	"vike",
+}
+
+// devServerStartScripts are the start scripts running a development server rather than the
+// application, they are ignored when serving the static files.
+var devServerStartScripts = []string{"vite", "react-scripts start", "ng serve"}
+
+// staticOutputDirs are the directories the static files are built into, in order of preference:
+// Vite, Create React App, then Angular 17+ and earlier Angular versions.
+var staticOutputDirs = []string{"dist", "build", "dist/*/browser", "dist/*"}
+
+// StaticSiteEnabled returns false if serving single page applications with nginx was disabled
+// with GOOGLE_NODE_STATIC_SITE.
+func StaticSiteEnabled() (bool, error) {
+	v, ok := os.LookupEnv(StaticSiteEnv)
+	if !ok || v == "" {
+		return true, nil
+	}
+	enabled, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, gcp.UserErrorf("parsing %s=%q: %v", StaticSiteEnv, v, err)
+	}
+	return enabled, nil
+}
+
+// StaticSPAOptOutReason returns why the nodejs/static buildpack does not serve the application
+// with nginx, or an empty string if it does once the application is built.
+func StaticSPAOptOutReason(ctx *gcp.Context) (string, error) {
+	enabled, err := StaticSiteEnabled()
+	if err != nil {
+		return "", err
+	}
+	if !enabled {
+		return fmt.Sprintf("%s is false", StaticSiteEnv), nil
+	}
+	if _, ok := os.LookupEnv(env.Entrypoint); ok {
+		return fmt.Sprintf("%s is set", env.Entrypoint), nil
+	}
+	if _, ok := os.LookupEnv(env.FunctionTarget); ok {
+		return fmt.Sprintf("%s is set", env.FunctionTarget), nil
+	}
+	procfileExists, err := ctx.FileExists(ctx.ApplicationRoot(), "Procfile")
+	if err != nil {
+		return "", err
+	}
+	if procfileExists {
+		return "Procfile found", nil
+	}
+	if devmode.Enabled(ctx) {
+		return "dev mode is enabled", nil
+	}
+	pjs, err := ReadPackageJSONIfExists(ctx.ApplicationRoot())
+	if err != nil {
+		return "", err
+	}
+	if pjs == nil {
+		return "package.json not found", nil
+	}
+	if !IsStaticSPA(pjs) {
+		return "not a single page application built into static files", nil
+	}
+	return "", nil
+}
+
+// ServesStaticSPA returns true if the nodejs/static buildpack serves the built application with
+// nginx, in which case node_modules is not needed at run time. It must be called after the build
+// scripts ran.
+func ServesStaticSPA(ctx *gcp.Context) (bool, error) {
+	reason, err := StaticSPAOptOutReason(ctx)
+	if err != nil || reason != "" {
+		return false, err
+	}
+	dir, err := StaticOutputDir(ctx)
+	return dir != "", err
+}
+
+// SkipModulesAtLaunch removes the given layers holding the dependencies of the application, e.g.
+// a node_modules directory linked into the application, from the launch image if the application
+// is served with nginx, see ServesStaticSPA. Nil layers are ignored.
+func SkipModulesAtLaunch(ctx *gcp.Context, layers ...*libcnb.Layer) error {
+	var launched []*libcnb.Layer
+	for _, l := range layers {
+		if l != nil && l.Launch {
+			launched = append(launched, l)
+		}
+	}
+	if len(launched) == 0 {
+		return nil
+	}
+	static, err := ServesStaticSPA(ctx)
+	if err != nil || !static {
+		return err
+	}
+	for _, l := range launched {
+		ctx.Debugf("Excluding layer %s from the launch image, the static files are served with nginx.", l.Name)
+		l.Launch = false
+	}
+	return nil
+}
+
+// IsStaticSPA returns true if the given package.json file describes a single page application
+// built into static files by Vite, Create React App or Angular, without server-side rendering.
+func IsStaticSPA(p *PackageJSON) bool {
+	if p == nil || ExtractAngularStartCommand(p) != "" {
+		return false
+	}
+	if len(matchingDependencies(p, serverFrameworks)) > 0 {
+		return false
+	}
+	if start, ok := p.Scripts["start"]; ok && !isDevServer(start) {
+		return false
+	}
+	return len(matchingDependencies(p, staticSPABuilders)) > 0
+}
+
+// isDevServer returns true if the script runs one of the devServerStartScripts.
+func isDevServer(script string) bool {
+	fields := strings.Fields(script)
+	for _, d := range devServerStartScripts {
+		dev := strings.Fields(d)
+		if len(fields) >= len(dev) && strings.Join(fields[:len(dev)], " ") == d {
+			return true
+		}
+	}
+	return false
+}
+
+// StaticOutputDir returns the directory, relative to the application root, containing the
+// index.html file of a built single page application, or an empty string if there is none.
+func StaticOutputDir(ctx *gcp.Context) (string, error) {
+	for _, d := range staticOutputDirs {
+		matches, err := filepath.Glob(filepath.Join(ctx.ApplicationRoot(), d, "index.html"))
+		if err != nil {
+			return "", fmt.Errorf("finding static output in %s: %w", d, err)
+		}
+		if len(matches) > 0 {
+			return filepath.Rel(ctx.ApplicationRoot(), filepath.Dir(matches[0]))
+		}
+	}
+	return "", nil
+}
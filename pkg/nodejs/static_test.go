@@ -0,0 +1,244 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodejs
+
+import (
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb/v2"
+)
+
+func TestIsStaticSPA(t *testing.T) {
+	testCases := []struct {
+		name string
+		pjs  *PackageJSON
+		want bool
+	}{
+		{
+			name: "vite",
+			pjs: &PackageJSON{
+				Scripts:         map[string]string{"dev": "vite", "build": "tsc -b && vite build", "preview": "vite preview"},
+				DevDependencies: map[string]string{"vite": "^6.0.0"},
+			},
+			want: true,
+		},
+		{
+			name: "create react app",
+			pjs: &PackageJSON{
+				Scripts:      map[string]string{"start": "react-scripts start", "build": "react-scripts build"},
+				Dependencies: map[string]string{"react-scripts": "5.0.1"},
+			},
+			want: true,
+		},
+		{
+			name: "angular",
+			pjs: &PackageJSON{
+				Scripts:         map[string]string{"start": "ng serve", "build": "ng build"},
+				DevDependencies: map[string]string{"@angular/build": "^19.0.0"},
+			},
+			want: true,
+		},
+		{
+			name: "angular ssr",
+			pjs: &PackageJSON{
+				Scripts:         map[string]string{"start": "ng serve", "build": "ng build", "serve:ssr:app": "node dist/app/server/server.mjs"},
+				Dependencies:    map[string]string{"@angular/ssr": "^19.0.0"},
+				DevDependencies: map[string]string{"@angular/build": "^19.0.0"},
+			},
+		},
+		{
+			name: "vite with a server",
+			pjs: &PackageJSON{
+				Scripts:         map[string]string{"start": "node server.js", "build": "vite build"},
+				DevDependencies: map[string]string{"vite": "^6.0.0"},
+			},
+		},
+		{
+			name: "vite dev server named like another command",
+			pjs: &PackageJSON{
+				Scripts:         map[string]string{"start": "vite-node server.ts", "build": "vite build"},
+				DevDependencies: map[string]string{"vite": "^6.0.0"},
+			},
+		},
+		{
+			name: "server framework built with vite",
+			pjs: &PackageJSON{
+				Scripts:         map[string]string{"build": "react-router build"},
+				DevDependencies: map[string]string{"vite": "^6.0.0", "@react-router/dev": "^7.0.0"},
+			},
+		},
+		{
+			name: "express",
+			pjs:  &PackageJSON{Dependencies: map[string]string{"express": "^4.21.0"}},
+		},
+		{
+			name: "no package.json",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsStaticSPA(tc.pjs); got != tc.want {
+				t.Errorf("IsStaticSPA() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStaticOutputDir(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name:  "vite",
+			files: map[string]string{"dist/index.html": "", "dist/assets/index-BxY7c2a1.js": ""},
+			want:  "dist",
+		},
+		{
+			name:  "create react app",
+			files: map[string]string{"build/index.html": "", "build/static/js/main.3f2a1b9c.js": ""},
+			want:  "build",
+		},
+		{
+			name:  "angular",
+			files: map[string]string{"dist/my-app/browser/index.html": "", "dist/my-app/3rdpartylicenses.txt": ""},
+			want:  "dist/my-app/browser",
+		},
+		{
+			name:  "angular before 17",
+			files: map[string]string{"dist/my-app/index.html": ""},
+			want:  "dist/my-app",
+		},
+		{
+			name:  "no index.html",
+			files: map[string]string{"dist/main.js": ""},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeWorkspace(t, tc.files)
+
+			got, err := StaticOutputDir(gcp.NewContext(gcp.WithApplicationRoot(dir)))
+			if err != nil {
+				t.Fatalf("StaticOutputDir() got error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("StaticOutputDir() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStaticSiteEnabled(t *testing.T) {
+	testCases := []struct {
+		name    string
+		value   string
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "default",
+			want: true,
+		},
+		{
+			name:  "true",
+			value: "true",
+			want:  true,
+		},
+		{
+			name:  "false",
+			value: "false",
+		},
+		{
+			name:    "invalid",
+			value:   "nginx",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.value != "" {
+				t.Setenv(StaticSiteEnv, tc.value)
+			}
+
+			got, err := StaticSiteEnabled()
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("StaticSiteEnabled() got error: %v, want error? %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("StaticSiteEnabled() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSkipModulesAtLaunch(t *testing.T) {
+	const spa = `{"scripts": {"build": "vite build"}, "devDependencies": {"vite": "^5.0.0"}}`
+	testCases := []struct {
+		name       string
+		files      map[string]string
+		env        map[string]string
+		wantLaunch bool
+	}{
+		{
+			name:  "static files served with nginx",
+			files: map[string]string{"package.json": spa, "dist/index.html": ""},
+		},
+		{
+			name:       "no static files built",
+			files:      map[string]string{"package.json": spa},
+			wantLaunch: true,
+		},
+		{
+			name:       "static site disabled",
+			files:      map[string]string{"package.json": spa, "dist/index.html": ""},
+			env:        map[string]string{StaticSiteEnv: "false"},
+			wantLaunch: true,
+		},
+		{
+			name:       "function",
+			files:      map[string]string{"package.json": spa, "dist/index.html": ""},
+			env:        map[string]string{"GOOGLE_FUNCTION_TARGET": "helloWorld"},
+			wantLaunch: true,
+		},
+		{
+			name:       "server application",
+			files:      map[string]string{"package.json": `{"scripts": {"start": "node server.js"}, "dependencies": {"vite": "^5.0.0"}}`, "dist/index.html": ""},
+			wantLaunch: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			dir := writeWorkspace(t, tc.files)
+			l := &libcnb.Layer{Name: "npm_modules", LayerTypes: libcnb.LayerTypes{Launch: true}}
+
+			if err := SkipModulesAtLaunch(gcp.NewContext(gcp.WithApplicationRoot(dir)), l, nil); err != nil {
+				t.Fatalf("SkipModulesAtLaunch() got error: %v", err)
+			}
+			if l.Launch != tc.wantLaunch {
+				t.Errorf("SkipModulesAtLaunch() layer launch = %v, want %v", l.Launch, tc.wantLaunch)
+			}
+		})
+	}
+}
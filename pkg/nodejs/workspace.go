@@ -0,0 +1,541 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodejs
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// PNPMWorkspaceFile is the name of the file declaring the packages of a pnpm workspace.
+	PNPMWorkspaceFile = "pnpm-workspace.yaml"
+	// TurboJSON is the name of the Turborepo configuration file.
+	TurboJSON = "turbo.json"
+	// WorkspaceBuildEnv opts in to building a single workspace package, selected with
+	// GOOGLE_BUILDABLE, from the workspace root.
+	WorkspaceBuildEnv = "GOOGLE_NODE_WORKSPACE_BUILD"
+	// turboPruneDir is the temporary directory turbo prune writes the pruned workspace to.
+	turboPruneDir = "turbo-prune"
+)
+
+// packageWorkspaces is the workspaces package.json field. It is either a list of globs matching
+// the workspace packages, or with Yarn classic an object with the list in its packages field.
+type packageWorkspaces []string
+
+func (w *packageWorkspaces) UnmarshalJSON(data []byte) error {
+	var patterns []string
+	if err := json.Unmarshal(data, &patterns); err == nil {
+		*w = patterns
+		return nil
+	}
+	var yarnWorkspaces struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(data, &yarnWorkspaces); err != nil {
+		return err
+	}
+	*w = yarnWorkspaces.Packages
+	return nil
+}
+
+// pnpmWorkspace represents the contents of a pnpm-workspace.yaml file.
+type pnpmWorkspace struct {
+	Packages []string `yaml:"packages"`
+}
+
+// Workspace is a monorepo whose packages are installed together by npm, pnpm or Yarn workspaces.
+type Workspace struct {
+	// Root is the directory of the workspace root package.
+	Root string
+	// Patterns are the globs matching the directories of the workspace packages. Patterns starting
+	// with ! exclude directories.
+	Patterns []string
+	// Turbo is true if the tasks of the workspace are run with Turborepo.
+	Turbo bool
+}
+
+// WorkspacePackage is a package of a workspace.
+type WorkspacePackage struct {
+	// Name is the name of the package in its package.json.
+	Name string
+	// Dir is the directory of the package relative to the workspace root.
+	Dir string
+}
+
+// ReadWorkspaceIfExists returns the workspace whose root is the given dir, declared either in
+// pnpm-workspace.yaml or in the workspaces field of package.json. If the provided dir is not a
+// workspace root it returns nil.
+func ReadWorkspaceIfExists(dir string) (*Workspace, error) {
+	patterns, err := readPNPMWorkspacePatterns(dir)
+	if err != nil {
+		return nil, err
+	}
+	if patterns == nil {
+		pjs, err := ReadPackageJSONIfExists(dir)
+		if err != nil {
+			return nil, err
+		}
+		if pjs != nil {
+			patterns = pjs.Workspaces
+		}
+	}
+	if patterns == nil {
+		return nil, nil
+	}
+	turbo, err := fileExists(filepath.Join(dir, TurboJSON))
+	if err != nil {
+		return nil, err
+	}
+	return &Workspace{Root: dir, Patterns: patterns, Turbo: turbo}, nil
+}
+
+// readPNPMWorkspacePatterns returns the packages of the pnpm-workspace.yaml file in dir, or nil if
+// there is none.
+func readPNPMWorkspacePatterns(dir string) ([]string, error) {
+	f := filepath.Join(dir, PNPMWorkspaceFile)
+	raw, err := os.ReadFile(f)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, gcp.InternalErrorf("reading %s: %v", f, err)
+	}
+	var ws pnpmWorkspace
+	if err := yaml.Unmarshal(raw, &ws); err != nil {
+		return nil, gcp.UserErrorf("unmarshalling %s: %v", f, err)
+	}
+	if ws.Packages == nil {
+		// The packages field is optional since pnpm 9, the workspace then only contains the root.
+		return []string{}, nil
+	}
+	return ws.Packages, nil
+}
+
+func fileExists(path string) (bool, error) {
+	_, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, gcp.InternalErrorf("stating %s: %v", path, err)
+	}
+	return true, nil
+}
+
+// Packages returns the packages of the workspace sorted by directory. Directories matching the
+// patterns without a package.json file are ignored.
+func (w *Workspace) Packages() ([]WorkspacePackage, error) {
+	dirs := map[string]bool{}
+	for _, p := range w.Patterns {
+		exclude := strings.HasPrefix(p, "!")
+		matches, err := w.glob(strings.TrimPrefix(p, "!"))
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			dirs[m] = !exclude
+		}
+	}
+	var pkgs []WorkspacePackage
+	for dir, included := range dirs {
+		if !included {
+			continue
+		}
+		pjs, err := ReadPackageJSONIfExists(filepath.Join(w.Root, dir))
+		if err != nil {
+			return nil, err
+		}
+		if pjs == nil {
+			continue
+		}
+		pkgs = append(pkgs, WorkspacePackage{Name: pjs.Name, Dir: dir})
+	}
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].Dir < pkgs[j].Dir })
+	return pkgs, nil
+}
+
+// glob returns the directories relative to the workspace root matching a workspace pattern. A **
+// path segment matches any number of directories, node_modules directories are never matched.
+func (w *Workspace) glob(pattern string) ([]string, error) {
+	pattern = strings.TrimSuffix(filepath.Clean(pattern), "/")
+	if !strings.Contains(pattern, "**") {
+		matches, err := filepath.Glob(filepath.Join(w.Root, pattern))
+		if err != nil {
+			return nil, gcp.UserErrorf("invalid workspace pattern %q: %v", pattern, err)
+		}
+		var dirs []string
+		for _, m := range matches {
+			if fi, err := os.Stat(m); err == nil && fi.IsDir() && !strings.Contains(m, "node_modules") {
+				rel, err := filepath.Rel(w.Root, m)
+				if err != nil {
+					return nil, gcp.InternalErrorf("finding relative path of %s: %v", m, err)
+				}
+				dirs = append(dirs, rel)
+			}
+		}
+		return dirs, nil
+	}
+	var dirs []string
+	err := filepath.WalkDir(w.Root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || path == w.Root {
+			return nil
+		}
+		if d.Name() == "node_modules" || strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(w.Root, path)
+		if err != nil {
+			return err
+		}
+		if matchDoubleStar(strings.Split(pattern, "/"), strings.Split(rel, "/")) {
+			dirs = append(dirs, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, gcp.InternalErrorf("matching workspace pattern %q: %v", pattern, err)
+	}
+	return dirs, nil
+}
+
+// matchDoubleStar matches path segments against pattern segments, where a ** segment matches any
+// number of path segments.
+func matchDoubleStar(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(path); i++ {
+			if matchDoubleStar(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 {
+		return false
+	}
+	if ok, err := filepath.Match(pattern[0], path[0]); err != nil || !ok {
+		return false
+	}
+	return matchDoubleStar(pattern[1:], path[1:])
+}
+
+// Package returns the workspace package named buildable, or located in the buildable directory
+// relative to the workspace root.
+func (w *Workspace) Package(buildable string) (*WorkspacePackage, error) {
+	pkgs, err := w.Packages()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Clean(buildable)
+	for _, p := range pkgs {
+		if p.Name == buildable || p.Dir == dir {
+			return &p, nil
+		}
+	}
+	return nil, gcp.UserErrorf("%s=%q does not match the name or directory of a workspace package", env.Buildable, buildable)
+}
+
+// Dependencies returns the package and the workspace packages it depends on, directly or through
+// other workspace packages, sorted by directory.
+func (w *Workspace) Dependencies(pkg *WorkspacePackage) ([]WorkspacePackage, error) {
+	pkgs, err := w.Packages()
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string]WorkspacePackage{}
+	for _, p := range pkgs {
+		byName[p.Name] = p
+	}
+	seen := map[string]bool{pkg.Dir: true}
+	queue := []WorkspacePackage{*pkg}
+	var deps []WorkspacePackage
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		deps = append(deps, p)
+		pjs, err := ReadPackageJSONIfExists(filepath.Join(w.Root, p.Dir))
+		if err != nil {
+			return nil, err
+		}
+		if pjs == nil {
+			continue
+		}
+		for _, m := range []map[string]string{pjs.Dependencies, pjs.DevDependencies} {
+			for name := range m {
+				d, ok := byName[name]
+				if !ok || seen[d.Dir] {
+					continue
+				}
+				seen[d.Dir] = true
+				queue = append(queue, d)
+			}
+		}
+	}
+	sort.Slice(deps, func(i, j int) bool { return deps[i].Dir < deps[j].Dir })
+	return deps, nil
+}
+
+// RemoveUnusedWorkspacePackages removes the workspace packages that are not in keep from the
+// workspace root, so they are not part of the launch image.
+func RemoveUnusedWorkspacePackages(ctx *gcp.Context, ws *Workspace, keep []WorkspacePackage) error {
+	pkgs, err := ws.Packages()
+	if err != nil {
+		return err
+	}
+	for _, p := range pkgs {
+		if p.Dir == "." || containsPackage(keep, p.Dir) {
+			continue
+		}
+		ctx.Debugf("Removing unused workspace package %s in %s", p.Name, p.Dir)
+		if err := ctx.RemoveAll(ws.Root, p.Dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// containsPackage returns true if a package of pkgs is in dir or one of its subdirectories.
+func containsPackage(pkgs []WorkspacePackage, dir string) bool {
+	for _, p := range pkgs {
+		if p.Dir == dir || strings.HasPrefix(p.Dir, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkspaceCacheFiles returns the files keying the cached node_modules directories of the
+// workspace packages: the lockfile and the package.json files of the root and the packages.
+func WorkspaceCacheFiles(ws *Workspace, pkgs []WorkspacePackage, lockfile string) []string {
+	files := []string{filepath.Join(ws.Root, lockfile), filepath.Join(ws.Root, "package.json")}
+	for _, p := range pkgs {
+		files = append(files, filepath.Join(ws.Root, p.Dir, "package.json"))
+	}
+	return files
+}
+
+// workspaceModulesDirs returns the node_modules directories of the workspace root and packages,
+// relative to the workspace root.
+func workspaceModulesDirs(pkgs []WorkspacePackage) []string {
+	dirs := []string{"node_modules"}
+	for _, p := range pkgs {
+		dirs = append(dirs, filepath.Join(p.Dir, "node_modules"))
+	}
+	return dirs
+}
+
+// SaveWorkspaceModules copies the node_modules directories of the workspace root and packages to
+// the cache directory with the given CopyModules strategy.
+func SaveWorkspaceModules(ctx *gcp.Context, strategy string, ws *Workspace, pkgs []WorkspacePackage, cacheDir string) error {
+	for _, m := range workspaceModulesDirs(pkgs) {
+		src := filepath.Join(ws.Root, m)
+		exists, err := ctx.FileExists(src)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		dst := filepath.Join(cacheDir, m)
+		if err := ctx.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		if _, err := CopyModules(ctx, strategy, src, dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestoreWorkspaceModules replaces the node_modules directories of the workspace root and packages
+// with the ones saved in the cache directory, with the given CopyModules strategy.
+func RestoreWorkspaceModules(ctx *gcp.Context, strategy string, ws *Workspace, pkgs []WorkspacePackage, cacheDir string) error {
+	for _, m := range workspaceModulesDirs(pkgs) {
+		src := filepath.Join(cacheDir, m)
+		exists, err := ctx.FileExists(src)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		dst := filepath.Join(ws.Root, m)
+		if err := ctx.RemoveAll(dst); err != nil {
+			return err
+		}
+		if _, err := CopyModules(ctx, strategy, src, dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BuildableWorkspacePackage returns the application root workspace and the workspace package
+// selected with GOOGLE_BUILDABLE. It returns nil unless the build opted in with
+// GOOGLE_NODE_WORKSPACE_BUILD. Firebase App Hosting selects the package with its own monorepo
+// support, so workspace builds are never used there.
+func BuildableWorkspacePackage(ctx *gcp.Context) (*Workspace, *WorkspacePackage, error) {
+	enabled, err := env.IsPresentAndTrue(WorkspaceBuildEnv)
+	if err != nil {
+		return nil, nil, gcp.UserErrorf("parsing %s: %w", WorkspaceBuildEnv, err)
+	}
+	if !enabled {
+		return nil, nil, nil
+	}
+	if env.IsFAH() {
+		ctx.Warnf("Ignoring %s, workspace builds are not supported on Firebase App Hosting.", WorkspaceBuildEnv)
+		return nil, nil, nil
+	}
+	buildable := os.Getenv(env.Buildable)
+	if buildable == "" {
+		return nil, nil, gcp.UserErrorf("%s requires %s to select the workspace package to build", WorkspaceBuildEnv, env.Buildable)
+	}
+	ws, err := ReadWorkspaceIfExists(ctx.ApplicationRoot())
+	if err != nil {
+		return nil, nil, err
+	}
+	if ws == nil {
+		return nil, nil, gcp.UserErrorf("%s is set but the application root is not a workspace root", WorkspaceBuildEnv)
+	}
+	pkg, err := ws.Package(buildable)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx.Logf("Building workspace package %s in %s", pkg.Name, pkg.Dir)
+	return ws, pkg, nil
+}
+
+// TurboPrune replaces the application root with the subset of the Turborepo workspace needed to
+// build the package: the package, the workspace packages it depends on and a pruned lockfile.
+func TurboPrune(ctx *gcp.Context, rootPJS *PackageJSON, pkg *WorkspacePackage) error {
+	tmp, err := ctx.TempDir(turboPruneDir)
+	if err != nil {
+		return err
+	}
+	out := filepath.Join(tmp, "out")
+	cmd := TurboCommand(rootPJS, "prune", pkg.Name, "--out-dir="+out)
+	if _, err := ctx.Exec(cmd, gcp.WithUserAttribution); err != nil {
+		return gcp.UserErrorf("pruning the workspace for %s: %w", pkg.Name, err)
+	}
+	entries, err := os.ReadDir(ctx.ApplicationRoot())
+	if err != nil {
+		return gcp.InternalErrorf("reading %s: %v", ctx.ApplicationRoot(), err)
+	}
+	for _, e := range entries {
+		if err := ctx.RemoveAll(ctx.ApplicationRoot(), e.Name()); err != nil {
+			return err
+		}
+	}
+	if _, err := ctx.Exec([]string{"cp", "--archive", out + "/.", ctx.ApplicationRoot()}, gcp.WithUserTimingAttribution); err != nil {
+		return err
+	}
+	return nil
+}
+
+// TurboCommand returns the command running Turborepo with the given arguments. The version of
+// turbo declared in the root package.json is used, if any.
+func TurboCommand(rootPJS *PackageJSON, args ...string) []string {
+	turbo := "turbo"
+	if rootPJS != nil {
+		if v := rootPJS.DevDependencies["turbo"]; v != "" {
+			turbo += "@" + v
+		} else if v := rootPJS.Dependencies["turbo"]; v != "" {
+			turbo += "@" + v
+		}
+	}
+	return append([]string{"npx", "--yes", turbo}, args...)
+}
+
+// WorkspaceInstallArgs returns the arguments restricting the dependencies installed by the
+// package manager to those of the workspace package, including other workspace packages it depends
+// on. Yarn installs a workspace package with yarn workspaces focus instead.
+func WorkspaceInstallArgs(pkgTool string, pkg *WorkspacePackage) []string {
+	switch pkgTool {
+	case "npm":
+		// The root package usually declares the build tools shared by the workspace.
+		return []string{"--workspace=" + pkg.Name, "--include-workspace-root"}
+	case "pnpm":
+		// The ... suffix selects the package and its dependencies.
+		return []string{"--filter=" + pkg.Name + "..."}
+	}
+	return nil
+}
+
+// WorkspaceRunCommand returns the command running a script of the workspace package.
+func WorkspaceRunCommand(pkgTool string, pkg *WorkspacePackage, script string) []string {
+	switch pkgTool {
+	case "pnpm":
+		return []string{"pnpm", "--filter=" + pkg.Name, "run", script}
+	case "yarn":
+		return []string{"yarn", "workspace", pkg.Name, "run", script}
+	}
+	return []string{pkgTool, "run", script, "--workspace=" + pkg.Name}
+}
+
+// WorkspaceBuildCommands returns the commands building the workspace package: the scripts of
+// GOOGLE_NODE_RUN_SCRIPTS, or its gcp-build or build script. In a Turborepo workspace the scripts
+// are run with turbo, which also runs them in the workspace packages it depends on.
+func WorkspaceBuildCommands(pkgTool string, ws *Workspace, rootPJS *PackageJSON, pkg *WorkspacePackage, pjs *PackageJSON) [][]string {
+	var scripts []string
+	if envScripts, ok := os.LookupEnv(GoogleNodeRunScriptsEnv); ok {
+		for _, s := range strings.Split(envScripts, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				scripts = append(scripts, s)
+			}
+		}
+	} else if HasGCPBuild(pjs) && strings.TrimSpace(pjs.Scripts[ScriptGCPBuild]) != "" {
+		scripts = []string{ScriptGCPBuild}
+	} else if HasScript(pjs, ScriptBuild) && strings.TrimSpace(pjs.Scripts[ScriptBuild]) != "" {
+		scripts = []string{ScriptBuild}
+	}
+	if len(scripts) == 0 {
+		return nil
+	}
+	if ws.Turbo {
+		args := append([]string{"run"}, scripts...)
+		return [][]string{TurboCommand(rootPJS, append(args, "--filter="+pkg.Name)...)}
+	}
+	var cmds [][]string
+	for _, s := range scripts {
+		cmds = append(cmds, WorkspaceRunCommand(pkgTool, pkg, s))
+	}
+	return cmds
+}
+
+// WorkspaceStartCommand returns the command starting the workspace package: its start script, or
+// its main module.
+func WorkspaceStartCommand(pkgTool string, pkg *WorkspacePackage, pjs *PackageJSON) []string {
+	if HasScript(pjs, "start") {
+		return WorkspaceRunCommand(pkgTool, pkg, "start")
+	}
+	main := "index.js"
+	if pjs != nil && pjs.Main != "" {
+		main = pjs.Main
+	}
+	return []string{"node", filepath.Join(pkg.Dir, main)}
+}
@@ -0,0 +1,461 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nodejs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestReadWorkspaceIfExists(t *testing.T) {
+	testCases := []struct {
+		name    string
+		files   map[string]string
+		want    *Workspace
+		wantErr bool
+	}{
+		{
+			name:  "not a workspace",
+			files: map[string]string{"package.json": `{"name": "app"}`},
+		},
+		{
+			name:  "npm workspaces",
+			files: map[string]string{"package.json": `{"workspaces": ["apps/*", "packages/*"]}`},
+			want:  &Workspace{Patterns: []string{"apps/*", "packages/*"}},
+		},
+		{
+			name:  "yarn classic workspaces",
+			files: map[string]string{"package.json": `{"workspaces": {"packages": ["apps/*"], "nohoist": ["**/react"]}}`},
+			want:  &Workspace{Patterns: []string{"apps/*"}},
+		},
+		{
+			name: "pnpm workspace",
+			files: map[string]string{
+				"package.json":        `{"name": "root"}`,
+				"pnpm-workspace.yaml": "packages:\n  - apps/*\n  - '!apps/legacy'\n",
+			},
+			want: &Workspace{Patterns: []string{"apps/*", "!apps/legacy"}},
+		},
+		{
+			name: "turborepo",
+			files: map[string]string{
+				"package.json": `{"workspaces": ["apps/*"]}`,
+				"turbo.json":   `{"tasks": {}}`,
+			},
+			want: &Workspace{Patterns: []string{"apps/*"}, Turbo: true},
+		},
+		{
+			name:    "invalid pnpm-workspace.yaml",
+			files:   map[string]string{"pnpm-workspace.yaml": "packages: ["},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeWorkspace(t, tc.files)
+
+			got, err := ReadWorkspaceIfExists(dir)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("ReadWorkspaceIfExists() got error: %v, want error? %v", err, tc.wantErr)
+			}
+			if tc.want != nil {
+				tc.want.Root = dir
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ReadWorkspaceIfExists() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWorkspacePackages(t *testing.T) {
+	dir := writeWorkspace(t, map[string]string{
+		"apps/web/package.json":                   `{"name": "web"}`,
+		"apps/api/package.json":                   `{"name": "api"}`,
+		"apps/legacy/package.json":                `{"name": "legacy"}`,
+		"apps/docs/README.md":                     "",
+		"packages/ui/button/package.json":         `{"name": "@acme/button"}`,
+		"packages/ui/node_modules/package.json":   `{"name": "dependency"}`,
+		"packages/config/eslint/package.json":     `{"name": "@acme/eslint-config"}`,
+		"packages/config/eslint/lib/package.json": `{"name": "nested"}`,
+	})
+	testCases := []struct {
+		name     string
+		patterns []string
+		want     []WorkspacePackage
+	}{
+		{
+			name:     "single level",
+			patterns: []string{"apps/*"},
+			want: []WorkspacePackage{
+				{Name: "api", Dir: "apps/api"},
+				{Name: "legacy", Dir: "apps/legacy"},
+				{Name: "web", Dir: "apps/web"},
+			},
+		},
+		{
+			name:     "exclusion",
+			patterns: []string{"apps/*", "!apps/legacy"},
+			want: []WorkspacePackage{
+				{Name: "api", Dir: "apps/api"},
+				{Name: "web", Dir: "apps/web"},
+			},
+		},
+		{
+			name:     "any depth",
+			patterns: []string{"packages/**"},
+			want: []WorkspacePackage{
+				{Name: "@acme/eslint-config", Dir: "packages/config/eslint"},
+				{Name: "nested", Dir: "packages/config/eslint/lib"},
+				{Name: "@acme/button", Dir: "packages/ui/button"},
+			},
+		},
+		{
+			name:     "directory",
+			patterns: []string{"apps/web/"},
+			want:     []WorkspacePackage{{Name: "web", Dir: "apps/web"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ws := &Workspace{Root: dir, Patterns: tc.patterns}
+
+			got, err := ws.Packages()
+			if err != nil {
+				t.Fatalf("Packages() got error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Packages() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWorkspaceDependencies(t *testing.T) {
+	files := map[string]string{
+		"package.json":                 `{"name": "acme", "workspaces": ["apps/*", "packages/*"]}`,
+		"apps/web/package.json":        `{"name": "@acme/web", "dependencies": {"@acme/ui": "*", "react": "^18"}}`,
+		"apps/docs/package.json":       `{"name": "@acme/docs", "dependencies": {"@acme/ui": "*"}}`,
+		"packages/ui/package.json":     `{"name": "@acme/ui", "devDependencies": {"@acme/config": "*"}}`,
+		"packages/config/package.json": `{"name": "@acme/config", "dependencies": {"@acme/ui": "*"}}`,
+	}
+	ws := &Workspace{Root: writeWorkspace(t, files), Patterns: []string{"apps/*", "packages/*"}}
+
+	got, err := ws.Dependencies(&WorkspacePackage{Name: "@acme/web", Dir: "apps/web"})
+	if err != nil {
+		t.Fatalf("Dependencies() got error: %v", err)
+	}
+	want := []WorkspacePackage{
+		{Name: "@acme/web", Dir: "apps/web"},
+		{Name: "@acme/config", Dir: "packages/config"},
+		{Name: "@acme/ui", Dir: "packages/ui"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Dependencies() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRemoveUnusedWorkspacePackages(t *testing.T) {
+	files := map[string]string{
+		"package.json":                   `{"name": "acme", "workspaces": ["apps/*", "packages/**"]}`,
+		"apps/web/package.json":          `{"name": "@acme/web"}`,
+		"apps/docs/package.json":         `{"name": "@acme/docs"}`,
+		"packages/ui/package.json":       `{"name": "@acme/ui"}`,
+		"packages/ui/icons/package.json": `{"name": "@acme/icons"}`,
+	}
+	ws := &Workspace{Root: writeWorkspace(t, files), Patterns: []string{"apps/*", "packages/**"}}
+	keep := []WorkspacePackage{{Name: "@acme/web", Dir: "apps/web"}, {Name: "@acme/icons", Dir: "packages/ui/icons"}}
+
+	if err := RemoveUnusedWorkspacePackages(gcp.NewContext(), ws, keep); err != nil {
+		t.Fatalf("RemoveUnusedWorkspacePackages() got error: %v", err)
+	}
+	for dir, want := range map[string]bool{
+		"apps/web":          true,
+		"apps/docs":         false,
+		"packages/ui":       true, // contains a package to keep.
+		"packages/ui/icons": true,
+	} {
+		_, err := os.Stat(filepath.Join(ws.Root, dir))
+		if got := err == nil; got != want {
+			t.Errorf("%s exists = %v, want %v", dir, got, want)
+		}
+	}
+}
+
+func TestSaveAndRestoreWorkspaceModules(t *testing.T) {
+	files := map[string]string{
+		"package.json":                            `{"name": "acme", "workspaces": ["apps/*"]}`,
+		"node_modules/react/package.json":         `{"name": "react"}`,
+		"apps/web/package.json":                   `{"name": "@acme/web"}`,
+		"apps/web/node_modules/next/package.json": `{"name": "next"}`,
+		"apps/api/package.json":                   `{"name": "@acme/api"}`,
+	}
+	ws := &Workspace{Root: writeWorkspace(t, files), Patterns: []string{"apps/*"}}
+	deps := []WorkspacePackage{{Name: "@acme/web", Dir: "apps/web"}, {Name: "@acme/api", Dir: "apps/api"}}
+	cacheDir := filepath.Join(t.TempDir(), "layer")
+	ctx := gcp.NewContext()
+
+	if err := SaveWorkspaceModules(ctx, ModulesCopy, ws, deps, cacheDir); err != nil {
+		t.Fatalf("SaveWorkspaceModules() got error: %v", err)
+	}
+	for _, m := range []string{"node_modules", "apps/web/node_modules"} {
+		if err := os.RemoveAll(filepath.Join(ws.Root, m)); err != nil {
+			t.Fatalf("removing %s: %v", m, err)
+		}
+	}
+	// A stale node_modules directory is replaced by the cached one.
+	stale := filepath.Join(ws.Root, "apps/web/node_modules/left-pad/package.json")
+	if err := os.MkdirAll(filepath.Dir(stale), 0755); err != nil {
+		t.Fatalf("creating %s: %v", stale, err)
+	}
+	if err := RestoreWorkspaceModules(ctx, ModulesCopy, ws, deps, cacheDir); err != nil {
+		t.Fatalf("RestoreWorkspaceModules() got error: %v", err)
+	}
+
+	for _, f := range []string{"node_modules/react/package.json", "apps/web/node_modules/next/package.json"} {
+		if _, err := os.Stat(filepath.Join(ws.Root, f)); err != nil {
+			t.Errorf("%s was not restored: %v", f, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Dir(stale)); !os.IsNotExist(err) {
+		t.Errorf("stale %s was not removed, stat error: %v", filepath.Dir(stale), err)
+	}
+	if _, err := os.Stat(filepath.Join(ws.Root, "apps/api/node_modules")); !os.IsNotExist(err) {
+		t.Errorf("apps/api/node_modules was created, stat error: %v", err)
+	}
+}
+
+func TestBuildableWorkspacePackage(t *testing.T) {
+	testCases := []struct {
+		name      string
+		optIn     string
+		buildable string
+		platform  string
+		files     map[string]string
+		want      *WorkspacePackage
+		wantErr   bool
+	}{
+		{
+			name:      "package name",
+			optIn:     "true",
+			buildable: "@acme/web",
+			want:      &WorkspacePackage{Name: "@acme/web", Dir: "apps/web"},
+		},
+		{
+			name:      "package directory",
+			optIn:     "true",
+			buildable: "./apps/web/",
+			want:      &WorkspacePackage{Name: "@acme/web", Dir: "apps/web"},
+		},
+		{
+			name:      "not opted in",
+			buildable: "@acme/web",
+		},
+		{
+			name:      "opted out",
+			optIn:     "false",
+			buildable: "@acme/web",
+		},
+		{
+			name:      "invalid opt in",
+			optIn:     "yes please",
+			buildable: "@acme/web",
+			wantErr:   true,
+		},
+		{
+			name:      "app hosting",
+			optIn:     "true",
+			buildable: "@acme/web",
+			platform:  env.TargetPlatformFAH,
+		},
+		{
+			name:    "no buildable",
+			optIn:   "true",
+			wantErr: true,
+		},
+		{
+			name:      "not a workspace",
+			optIn:     "true",
+			buildable: "@acme/web",
+			files:     map[string]string{"package.json": `{"name": "app"}`},
+			wantErr:   true,
+		},
+		{
+			name:      "unknown package",
+			optIn:     "true",
+			buildable: "@acme/api",
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			files := tc.files
+			if files == nil {
+				files = map[string]string{
+					"package.json":          `{"name": "acme", "workspaces": ["apps/*"]}`,
+					"apps/web/package.json": `{"name": "@acme/web"}`,
+				}
+			}
+			dir := writeWorkspace(t, files)
+			t.Setenv(env.Buildable, tc.buildable)
+			if tc.optIn != "" {
+				t.Setenv(WorkspaceBuildEnv, tc.optIn)
+			}
+			t.Setenv(env.XGoogleTargetPlatform, tc.platform)
+
+			_, got, err := BuildableWorkspacePackage(gcp.NewContext(gcp.WithApplicationRoot(dir)))
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("BuildableWorkspacePackage() got error: %v, want error? %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("BuildableWorkspacePackage() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWorkspaceBuildCommands(t *testing.T) {
+	pkg := &WorkspacePackage{Name: "@acme/web", Dir: "apps/web"}
+	testCases := []struct {
+		name       string
+		pkgTool    string
+		turbo      bool
+		rootPJS    *PackageJSON
+		pjs        *PackageJSON
+		runScripts *string
+		want       [][]string
+	}{
+		{
+			name:    "npm build",
+			pkgTool: "npm",
+			pjs:     &PackageJSON{Scripts: map[string]string{"build": "next build"}},
+			want:    [][]string{{"npm", "run", "build", "--workspace=@acme/web"}},
+		},
+		{
+			name:    "pnpm gcp-build",
+			pkgTool: "pnpm",
+			pjs:     &PackageJSON{Scripts: map[string]string{"build": "next build", "gcp-build": "next build --debug"}},
+			want:    [][]string{{"pnpm", "--filter=@acme/web", "run", "gcp-build"}},
+		},
+		{
+			name:       "yarn GOOGLE_NODE_RUN_SCRIPTS",
+			pkgTool:    "yarn",
+			pjs:        &PackageJSON{Scripts: map[string]string{"build": "next build"}},
+			runScripts: stringPtr("lint, build"),
+			want: [][]string{
+				{"yarn", "workspace", "@acme/web", "run", "lint"},
+				{"yarn", "workspace", "@acme/web", "run", "build"},
+			},
+		},
+		{
+			name:       "empty GOOGLE_NODE_RUN_SCRIPTS",
+			pkgTool:    "npm",
+			pjs:        &PackageJSON{Scripts: map[string]string{"build": "next build"}},
+			runScripts: stringPtr(""),
+		},
+		{
+			name:    "turbo",
+			pkgTool: "pnpm",
+			turbo:   true,
+			rootPJS: &PackageJSON{DevDependencies: map[string]string{"turbo": "^2.3.0"}},
+			pjs:     &PackageJSON{Scripts: map[string]string{"build": "next build"}},
+			want:    [][]string{{"npx", "--yes", "turbo@^2.3.0", "run", "build", "--filter=@acme/web"}},
+		},
+		{
+			name:    "no build script",
+			pkgTool: "npm",
+			pjs:     &PackageJSON{Scripts: map[string]string{"start": "node server.js"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.runScripts != nil {
+				t.Setenv(GoogleNodeRunScriptsEnv, *tc.runScripts)
+			}
+			ws := &Workspace{Turbo: tc.turbo}
+
+			got := WorkspaceBuildCommands(tc.pkgTool, ws, tc.rootPJS, pkg, tc.pjs)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("WorkspaceBuildCommands() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWorkspaceStartCommand(t *testing.T) {
+	pkg := &WorkspacePackage{Name: "@acme/api", Dir: "apps/api"}
+	testCases := []struct {
+		name    string
+		pkgTool string
+		pjs     *PackageJSON
+		want    []string
+	}{
+		{
+			name:    "start script",
+			pkgTool: "yarn",
+			pjs:     &PackageJSON{Scripts: map[string]string{"start": "node dist/main.js"}},
+			want:    []string{"yarn", "workspace", "@acme/api", "run", "start"},
+		},
+		{
+			name:    "main",
+			pkgTool: "npm",
+			pjs:     &PackageJSON{Main: "dist/main.js"},
+			want:    []string{"node", "apps/api/dist/main.js"},
+		},
+		{
+			name:    "default",
+			pkgTool: "npm",
+			pjs:     &PackageJSON{},
+			want:    []string{"node", "apps/api/index.js"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, WorkspaceStartCommand(tc.pkgTool, pkg, tc.pjs)); diff != "" {
+				t.Errorf("WorkspaceStartCommand() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func writeWorkspace(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("creating directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+	return dir
+}
@@ -17,6 +17,7 @@ package nodejs
 import (
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -38,14 +39,25 @@ var (
 const (
 	// YarnLock is the name of the yarn lock file.
 	YarnLock = "yarn.lock"
+	// yarnrc is the name of the configuration file of Yarn 2+.
+	yarnrc = ".yarnrc.yml"
+	// yarnPnPLoader is the name of the Plug'n'Play ESM loader generated by Yarn 3+.
+	yarnPnPLoader = ".pnp.loader.mjs"
 )
 
+// yarnPnPRuntimes are the names of the Plug'n'Play runtime generated by Yarn 3+ and Yarn 2.
+var yarnPnPRuntimes = []string{".pnp.cjs", ".pnp.js"}
+
 type yarn2Lock struct {
 	Metadata struct {
 		Version string `yaml:"version"`
 	} `yaml:"__metadata"`
 }
 
+type yarnrcConfig struct {
+	NodeLinker string `yaml:"nodeLinker"`
+}
+
 // UseFrozenLockfile returns an true if the environment supporte Yarn's --frozen-lockfile flag. This
 // is a hack to maintain backwards compatibility on App Engine Node.js 10 and older.
 func UseFrozenLockfile(ctx *gcp.Context) (bool, error) {
@@ -70,6 +82,61 @@ func IsYarn2(rootDir string) (bool, error) {
 	return manifest.Metadata.Version != "", nil
 }
 
+// IsYarnPnP returns true if the Yarn 2+ project in dir installs its dependencies with
+// Plug'n'Play, the default nodeLinker of Yarn 2+, rather than in node_modules.
+func IsYarnPnP(dir string) (bool, error) {
+	yarn2, err := IsYarn2(dir)
+	if err != nil || !yarn2 {
+		return false, err
+	}
+	linker := os.Getenv("YARN_NODE_LINKER")
+	if linker == "" {
+		data, err := ioutil.ReadFile(filepath.Join(dir, yarnrc))
+		if err != nil && !os.IsNotExist(err) {
+			return false, gcp.InternalErrorf("reading %s: %v", yarnrc, err)
+		}
+		var config yarnrcConfig
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			return false, gcp.UserErrorf("parsing %s: %v", yarnrc, err)
+		}
+		linker = config.NodeLinker
+	}
+	return linker == "" || linker == "pnp", nil
+}
+
+// YarnPnPNodeOptions returns the Node.js options loading the Plug'n'Play runtime of the project in
+// dir, the options `yarn run` adds to NODE_OPTIONS, so that processes started without Yarn resolve
+// the dependencies. It returns an empty string if the project was not installed with Plug'n'Play.
+func YarnPnPNodeOptions(dir string) (string, error) {
+	var opts []string
+	for _, r := range yarnPnPRuntimes {
+		p := filepath.Join(dir, r)
+		if _, err := os.Stat(p); err == nil {
+			opts = append(opts, "--require", p)
+			break
+		} else if !os.IsNotExist(err) {
+			return "", gcp.InternalErrorf("finding Plug'n'Play runtime %s: %v", p, err)
+		}
+	}
+	if len(opts) == 0 {
+		return "", nil
+	}
+	loader := filepath.Join(dir, yarnPnPLoader)
+	if _, err := os.Stat(loader); err == nil {
+		opts = append(opts, "--experimental-loader", (&url.URL{Scheme: "file", Path: loader}).String())
+	} else if !os.IsNotExist(err) {
+		return "", gcp.InternalErrorf("finding Plug'n'Play loader %s: %v", loader, err)
+	}
+	return strings.Join(opts, " "), nil
+}
+
+// YarnPnPCacheEnv returns the environment variables installing the package archives of a
+// Plug'n'Play project in cacheDir. The archives are loaded at launch time, so cacheDir must be in
+// a launch layer. The global cache of Yarn is only used as a mirror the archives are copied from.
+func YarnPnPCacheEnv(cacheDir string) []string {
+	return []string{"YARN_ENABLE_GLOBAL_CACHE=false", "YARN_CACHE_FOLDER=" + cacheDir}
+}
+
 // HasYarnWorkspacePlugin returns true if this project has Yarn2's workspaces plugin installed.
 func HasYarnWorkspacePlugin(ctx *gcp.Context) (bool, error) {
 	res, err := ctx.Exec([]string{"yarn", "plugin", "runtime"})
@@ -98,14 +165,14 @@ func detectYarnVersion(pjs *PackageJSON) (string, error) {
 	if pjs.Engines.Yarn != "" {
 		requestedVersion = pjs.Engines.Yarn
 	} else {
-		packageManagerName, packageManagerVersion, err := parsePackageManager(pjs.PackageManager)
+		spec, err := parsePackageManager(pjs.PackageManager)
 		if err != nil {
 			return "", err
 		}
-		if packageManagerName != "yarn" {
-			return "", gcp.UserErrorf("yarn was detected but %s is set in the packageManager package.json field.", packageManagerName)
+		if spec.name != "yarn" {
+			return "", gcp.UserErrorf("yarn was detected but %s is set in the packageManager package.json field.", spec.name)
 		}
-		requestedVersion = packageManagerVersion
+		requestedVersion = spec.version
 	}
 	version, err := resolvePackageVersion("yarn", requestedVersion)
 	if err != nil {
@@ -116,37 +183,11 @@ func detectYarnVersion(pjs *PackageJSON) (string, error) {
 
 // InstallYarnLayer installs Yarn in the given layer if it is not already cached.
 func InstallYarnLayer(ctx *gcp.Context, yarnLayer *libcnb.Layer, pjs *PackageJSON) error {
-	layerName := yarnLayer.Name
 	version, err := detectYarnVersion(pjs)
 	if err != nil {
 		return err
 	}
-
-	// Check the metadata in the cache layer to determine if we need to proceed.
-	metaVersion := ctx.GetMetadata(yarnLayer, versionKey)
-	if version == metaVersion {
-		ctx.CacheHit(layerName)
-		ctx.Logf("Yarn cache hit: %q, %q, skipping installation.", version, metaVersion)
-	} else {
-		ctx.CacheMiss(layerName)
-		if err := ctx.ClearLayer(yarnLayer); err != nil {
-			return fmt.Errorf("clearing layer %q: %w", layerName, err)
-		}
-		// Download and install yarn in layer.
-		ctx.Logf("Installing Yarn v%s", version)
-		if err := InstallYarn(ctx, yarnLayer.Path, version); err != nil {
-			return err
-		}
-	}
-
-	// Store layer flags and metadata.
-	ctx.SetMetadata(yarnLayer, versionKey, version)
-	// We need to update the path here to ensure the version we just installed take precendence over
-	// anything pre-installed in the base image.
-	if err := ctx.Setenv("PATH", filepath.Join(yarnLayer.Path, "bin")+":"+os.Getenv("PATH")); err != nil {
-		return err
-	}
-	return nil
+	return installPackageManager(ctx, yarnLayer, "yarn", version, pjs, InstallYarn)
 }
 
 // InstallYarn downloads a given version of Yarn into the provided directory.
@@ -21,6 +21,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/GoogleCloudPlatform/buildpacks/internal/testserver"
@@ -157,6 +158,106 @@ __metadata:
 	}
 }
 
+func TestIsYarnPnP(t *testing.T) {
+	const yarn2Lock = "__metadata:\n  version: 8\n"
+	testCases := []struct {
+		name      string
+		files     map[string]string
+		linkerEnv string
+		want      bool
+	}{
+		{
+			name:  "Yarn1",
+			files: map[string]string{YarnLock: "# yarn lockfile v1\n"},
+		},
+		{
+			name:  "Yarn2 default linker",
+			files: map[string]string{YarnLock: yarn2Lock},
+			want:  true,
+		},
+		{
+			name: "Yarn2 pnp linker",
+			files: map[string]string{
+				YarnLock:      yarn2Lock,
+				".yarnrc.yml": "nodeLinker: pnp\nenableTelemetry: false\n",
+			},
+			want: true,
+		},
+		{
+			name: "Yarn2 node-modules linker",
+			files: map[string]string{
+				YarnLock:      yarn2Lock,
+				".yarnrc.yml": "nodeLinker: \"node-modules\"\n",
+			},
+		},
+		{
+			name: "Yarn2 linker env",
+			files: map[string]string{
+				YarnLock:      yarn2Lock,
+				".yarnrc.yml": "nodeLinker: pnp\n",
+			},
+			linkerEnv: "pnpm",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.linkerEnv != "" {
+				t.Setenv("YARN_NODE_LINKER", tc.linkerEnv)
+			}
+			dir := writeWorkspace(t, tc.files)
+
+			got, err := IsYarnPnP(dir)
+			if err != nil {
+				t.Fatalf("IsYarnPnP(%q) got error: %v", dir, err)
+			}
+			if got != tc.want {
+				t.Errorf("IsYarnPnP(%q) = %t, want %t", dir, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestYarnPnPNodeOptions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name:  "node_modules",
+			files: map[string]string{"node_modules/.yarn-state.yml": ""},
+		},
+		{
+			name:  "Yarn2",
+			files: map[string]string{".pnp.js": ""},
+			want:  "--require DIR/.pnp.js",
+		},
+		{
+			name: "Yarn3+ with ESM loader",
+			files: map[string]string{
+				".pnp.cjs":        "",
+				".pnp.loader.mjs": "",
+			},
+			want: "--require DIR/.pnp.cjs --experimental-loader file://DIR/.pnp.loader.mjs",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeWorkspace(t, tc.files)
+
+			got, err := YarnPnPNodeOptions(dir)
+			if err != nil {
+				t.Fatalf("YarnPnPNodeOptions(%q) got error: %v", dir, err)
+			}
+			if want := strings.ReplaceAll(tc.want, "DIR", dir); got != want {
+				t.Errorf("YarnPnPNodeOptions(%q) = %q, want %q", dir, got, want)
+			}
+		})
+	}
+}
+
 func TestInstallYarn(t *testing.T) {
 	testCases := []struct {
 		name       string
@@ -24,6 +24,7 @@ import (
 	"strings"
 
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/appengine"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/ar"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/cache"
 	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
 	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
@@ -140,6 +141,9 @@ func version(ctx *gcp.Context) (string, error) {
 
 // composerInstall runs `composer install` with the given flags.
 func composerInstall(ctx *gcp.Context, flags []string) error {
+	if err := ar.GenerateComposerAuth(ctx); err != nil {
+		return fmt.Errorf("generating Composer authentication: %w", err)
+	}
 	cmd := append([]string{"composer", "install"}, flags...)
 	if _, err := ctx.Exec(cmd, gcp.WithUserAttribution); err != nil {
 		return err
@@ -0,0 +1,181 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const (
+	// maxAppObjectDepth is the maximum directory depth searched for application objects.
+	maxAppObjectDepth = 3
+)
+
+var (
+	// appConstructors maps the calls that create an application object to the package that must be
+	// imported by the module for the call to be considered.
+	appConstructors = map[string]struct{ framework, pkg string }{
+		"Flask":                {"Flask", "flask"},
+		"FastAPI":              {"FastAPI", "fastapi"},
+		"Starlette":            {"Starlette", "starlette"},
+		"Quart":                {"Quart", "quart"},
+		"get_wsgi_application": {"Django", "django"},
+	}
+
+	// appAssignmentRegexp matches a module level assignment of an application object, with an
+	// optional type annotation and module qualifier, e.g. `api: FastAPI = fastapi.FastAPI(`.
+	appAssignmentRegexp = regexp.MustCompile(`(?m)^([A-Za-z_]\w*)\s*(?::[^=\n]+)?=\s*(?:[A-Za-z_][\w.]*\.)?(Flask|FastAPI|Starlette|Quart|get_wsgi_application)\s*\(`)
+	// importRegexp matches the top level package of import statements.
+	importRegexp = regexp.MustCompile(`(?m)^\s*(?:from\s+([A-Za-z_]\w*)[\w.]*\s+import\b|import\s+([A-Za-z_]\w*))`)
+	// identifierRegexp matches the directory names that can be part of a module path.
+	identifierRegexp = regexp.MustCompile(`^[A-Za-z_]\w*$`)
+
+	// skippedAppObjectDirs are directories that do not contain application sources.
+	skippedAppObjectDirs = map[string]bool{
+		"__pycache__":   true,
+		"node_modules":  true,
+		"site-packages": true,
+		"venv":          true,
+		"env":           true,
+		"test":          true,
+		"tests":         true,
+	}
+
+	// preferredAppObjectFiles are the conventional names of the file defining the application object,
+	// in order of preference.
+	preferredAppObjectFiles = []string{"main.py", "app.py", "wsgi.py", "asgi.py"}
+)
+
+// AppObject is an application object, such as a Flask or FastAPI instance, found in the
+// application sources.
+type AppObject struct {
+	// Module is the module defining the object, e.g. app.server.
+	Module string
+	// Variable is the name of the module variable bound to the object, e.g. api.
+	Variable string
+	// Framework is the web framework of the object, e.g. FastAPI.
+	Framework string
+	// File is the path of the module relative to the application root.
+	File string
+	// Line is the line of the assignment in File.
+	Line int
+}
+
+// Target returns the object in the module:variable form used by gunicorn and uvicorn.
+func (a AppObject) Target() string {
+	return a.Module + ":" + a.Variable
+}
+
+// String describes where the object was found, for use in logs.
+func (a AppObject) String() string {
+	return fmt.Sprintf("%s application %q in %s:%d", a.Framework, a.Variable, a.File, a.Line)
+}
+
+// FindAppObjects returns the application objects created at module level in the Python sources
+// under dir, most likely entrypoint first. Sources are analyzed statically, without importing them.
+func FindAppObjects(ctx *gcp.Context, dir string) ([]AppObject, error) {
+	var objs []AppObject
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path == dir {
+				return nil
+			}
+			name := d.Name()
+			if strings.HasPrefix(name, ".") || skippedAppObjectDirs[name] || !identifierRegexp.MatchString(name) ||
+				strings.Count(rel, string(filepath.Separator)) >= maxAppObjectDepth-1 {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".py" || !identifierRegexp.MatchString(strings.TrimSuffix(d.Name(), ".py")) {
+			return nil
+		}
+		content, err := ctx.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		objs = append(objs, appObjectsInSource(rel, content)...)
+		return nil
+	})
+	if err != nil {
+		return nil, gcp.InternalErrorf("finding application objects in %s: %w", dir, err)
+	}
+	sort.SliceStable(objs, func(i, j int) bool {
+		return appObjectLess(objs[i], objs[j])
+	})
+	return objs, nil
+}
+
+// appObjectsInSource returns the application objects created in the source of the module at the
+// given relative path.
+func appObjectsInSource(rel string, content []byte) []AppObject {
+	imported := map[string]bool{}
+	for _, m := range importRegexp.FindAllSubmatch(content, -1) {
+		imported[string(m[1])+string(m[2])] = true
+	}
+	module := strings.ReplaceAll(strings.TrimSuffix(filepath.ToSlash(rel), ".py"), "/", ".")
+	module = strings.TrimSuffix(module, ".__init__")
+
+	var objs []AppObject
+	for _, m := range appAssignmentRegexp.FindAllSubmatchIndex(content, -1) {
+		ctor := appConstructors[string(content[m[4]:m[5]])]
+		if !imported[ctor.pkg] {
+			continue
+		}
+		objs = append(objs, AppObject{
+			Module:    module,
+			Variable:  string(content[m[2]:m[3]]),
+			Framework: ctor.framework,
+			File:      filepath.ToSlash(rel),
+			Line:      bytes.Count(content[:m[0]], []byte("\n")) + 1,
+		})
+	}
+	return objs
+}
+
+// appObjectLess orders application objects by conventional file name, then by depth and path.
+func appObjectLess(a, b AppObject) bool {
+	if pa, pb := appFilePreference(a.File), appFilePreference(b.File); pa != pb {
+		return pa < pb
+	}
+	if da, db := strings.Count(a.File, "/"), strings.Count(b.File, "/"); da != db {
+		return da < db
+	}
+	return a.File < b.File
+}
+
+func appFilePreference(file string) int {
+	for i, name := range preferredAppObjectFiles {
+		if filepath.Base(file) == name {
+			return i
+		}
+	}
+	return len(preferredAppObjectFiles)
+}
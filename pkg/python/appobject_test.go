@@ -0,0 +1,120 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFindAppObjects(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		want  []AppObject
+	}{
+		{
+			name: "flask in main.py",
+			files: map[string]string{
+				"main.py": "from flask import Flask\n\napp = Flask(__name__)\n",
+			},
+			want: []AppObject{
+				{Module: "main", Variable: "app", Framework: "Flask", File: "main.py", Line: 3},
+			},
+		},
+		{
+			name: "fastapi in package with annotation",
+			files: map[string]string{
+				"app/__init__.py": "",
+				"app/server.py":   "import fastapi\n\n\napi: fastapi.FastAPI = fastapi.FastAPI(title='x')\n",
+			},
+			want: []AppObject{
+				{Module: "app.server", Variable: "api", Framework: "FastAPI", File: "app/server.py", Line: 4},
+			},
+		},
+		{
+			name: "package __init__",
+			files: map[string]string{
+				"service/__init__.py": "from quart import Quart\nservice = Quart(__name__)\n",
+			},
+			want: []AppObject{
+				{Module: "service", Variable: "service", Framework: "Quart", File: "service/__init__.py", Line: 2},
+			},
+		},
+		{
+			name: "django wsgi",
+			files: map[string]string{
+				"mysite/wsgi.py": "import os\nfrom django.core.wsgi import get_wsgi_application\n\napplication = get_wsgi_application()\n",
+			},
+			want: []AppObject{
+				{Module: "mysite.wsgi", Variable: "application", Framework: "Django", File: "mysite/wsgi.py", Line: 4},
+			},
+		},
+		{
+			name: "ranked by file name then depth",
+			files: map[string]string{
+				"api/routes.py": "from starlette.applications import Starlette\napp = Starlette()\n",
+				"server.py":     "from flask import Flask\nserver = Flask(__name__)\n",
+				"app.py":        "from flask import Flask\napplication = Flask(__name__)\n",
+			},
+			want: []AppObject{
+				{Module: "app", Variable: "application", Framework: "Flask", File: "app.py", Line: 2},
+				{Module: "server", Variable: "server", Framework: "Flask", File: "server.py", Line: 2},
+				{Module: "api.routes", Variable: "app", Framework: "Starlette", File: "api/routes.py", Line: 2},
+			},
+		},
+		{
+			name: "ignored sources",
+			files: map[string]string{
+				"main.py":                 "app = Flask(__name__)\n",
+				"factory.py":              "from flask import Flask\n\ndef create_app():\n    app = Flask(__name__)\n    return app\n",
+				"tests/conftest.py":       "from flask import Flask\napp = Flask(__name__)\n",
+				".venv/lib/flask/app.py":  "from flask import Flask\napp = Flask(__name__)\n",
+				"my-scripts/app.py":       "from flask import Flask\napp = Flask(__name__)\n",
+				"a/b/c/deep.py":           "from flask import Flask\napp = Flask(__name__)\n",
+				"README.md":               "app = Flask(__name__)\n",
+				"venv/site-packages/x.py": "from flask import Flask\napp = Flask(__name__)\n",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("creating directory for %s: %v", name, err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("writing %s: %v", name, err)
+				}
+			}
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(dir))
+
+			got, err := FindAppObjects(ctx, dir)
+			if err != nil {
+				t.Fatalf("FindAppObjects() got error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("FindAppObjects() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const (
+	// DjangoManagePy is the name of the Django command-line utility of a project.
+	DjangoManagePy = "manage.py"
+
+	// DjangoSettingsModuleEnv is the env var Django reads the settings module from.
+	DjangoSettingsModuleEnv = "DJANGO_SETTINGS_MODULE"
+
+	// DjangoCheckDeployEnv is the env var used to run `manage.py check --deploy` at build time.
+	// Example: `true`, `True`, `1` will fail the build on deployment check warnings.
+	DjangoCheckDeployEnv = "GOOGLE_DJANGO_CHECK_DEPLOY"
+
+	// DjangoSkipCollectStaticEnv is the env var used to skip `manage.py collectstatic`.
+	DjangoSkipCollectStaticEnv = "GOOGLE_DJANGO_SKIP_COLLECTSTATIC"
+)
+
+var (
+	// settingsModuleRegexp matches the default settings module set in manage.py, e.g.
+	// os.environ.setdefault("DJANGO_SETTINGS_MODULE", "mysite.settings").
+	settingsModuleRegexp  = regexp.MustCompile(`['"]DJANGO_SETTINGS_MODULE['"]\s*,\s*['"]([\w.]+)['"]`)
+	wsgiApplicationRegexp = regexp.MustCompile(`(?m)^WSGI_APPLICATION\s*=\s*['"]([\w.]+)['"]`)
+	asgiApplicationRegexp = regexp.MustCompile(`(?m)^ASGI_APPLICATION\s*=\s*['"]([\w.]+)['"]`)
+)
+
+// DjangoProject describes the layout of a Django project.
+type DjangoProject struct {
+	// SettingsModule is the module of the project settings, e.g. mysite.settings.
+	SettingsModule string
+	// WSGIApplication is the WSGI application in module:variable form, e.g. mysite.wsgi:application.
+	WSGIApplication string
+	// ASGIApplication is the ASGI application in module:variable form, e.g. mysite.asgi:application.
+	ASGIApplication string
+}
+
+// FindDjangoProject returns the Django project in the application root, or nil if the application
+// has no manage.py file or settings module.
+func FindDjangoProject(ctx *gcp.Context) (*DjangoProject, error) {
+	root := ctx.ApplicationRoot()
+	managePy := filepath.Join(root, DjangoManagePy)
+	managePyExists, err := ctx.FileExists(managePy)
+	if err != nil || !managePyExists {
+		return nil, err
+	}
+	settingsModule := os.Getenv(DjangoSettingsModuleEnv)
+	if settingsModule == "" {
+		content, err := ctx.ReadFile(managePy)
+		if err != nil {
+			return nil, err
+		}
+		m := settingsModuleRegexp.FindSubmatch(content)
+		if m == nil {
+			ctx.Debugf("No %s found in %s.", DjangoSettingsModuleEnv, DjangoManagePy)
+			return nil, nil
+		}
+		settingsModule = string(m[1])
+	}
+	settingsFile, err := moduleFile(ctx, root, settingsModule)
+	if err != nil || settingsFile == "" {
+		return nil, err
+	}
+
+	p := &DjangoProject{SettingsModule: settingsModule}
+	settings, err := ctx.ReadFile(settingsFile)
+	if err != nil {
+		return nil, err
+	}
+	if m := wsgiApplicationRegexp.FindSubmatch(settings); m != nil {
+		p.WSGIApplication = objectPath(string(m[1]))
+	}
+	if m := asgiApplicationRegexp.FindSubmatch(settings); m != nil {
+		p.ASGIApplication = objectPath(string(m[1]))
+	}
+
+	// Fall back to the wsgi.py and asgi.py files created by `django-admin startproject`, which are
+	// next to the settings module or in one of its parent packages.
+	parts := strings.Split(settingsModule, ".")
+	for i := len(parts) - 1; i > 0; i-- {
+		pkg := strings.Join(parts[:i], ".")
+		for _, app := range []struct {
+			module string
+			path   *string
+		}{{pkg + ".wsgi", &p.WSGIApplication}, {pkg + ".asgi", &p.ASGIApplication}} {
+			if *app.path != "" {
+				continue
+			}
+			f, err := moduleFile(ctx, root, app.module)
+			if err != nil {
+				return nil, err
+			}
+			if f != "" {
+				*app.path = app.module + ":application"
+			}
+		}
+	}
+	return p, nil
+}
+
+// moduleFile returns the source file of a Python module relative to the root, or "" if the module
+// is not found.
+func moduleFile(ctx *gcp.Context, root, module string) (string, error) {
+	base := filepath.Join(root, filepath.FromSlash(strings.ReplaceAll(module, ".", "/")))
+	for _, f := range []string{base + ".py", filepath.Join(base, "__init__.py")} {
+		exists, err := ctx.FileExists(f)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return f, nil
+		}
+	}
+	return "", nil
+}
+
+// objectPath converts a dotted object path, e.g. mysite.wsgi.application, to the module:variable
+// form used by gunicorn and uvicorn.
+func objectPath(dotted string) string {
+	i := strings.LastIndex(dotted, ".")
+	if i < 0 {
+		return dotted
+	}
+	return dotted[:i] + ":" + dotted[i+1:]
+}
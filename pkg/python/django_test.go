@@ -0,0 +1,130 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
+)
+
+const managePy = `#!/usr/bin/env python
+import os
+import sys
+
+def main():
+    os.environ.setdefault('DJANGO_SETTINGS_MODULE', 'mysite.settings')
+    from django.core.management import execute_from_command_line
+    execute_from_command_line(sys.argv)
+`
+
+func TestFindDjangoProject(t *testing.T) {
+	testCases := []struct {
+		name           string
+		files          map[string]string
+		settingsModule string
+		want           *DjangoProject
+	}{
+		{
+			name: "startproject layout",
+			files: map[string]string{
+				"manage.py":          managePy,
+				"mysite/__init__.py": "",
+				"mysite/settings.py": "WSGI_APPLICATION = 'mysite.wsgi.application'\n",
+				"mysite/wsgi.py":     "",
+				"mysite/asgi.py":     "",
+			},
+			want: &DjangoProject{
+				SettingsModule:  "mysite.settings",
+				WSGIApplication: "mysite.wsgi:application",
+				ASGIApplication: "mysite.asgi:application",
+			},
+		},
+		{
+			name: "settings package from env",
+			files: map[string]string{
+				"manage.py":                     managePy,
+				"config/settings/__init__.py":   "",
+				"config/settings/base.py":       "",
+				"config/settings/production.py": "from .base import *\n",
+				"config/wsgi.py":                "",
+				"mysite/settings.py":            "",
+			},
+			settingsModule: "config.settings.production",
+			want: &DjangoProject{
+				SettingsModule:  "config.settings.production",
+				WSGIApplication: "config.wsgi:application",
+			},
+		},
+		{
+			name: "custom application objects",
+			files: map[string]string{
+				"manage.py":          managePy,
+				"mysite/settings.py": "WSGI_APPLICATION = \"mysite.server.app\"\nASGI_APPLICATION = \"mysite.routing.application\"\n",
+			},
+			want: &DjangoProject{
+				SettingsModule:  "mysite.settings",
+				WSGIApplication: "mysite.server:app",
+				ASGIApplication: "mysite.routing:application",
+			},
+		},
+		{
+			name: "missing settings module",
+			files: map[string]string{
+				"manage.py": managePy,
+			},
+		},
+		{
+			name: "manage.py without settings",
+			files: map[string]string{
+				"manage.py": "print('hello')\n",
+			},
+		},
+		{
+			name: "no manage.py",
+			files: map[string]string{
+				"mysite/settings.py": "",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("creating directory for %s: %v", name, err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("writing %s: %v", name, err)
+				}
+			}
+			t.Setenv(DjangoSettingsModuleEnv, tc.settingsModule)
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(dir))
+
+			got, err := FindDjangoProject(ctx)
+			if err != nil {
+				t.Fatalf("FindDjangoProject() got error: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("FindDjangoProject() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/BurntSushi/toml"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const (
+	// Pipfile is the name of the Pipenv project file.
+	Pipfile = "Pipfile"
+	// PipfileLock is the name of the Pipenv lock file.
+	PipfileLock = "Pipfile.lock"
+
+	defaultPipenvIndexURL = "https://pypi.org/simple"
+)
+
+// pipfileSections are the sections of a Pipfile that are not package categories.
+var pipfileSections = map[string]bool{
+	"source":       true,
+	"packages":     true,
+	"dev-packages": true,
+	"requires":     true,
+	"scripts":      true,
+	"pipfile":      true,
+	"pipenv":       true,
+	"default":      true,
+	"develop":      true,
+}
+
+// pipfileLock represents the parts of a Pipfile.lock file used by the buildpacks.
+type pipfileLock struct {
+	Meta struct {
+		Hash struct {
+			SHA256 string `json:"sha256"`
+		} `json:"hash"`
+		Sources []pipenvSource `json:"sources"`
+	} `json:"_meta"`
+	Default map[string]pipenvPackage `json:"default"`
+}
+
+type pipenvSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type pipenvPackage struct {
+	Version string   `json:"version"`
+	Hashes  []string `json:"hashes"`
+	Markers string   `json:"markers"`
+	Extras  []string `json:"extras"`
+	Git     string   `json:"git"`
+	Ref     string   `json:"ref"`
+	Subdir  string   `json:"subdirectory"`
+	Path    string   `json:"path"`
+	File    string   `json:"file"`
+}
+
+// PipenvRequirements returns the default packages of Pipfile.lock in dir as the lines of a pip
+// requirements file. Packages are pinned to the locked hashes, so that pip installs exactly the
+// locked artifacts.
+func PipenvRequirements(ctx *gcp.Context, dir string) ([]string, error) {
+	lockPath := filepath.Join(dir, PipfileLock)
+	lockExists, err := ctx.FileExists(lockPath)
+	if err != nil {
+		return nil, err
+	}
+	if !lockExists {
+		return nil, gcp.UserErrorf("%s not found, run `pipenv lock` and include %s with your application source", PipfileLock, PipfileLock)
+	}
+	content, err := ctx.ReadFile(lockPath)
+	if err != nil {
+		return nil, err
+	}
+	var lock pipfileLock
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, gcp.UserErrorf("parsing %s: %v", PipfileLock, err)
+	}
+
+	pipfilePath := filepath.Join(dir, Pipfile)
+	pipfileExists, err := ctx.FileExists(pipfilePath)
+	if err != nil {
+		return nil, err
+	}
+	if pipfileExists {
+		pipfile, err := ctx.ReadFile(pipfilePath)
+		if err != nil {
+			return nil, err
+		}
+		hash, err := pipfileHash(pipfile)
+		if err != nil {
+			return nil, err
+		}
+		if hash != lock.Meta.Hash.SHA256 {
+			return nil, gcp.UserErrorf("%s is out of sync with %s, run `pipenv lock` to update it", PipfileLock, Pipfile)
+		}
+	}
+	return pipenvRequirements(ctx, lock), nil
+}
+
+// pipenvRequirements returns the requirements lines of the default packages of the lock file.
+func pipenvRequirements(ctx *gcp.Context, lock pipfileLock) []string {
+	// pip only supports hash checking when every requirement is a pinned artifact with hashes.
+	requireHashes := true
+	for name, pkg := range lock.Default {
+		if len(pkg.Hashes) == 0 {
+			ctx.Warnf("Package %q in %s has no hashes, installing without hash checking.", name, PipfileLock)
+			requireHashes = false
+		}
+	}
+
+	var lines, reqs []string
+	if requireHashes {
+		lines = append(lines, "--require-hashes")
+	}
+	for i, src := range lock.Meta.Sources {
+		switch {
+		case i == 0 && strings.TrimSuffix(src.URL, "/") == defaultPipenvIndexURL:
+			// Keep the index configured for pip, e.g. a mirror.
+		case i == 0:
+			lines = append(lines, "--index-url "+src.URL)
+		default:
+			lines = append(lines, "--extra-index-url "+src.URL)
+		}
+	}
+	for name, pkg := range lock.Default {
+		reqs = append(reqs, pipenvRequirement(name, pkg, requireHashes))
+	}
+	sort.Strings(reqs)
+	return append(lines, reqs...)
+}
+
+// pipenvRequirement returns the requirements line of a locked package.
+func pipenvRequirement(name string, pkg pipenvPackage, withHashes bool) string {
+	if len(pkg.Extras) > 0 {
+		name += "[" + strings.Join(pkg.Extras, ",") + "]"
+	}
+	var req string
+	switch {
+	case pkg.Git != "":
+		url := pkg.Git
+		if !strings.HasPrefix(url, "git+") {
+			url = "git+" + url
+		}
+		if pkg.Ref != "" {
+			url += "@" + pkg.Ref
+		}
+		if pkg.Subdir != "" {
+			url += "#subdirectory=" + pkg.Subdir
+		}
+		req = fmt.Sprintf("%s @ %s", name, url)
+	case pkg.File != "":
+		req = fmt.Sprintf("%s @ %s", name, pkg.File)
+	case pkg.Path != "":
+		req = pkg.Path
+	default:
+		req = name + pkg.Version
+	}
+	if pkg.Markers != "" {
+		req += " ; " + pkg.Markers
+	}
+	if withHashes {
+		for _, h := range pkg.Hashes {
+			req += " --hash=" + h
+		}
+	}
+	return req
+}
+
+// pipfileHash returns the hash of the Pipfile content that Pipenv records in Pipfile.lock.
+func pipfileHash(content []byte) (string, error) {
+	var pipfile map[string]any
+	if err := toml.Unmarshal(content, &pipfile); err != nil {
+		return "", gcp.UserErrorf("parsing %s: %v", Pipfile, err)
+	}
+	sources, ok := pipfile["source"]
+	if !ok {
+		sources = []any{map[string]any{"name": "pypi", "url": defaultPipenvIndexURL, "verify_ssl": true}}
+	}
+	data := map[string]any{
+		"_meta": map[string]any{
+			"sources":  sources,
+			"requires": valueOrEmpty(pipfile, "requires"),
+		},
+		"default": valueOrEmpty(pipfile, "packages"),
+		"develop": valueOrEmpty(pipfile, "dev-packages"),
+	}
+	for category, values := range pipfile {
+		if !pipfileSections[category] {
+			data[category] = values
+		}
+	}
+
+	// Match the output of Python's json.dumps(data, sort_keys=True, separators=(",", ":")).
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(data); err != nil {
+		return "", gcp.InternalErrorf("encoding %s: %w", Pipfile, err)
+	}
+	sum := sha256.Sum256([]byte(asciiJSON(strings.TrimSuffix(buf.String(), "\n"))))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func valueOrEmpty(m map[string]any, key string) any {
+	if v, ok := m[key]; ok {
+		return v
+	}
+	return map[string]any{}
+}
+
+// asciiJSON escapes the non-ASCII characters of a JSON document like Python's json module.
+func asciiJSON(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r < 0x80 {
+			b.WriteRune(r)
+			continue
+		}
+		for _, u := range utf16.Encode([]rune{r}) {
+			fmt.Fprintf(&b, `\u%04x`, u)
+		}
+	}
+	return b.String()
+}
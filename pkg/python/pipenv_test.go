@@ -0,0 +1,185 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
+)
+
+const pipfileContent = `[[source]]
+url = "https://pypi.org/simple"
+verify_ssl = true
+name = "pypi"
+
+[packages]
+flask = "==3.0.3"
+requests = {version = "*", extras = ["socks"]}
+gunicorn = "*"
+
+[dev-packages]
+pytest = "*"
+
+[requires]
+python_version = "3.12"
+`
+
+func TestPipfileHash(t *testing.T) {
+	testCases := []struct {
+		name    string
+		pipfile string
+		want    string
+	}{
+		{
+			name:    "sources and requires",
+			pipfile: pipfileContent,
+			want:    "36ac1150d00ea53234d03ad002a65e180fb52120f956e564e24b721c156ffc45",
+		},
+		{
+			name: "default source and custom category",
+			pipfile: `[packages]
+django = {version = ">=5.0", markers = "python_version >= '3.10'"}
+
+[scripts]
+serve = "gunicorn app:app"
+
+[docs]
+mkdocs = "*"
+
+[pipenv]
+allow_prereleases = false
+`,
+			want: "bb82ae998eeb7ca4dace434205ba656339098a7a8413d1288c6f9a59796865d9",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := pipfileHash([]byte(tc.pipfile))
+			if err != nil {
+				t.Fatalf("pipfileHash() got error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("pipfileHash() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPipenvRequirements(t *testing.T) {
+	testCases := []struct {
+		name      string
+		files     map[string]string
+		want      []string
+		wantError bool
+	}{
+		{
+			name: "hashes",
+			files: map[string]string{
+				Pipfile: pipfileContent,
+				PipfileLock: `{
+  "_meta": {
+    "hash": {"sha256": "36ac1150d00ea53234d03ad002a65e180fb52120f956e564e24b721c156ffc45"},
+    "sources": [{"name": "pypi", "url": "https://pypi.org/simple", "verify_ssl": true}]
+  },
+  "default": {
+    "flask": {"hashes": ["sha256:aaa", "sha256:bbb"], "version": "==3.0.3"},
+    "requests": {"extras": ["socks"], "hashes": ["sha256:ccc"], "version": "==2.32.3"},
+    "colorama": {"hashes": ["sha256:ddd"], "markers": "platform_system == 'Windows'", "version": "==0.4.6"}
+  },
+  "develop": {
+    "pytest": {"hashes": ["sha256:eee"], "version": "==8.2.0"}
+  }
+}`,
+			},
+			want: []string{
+				"--require-hashes",
+				"colorama==0.4.6 ; platform_system == 'Windows' --hash=sha256:ddd",
+				"flask==3.0.3 --hash=sha256:aaa --hash=sha256:bbb",
+				"requests[socks]==2.32.3 --hash=sha256:ccc",
+			},
+		},
+		{
+			name: "vcs dependency and private index",
+			files: map[string]string{
+				PipfileLock: `{
+  "_meta": {
+    "hash": {"sha256": "unused"},
+    "sources": [
+      {"name": "private", "url": "https://pypi.example.com/simple", "verify_ssl": true},
+      {"name": "pypi", "url": "https://pypi.org/simple", "verify_ssl": true}
+    ]
+  },
+  "default": {
+    "flask": {"hashes": ["sha256:aaa"], "index": "pypi", "version": "==3.0.3"},
+    "mylib": {"git": "https://github.com/example/mylib.git", "ref": "0123abcd"}
+  }
+}`,
+			},
+			want: []string{
+				"--index-url https://pypi.example.com/simple",
+				"--extra-index-url https://pypi.org/simple",
+				"flask==3.0.3",
+				"mylib @ git+https://github.com/example/mylib.git@0123abcd",
+			},
+		},
+		{
+			name: "out of sync",
+			files: map[string]string{
+				Pipfile:     pipfileContent + "django = \"*\"\n",
+				PipfileLock: `{"_meta": {"hash": {"sha256": "36ac1150d00ea53234d03ad002a65e180fb52120f956e564e24b721c156ffc45"}}, "default": {}}`,
+			},
+			wantError: true,
+		},
+		{
+			name: "missing lock",
+			files: map[string]string{
+				Pipfile: pipfileContent,
+			},
+			wantError: true,
+		},
+		{
+			name: "invalid lock",
+			files: map[string]string{
+				PipfileLock: `{"_meta": `,
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatalf("writing %s: %v", name, err)
+				}
+			}
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(dir))
+
+			got, err := PipenvRequirements(ctx, dir)
+			if tc.wantError != (err != nil) {
+				t.Fatalf("PipenvRequirements() got error: %v, want error? %v", err, tc.wantError)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("PipenvRequirements() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/buildermetrics"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb/v2"
+)
+
+const (
+	// PruneDependenciesEnv is the env var used to opt in to pruning files that are not needed at
+	// run time, such as test suites and type stubs, from the dependencies layer.
+	PruneDependenciesEnv = "GOOGLE_PYTHON_PRUNE_DEPENDENCIES"
+	// PruneExcludeEnv is the env var with a comma-separated list of globs of additional files and
+	// directories to prune from the dependencies layer. Globs without a slash match the base name
+	// of a path, other globs match the path relative to site-packages, e.g. "*.md" or
+	// "botocore/data/*/2015-*".
+	PruneExcludeEnv = "GOOGLE_PYTHON_PRUNE_EXCLUDE"
+
+	// verifyImportsScript imports the modules given as arguments and prints the ones which fail to
+	// import.
+	verifyImportsScript = `import importlib, sys
+failed = []
+for m in sys.argv[1:]:
+    try:
+        importlib.import_module(m)
+    except BaseException:
+        failed.append(m)
+print(" ".join(failed))`
+
+	// testDirName is the name of the test suite directories pruned by default when they are nested
+	// in a package. Top-level directories are kept since they can be importable packages, and "test"
+	// directories are kept since they are often part of the package API, e.g. django/test.
+	testDirName = "tests"
+)
+
+var (
+
+	// pycRegexp matches bytecode file names, e.g. module.cpython-312.opt-1.pyc, capturing the
+	// interpreter cache tag.
+	pycRegexp = regexp.MustCompile(`^[^.]+\.([^.]+)(\.opt-\d)?\.pyc$`)
+
+	// moduleNameRegexp matches valid top-level module names.
+	moduleNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// pruneOptions configures the pruning of a dependencies layer.
+type pruneOptions struct {
+	// globs are the user-provided globs of paths to prune in addition to the defaults.
+	globs []string
+}
+
+// pruneConfig returns the pruning options set in the environment, or nil if pruning is disabled.
+func pruneConfig() (*pruneOptions, error) {
+	enabled, err := env.IsPresentAndTrue(PruneDependenciesEnv)
+	if err != nil {
+		return nil, gcp.UserErrorf("%v", err)
+	}
+	if !enabled {
+		return nil, nil
+	}
+	opts := &pruneOptions{}
+	for _, g := range strings.Split(os.Getenv(PruneExcludeEnv), ",") {
+		g = strings.TrimSpace(g)
+		if g == "" {
+			continue
+		}
+		if _, err := filepath.Match(g, ""); err != nil {
+			return nil, gcp.UserErrorf("invalid glob %q in %s: %v", g, PruneExcludeEnv, err)
+		}
+		opts.globs = append(opts.globs, g)
+	}
+	return opts, nil
+}
+
+// cacheKey returns the strings identifying the pruning options in the dependencies cache key, so
+// that the dependencies are reinstalled when the options change.
+func (o *pruneOptions) cacheKey() []string {
+	if o == nil {
+		return nil
+	}
+	return append([]string{"prune"}, o.globs...)
+}
+
+// excluded returns true if a path relative to site-packages matches one of the user-provided
+// globs.
+func (o *pruneOptions) excluded(rel string) bool {
+	for _, g := range o.globs {
+		name := rel
+		if !strings.Contains(g, "/") {
+			name = filepath.Base(rel)
+		}
+		if ok, _ := filepath.Match(g, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// prunable returns true if the path relative to site-packages can be removed. cacheTag is the
+// bytecode cache tag of the interpreter, e.g. cpython-312.
+func (o *pruneOptions) prunable(rel string, isDir bool, cacheTag string) bool {
+	if o.excluded(rel) {
+		return true
+	}
+	name := filepath.Base(rel)
+	parent := filepath.Base(filepath.Dir(rel))
+	top := strings.SplitN(rel, string(filepath.Separator), 2)[0]
+	if isDir {
+		return name == testDirName && rel != name && !strings.HasSuffix(top, ".dist-info")
+	}
+	switch {
+	case strings.HasSuffix(name, ".pyi"):
+		return true
+	case parent == "__pycache__":
+		m := pycRegexp.FindStringSubmatch(name)
+		return m != nil && m[1] != cacheTag
+	}
+	return false
+}
+
+// pruneDependencies removes files that are not needed at run time from the site-packages of the
+// dependencies layer when pruning is enabled, then checks that the top-level modules installed in
+// the layer can still be imported. Modules which fail to import before pruning, e.g. because they
+// depend on the run time environment, are not checked. The number of bytes removed is reported in
+// the builder metrics.
+func pruneDependencies(ctx *gcp.Context, l *libcnb.Layer, opts *pruneOptions) error {
+	if opts == nil {
+		return nil
+	}
+	sitePackages, err := filepath.Glob(filepath.Join(l.Path, "lib", "python*", "site-packages"))
+	if err != nil {
+		return err
+	}
+	if len(sitePackages) == 0 {
+		ctx.Debugf("No site-packages found in %s, skipping pruning.", l.Path)
+		return nil
+	}
+	result, err := ctx.Exec([]string{"python3", "-c", "import sys; print(sys.implementation.cache_tag)"})
+	if err != nil {
+		return fmt.Errorf("getting bytecode cache tag: %w", err)
+	}
+	cacheTag := strings.TrimSpace(result.Stdout)
+
+	var modules []string
+	for _, dir := range sitePackages {
+		m, err := topLevelModules(dir)
+		if err != nil {
+			return err
+		}
+		modules = append(modules, m...)
+	}
+	failedBefore, err := failedImports(ctx, modules)
+	if err != nil {
+		return err
+	}
+
+	var count, size int64
+	for _, dir := range sitePackages {
+		c, s, err := prunePackages(dir, opts, cacheTag)
+		if err != nil {
+			return fmt.Errorf("pruning %s: %w", dir, err)
+		}
+		count += c
+		size += s
+	}
+	ctx.Logf("Pruned %d files and directories (%.1f MiB) from the dependencies.", count, float64(size)/(1<<20))
+	buildermetrics.GlobalBuilderMetrics().GetCounter(buildermetrics.PipPrunedBytesCounterID).Increment(size)
+
+	failedAfter, err := failedImports(ctx, modules)
+	if err != nil {
+		return err
+	}
+	var broken []string
+	for _, m := range failedAfter {
+		if !slices.Contains(failedBefore, m) {
+			broken = append(broken, m)
+		}
+	}
+	if len(broken) > 0 {
+		return gcp.UserErrorf("modules %s cannot be imported after pruning the dependencies, check the globs in %s", strings.Join(broken, " "), PruneExcludeEnv)
+	}
+	return nil
+}
+
+// failedImports imports the given modules and returns the ones which fail to import.
+func failedImports(ctx *gcp.Context, modules []string) ([]string, error) {
+	if len(modules) == 0 {
+		return nil, nil
+	}
+	// Bytecode was already compiled deterministically, importing must not write any.
+	result, err := ctx.Exec(append([]string{"python3", "-c", verifyImportsScript}, modules...), gcp.WithEnv("PYTHONDONTWRITEBYTECODE=1"))
+	if err != nil {
+		return nil, fmt.Errorf("verifying dependency imports: %w", err)
+	}
+	failed := strings.Fields(result.Stdout)
+	if len(failed) > 0 {
+		ctx.Debugf("Modules failing to import: %s", strings.Join(failed, " "))
+	}
+	return failed, nil
+}
+
+// prunePackages removes the prunable files and directories in site-packages dir, and returns the
+// number of removed paths and their total size in bytes.
+func prunePackages(dir string, opts *pruneOptions, cacheTag string) (int64, int64, error) {
+	var count, size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == dir {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if !opts.prunable(rel, d.IsDir(), cacheTag) {
+			return nil
+		}
+		s, err := diskUsage(path)
+		if err != nil {
+			return err
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+		count++
+		size += s
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return count, size, err
+}
+
+// diskUsage returns the total size in bytes of the regular files at path.
+func diskUsage(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
+
+// topLevelModules returns the sorted names of the packages and modules in site-packages dir.
+func topLevelModules(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var modules []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() {
+			if _, err := os.Stat(filepath.Join(dir, name, "__init__.py")); err != nil {
+				continue
+			}
+		} else {
+			if filepath.Ext(name) != ".py" {
+				continue
+			}
+			name = strings.TrimSuffix(name, ".py")
+		}
+		if moduleNameRegexp.MatchString(name) {
+			modules = append(modules, name)
+		}
+	}
+	sort.Strings(modules)
+	return modules, nil
+}
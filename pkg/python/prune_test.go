@@ -0,0 +1,196 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPruneConfig(t *testing.T) {
+	testCases := []struct {
+		name    string
+		prune   string
+		exclude string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "disabled",
+		},
+		{
+			name:  "enabled",
+			prune: "true",
+			want:  []string{"prune"},
+		},
+		{
+			name:    "enabled with globs",
+			prune:   "true",
+			exclude: "*.md, botocore/data/*/2015-*,",
+			want:    []string{"prune", "*.md", "botocore/data/*/2015-*"},
+		},
+		{
+			name:    "invalid glob",
+			prune:   "true",
+			exclude: "[",
+			wantErr: true,
+		},
+		{
+			name:    "invalid bool",
+			prune:   "yes please",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.prune != "" {
+				t.Setenv(PruneDependenciesEnv, tc.prune)
+			}
+			t.Setenv(PruneExcludeEnv, tc.exclude)
+
+			opts, err := pruneConfig()
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("pruneConfig() got error: %v, want error? %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, opts.cacheKey()); diff != "" {
+				t.Errorf("pruneConfig().cacheKey() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPrunePackages(t *testing.T) {
+	files := map[string]string{
+		"flask/__init__.py":                                         "",
+		"flask/__pycache__/__init__.cpython-312.pyc":                "pyc",
+		"flask/__pycache__/__init__.cpython-311.pyc":                "stale",
+		"flask/__pycache__/__init__.cpython-311.opt-1.pyc":          "stale",
+		"flask/py.typed":                                            "",
+		"flask/app.pyi":                                             "stub",
+		"flask/tests/__init__.py":                                   "",
+		"flask/tests/test_app.py":                                   "assert True",
+		"django/test/__init__.py":                                   "",
+		"django/test/client.py":                                     "",
+		"flask-3.0.0.dist-info/METADATA":                            "Name: flask",
+		"flask-3.0.0.dist-info/RECORD":                              "flask/__init__.py,,",
+		"flask-3.0.0.dist-info/licenses/tests/LICENSE":              "license",
+		"tests/__init__.py":                                         "",
+		"six.py":                                                    "",
+		"README.md":                                                 "readme",
+		"botocore/data/s3/2006-03-01/service-2.json":                "{}",
+		"botocore/data/s3/2015-03-01/service-2.json":                "{}",
+		"botocore/__init__.py":                                      "",
+		"_distutils_hack/__init__.py":                               "",
+		"distutils-precedence.pth":                                  "",
+		"my-namespace-package/module.py":                            "",
+		"google/cloud/storage/__init__.py":                          "",
+		"google/cloud/storage/__pycache__/__init__.cpython-312.pyc": "pyc",
+	}
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("creating directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+	opts := &pruneOptions{globs: []string{"*.md", "botocore/data/*/2015-*"}}
+
+	count, size, err := prunePackages(dir, opts, "cpython-312")
+	if err != nil {
+		t.Fatalf("prunePackages() got error: %v", err)
+	}
+
+	if want := int64(6); count != want {
+		t.Errorf("prunePackages() removed %d paths, want %d", count, want)
+	}
+	if want := int64(len("stale")*2 + len("stub") + len("assert True") + len("readme") + len("{}")); size != want {
+		t.Errorf("prunePackages() removed %d bytes, want %d", size, want)
+	}
+	var got []string
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		got = append(got, rel)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("walking %s: %v", dir, err)
+	}
+	sort.Strings(got)
+	want := []string{
+		"_distutils_hack/__init__.py",
+		"botocore/__init__.py",
+		"botocore/data/s3/2006-03-01/service-2.json",
+		"distutils-precedence.pth",
+		"django/test/__init__.py",
+		"django/test/client.py",
+		"flask-3.0.0.dist-info/METADATA",
+		"flask-3.0.0.dist-info/RECORD",
+		"flask-3.0.0.dist-info/licenses/tests/LICENSE",
+		"flask/__init__.py",
+		"flask/__pycache__/__init__.cpython-312.pyc",
+		"flask/py.typed",
+		"google/cloud/storage/__init__.py",
+		"google/cloud/storage/__pycache__/__init__.cpython-312.pyc",
+		"my-namespace-package/module.py",
+		"six.py",
+		"tests/__init__.py",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("prunePackages() left unexpected files (-want +got):\n%s", diff)
+	}
+}
+
+func TestTopLevelModules(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"flask/__init__.py",
+		"six.py",
+		"typing_extensions.py",
+		"_cffi_backend.cpython-312-x86_64-linux-gnu.so",
+		"flask-3.0.0.dist-info/METADATA",
+		"google/cloud/storage/__init__.py",
+		"bin/flask",
+		"distutils-precedence.pth",
+		"my-module.py",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("creating directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+
+	got, err := topLevelModules(dir)
+	if err != nil {
+		t.Fatalf("topLevelModules() got error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"flask", "six", "typing_extensions"}, got); diff != "" {
+		t.Errorf("topLevelModules() mismatch (-want +got):\n%s", diff)
+	}
+}
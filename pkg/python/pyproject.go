@@ -0,0 +1,370 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+const (
+	// PyprojectTOML is the name of the PEP 621 project file.
+	PyprojectTOML = "pyproject.toml"
+	// PoetryLock is the name of the Poetry lock file.
+	PoetryLock = "poetry.lock"
+)
+
+var (
+	// requirementNameRegexp matches the distribution name and extras of a PEP 508 requirement.
+	requirementNameRegexp = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[([^\]]*)\])?`)
+	// normalizeNameRegexp matches the separators that are equivalent in package names (PEP 503).
+	normalizeNameRegexp = regexp.MustCompile(`[-_.]+`)
+)
+
+// PyProject represents the parts of a pyproject.toml file used by the buildpacks.
+type PyProject struct {
+	Project struct {
+		Name         string   `toml:"name"`
+		Dependencies []string `toml:"dependencies"`
+	} `toml:"project"`
+	Tool struct {
+		// Poetry is nil if the project is not managed by Poetry.
+		Poetry *struct {
+			Dependencies map[string]any `toml:"dependencies"`
+		} `toml:"poetry"`
+	} `toml:"tool"`
+}
+
+// ReadPyProject returns the parsed pyproject.toml file in dir, or nil if there is none.
+func ReadPyProject(ctx *gcp.Context, dir string) (*PyProject, error) {
+	path := filepath.Join(dir, PyprojectTOML)
+	exists, err := ctx.FileExists(path)
+	if err != nil || !exists {
+		return nil, err
+	}
+	content, err := ctx.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p PyProject
+	if err := toml.Unmarshal(content, &p); err != nil {
+		return nil, gcp.UserErrorf("parsing %s: %v", PyprojectTOML, err)
+	}
+	return &p, nil
+}
+
+// IsPoetry returns true if the project is managed by Poetry.
+func (p *PyProject) IsPoetry() bool {
+	return p.Tool.Poetry != nil
+}
+
+// dependencies returns the direct runtime dependencies of the project keyed by normalized name.
+// Dependencies declared in both [project] and [tool.poetry] are merged.
+func (p *PyProject) dependencies() map[string]lockEdge {
+	deps := map[string]lockEdge{}
+	for _, req := range p.Project.Dependencies {
+		name, extras, marker, ok := parseRequirement(req)
+		if !ok {
+			continue
+		}
+		deps[name] = lockEdge{extras: extras, marker: marker}
+	}
+	if p.Tool.Poetry != nil {
+		for name, spec := range p.Tool.Poetry.Dependencies {
+			if strings.EqualFold(name, "python") {
+				continue
+			}
+			for _, e := range poetryEdges(spec) {
+				// Optional dependencies are only installed with the project extras.
+				if !e.optional {
+					deps[normalizeName(name)] = e.lockEdge
+				}
+			}
+		}
+	}
+	return deps
+}
+
+// PyProjectDependencyPresent checks if a given package is a direct dependency declared in the
+// pyproject.toml file of the application.
+func PyProjectDependencyPresent(ctx *gcp.Context, name string) (bool, error) {
+	p, err := ReadPyProject(ctx, ctx.ApplicationRoot())
+	if err != nil || p == nil {
+		return false, err
+	}
+	_, ok := p.dependencies()[normalizeName(name)]
+	return ok, nil
+}
+
+// ProjectDependencyPresent checks if a given package is a dependency of the application, either in
+// requirements.txt or in pyproject.toml.
+func ProjectDependencyPresent(ctx *gcp.Context, name string) (bool, error) {
+	present, err := PackagePresent(ctx, filepath.Join(ctx.ApplicationRoot(), "requirements.txt"), name)
+	if err != nil || present {
+		return present, err
+	}
+	return PyProjectDependencyPresent(ctx, name)
+}
+
+// poetryLock represents the parts of a poetry.lock file used by the buildpacks.
+type poetryLock struct {
+	Packages []poetryPackage `toml:"package"`
+}
+
+type poetryPackage struct {
+	Name         string              `toml:"name"`
+	Version      string              `toml:"version"`
+	Markers      any                 `toml:"markers"`
+	Dependencies map[string]any      `toml:"dependencies"`
+	Extras       map[string][]string `toml:"extras"`
+	Source       *struct {
+		Type              string `toml:"type"`
+		URL               string `toml:"url"`
+		Reference         string `toml:"reference"`
+		ResolvedReference string `toml:"resolved_reference"`
+		Subdirectory      string `toml:"subdirectory"`
+	} `toml:"source"`
+}
+
+// lockEdge is a dependency on a package with optional extras and environment marker.
+type lockEdge struct {
+	extras []string
+	marker string
+}
+
+// PyProjectRequirements returns the pinned runtime dependencies of the project as the lines of a
+// pip requirements file.
+//
+// For Poetry projects, the dependencies are resolved from poetry.lock, starting at the main
+// dependencies in pyproject.toml and excluding other dependency groups. Other projects have their
+// PEP 621 dependencies returned as is.
+func PyProjectRequirements(ctx *gcp.Context, dir string) ([]string, error) {
+	p, err := ReadPyProject(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, gcp.UserErrorf("%s not found", PyprojectTOML)
+	}
+	lockPath := filepath.Join(dir, PoetryLock)
+	lockExists, err := ctx.FileExists(lockPath)
+	if err != nil {
+		return nil, err
+	}
+	if lockExists {
+		content, err := ctx.ReadFile(lockPath)
+		if err != nil {
+			return nil, err
+		}
+		return poetryRequirements(p, content)
+	}
+	if p.IsPoetry() {
+		return nil, gcp.UserErrorf("%s not found, run `poetry lock` and include %s with your application source", PoetryLock, PoetryLock)
+	}
+	return p.Project.Dependencies, nil
+}
+
+// poetryRequirements returns the requirements lines of the packages in the poetry.lock content
+// that are reachable from the main dependencies of the project.
+func poetryRequirements(p *PyProject, lockContent []byte) ([]string, error) {
+	var lock poetryLock
+	if err := toml.Unmarshal(lockContent, &lock); err != nil {
+		return nil, gcp.UserErrorf("parsing %s: %v", PoetryLock, err)
+	}
+	packages := map[string]poetryPackage{}
+	for _, pkg := range lock.Packages {
+		packages[normalizeName(pkg.Name)] = pkg
+	}
+
+	// markers holds the markers of every edge leading to a package, an empty marker means the
+	// package is always required.
+	markers := map[string][]string{}
+	visited := map[string]bool{}
+	type item struct {
+		name string
+		edge lockEdge
+	}
+	var queue []item
+	for name, edge := range p.dependencies() {
+		queue = append(queue, item{name, edge})
+	}
+	for len(queue) > 0 {
+		it := queue[0]
+		queue = queue[1:]
+		pkg, ok := packages[it.name]
+		if !ok {
+			return nil, gcp.UserErrorf("package %q not found in %s, run `poetry lock` to update it", it.name, PoetryLock)
+		}
+		if !slices.Contains(markers[it.name], it.edge.marker) {
+			markers[it.name] = append(markers[it.name], it.edge.marker)
+		}
+		key := it.name + "[" + strings.Join(it.edge.extras, ",") + "]"
+		if visited[key] {
+			continue
+		}
+		visited[key] = true
+
+		for depName, spec := range pkg.Dependencies {
+			for _, e := range poetryEdges(spec) {
+				if e.optional && !requestedByExtras(pkg, depName, it.edge.extras) {
+					continue
+				}
+				queue = append(queue, item{normalizeName(depName), e.lockEdge})
+			}
+		}
+	}
+
+	var reqs, indexes []string
+	for name := range markers {
+		pkg := packages[name]
+		req := pinnedRequirement(pkg)
+		if m := packageMarker(pkg, markers[name]); m != "" {
+			req += " ; " + m
+		}
+		reqs = append(reqs, req)
+		if pkg.Source != nil && pkg.Source.Type == "legacy" && !slices.Contains(indexes, pkg.Source.URL) {
+			indexes = append(indexes, pkg.Source.URL)
+		}
+	}
+	sort.Strings(reqs)
+	sort.Strings(indexes)
+	var lines []string
+	for _, index := range indexes {
+		lines = append(lines, "--extra-index-url "+index)
+	}
+	return append(lines, reqs...), nil
+}
+
+// pinnedRequirement returns the requirement that installs exactly the locked package.
+func pinnedRequirement(pkg poetryPackage) string {
+	if pkg.Source == nil {
+		return fmt.Sprintf("%s==%s", pkg.Name, pkg.Version)
+	}
+	switch pkg.Source.Type {
+	case "git":
+		ref := pkg.Source.ResolvedReference
+		if ref == "" {
+			ref = pkg.Source.Reference
+		}
+		req := fmt.Sprintf("%s @ git+%s@%s", pkg.Name, pkg.Source.URL, ref)
+		if pkg.Source.Subdirectory != "" {
+			req += "#subdirectory=" + pkg.Source.Subdirectory
+		}
+		return req
+	case "url":
+		return fmt.Sprintf("%s @ %s", pkg.Name, pkg.Source.URL)
+	case "directory", "file":
+		return pkg.Source.URL
+	}
+	return fmt.Sprintf("%s==%s", pkg.Name, pkg.Version)
+}
+
+// packageMarker returns the environment marker under which a package is required. Lock files
+// written by Poetry 2 carry the marker of each package, older ones only carry the markers of each
+// dependency.
+func packageMarker(pkg poetryPackage, edgeMarkers []string) string {
+	switch m := pkg.Markers.(type) {
+	case string:
+		return m
+	case map[string]any:
+		if main, ok := m["main"].(string); ok {
+			return main
+		}
+	}
+	if slices.Contains(edgeMarkers, "") {
+		return ""
+	}
+	if len(edgeMarkers) == 1 {
+		return edgeMarkers[0]
+	}
+	var parts []string
+	for _, m := range edgeMarkers {
+		parts = append(parts, "("+m+")")
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, " or ")
+}
+
+// requestedByExtras returns true if the optional dependency is required by one of the extras.
+func requestedByExtras(pkg poetryPackage, depName string, extras []string) bool {
+	for _, extra := range extras {
+		for _, req := range pkg.Extras[extra] {
+			if name, _, _, ok := parseRequirement(req); ok && name == normalizeName(depName) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type poetryEdge struct {
+	lockEdge
+	optional bool
+}
+
+// poetryEdges returns the edges described by a Poetry dependency specification, which is either a
+// version constraint, a table or a list of tables (multiple constraints).
+func poetryEdges(spec any) []poetryEdge {
+	switch s := spec.(type) {
+	case map[string]any:
+		e := poetryEdge{}
+		e.marker, _ = s["markers"].(string)
+		e.optional, _ = s["optional"].(bool)
+		if extras, ok := s["extras"].([]any); ok {
+			for _, x := range extras {
+				if str, ok := x.(string); ok {
+					e.extras = append(e.extras, str)
+				}
+			}
+		}
+		return []poetryEdge{e}
+	case []any:
+		var edges []poetryEdge
+		for _, item := range s {
+			edges = append(edges, poetryEdges(item)...)
+		}
+		return edges
+	}
+	return []poetryEdge{{}}
+}
+
+// parseRequirement returns the normalized name, extras and environment marker of a PEP 508
+// requirement.
+func parseRequirement(req string) (string, []string, string, bool) {
+	spec, marker, _ := strings.Cut(req, ";")
+	m := requirementNameRegexp.FindStringSubmatch(spec)
+	if m == nil {
+		return "", nil, "", false
+	}
+	var extras []string
+	for _, x := range strings.Split(m[2], ",") {
+		if x = strings.TrimSpace(x); x != "" {
+			extras = append(extras, x)
+		}
+	}
+	return normalizeName(m[1]), extras, strings.TrimSpace(marker), true
+}
+
+// normalizeName returns the normalized form of a package name (PEP 503).
+func normalizeName(name string) string {
+	return strings.ToLower(normalizeNameRegexp.ReplaceAllString(name, "-"))
+}
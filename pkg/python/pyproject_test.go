@@ -0,0 +1,293 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/google/go-cmp/cmp"
+)
+
+const poetryPyProject = `
+[tool.poetry]
+name = "app"
+version = "0.1.0"
+
+[tool.poetry.dependencies]
+python = "^3.12"
+flask = "^3.0"
+uvicorn = {version = "^0.30", extras = ["standard"]}
+pywin32 = {version = "^306", markers = "sys_platform == 'win32'"}
+
+[tool.poetry.group.dev.dependencies]
+pytest = "^8.0"
+`
+
+const poetryLockContent = `
+[[package]]
+name = "flask"
+version = "3.0.3"
+
+[package.dependencies]
+click = ">=8.1.3"
+Werkzeug = ">=3.0.0"
+python-dotenv = {version = "*", optional = true}
+
+[package.extras]
+dotenv = ["python-dotenv"]
+
+[[package]]
+name = "click"
+version = "8.1.7"
+
+[package.dependencies]
+colorama = {version = "*", markers = "platform_system == \"Windows\""}
+
+[[package]]
+name = "colorama"
+version = "0.4.6"
+
+[[package]]
+name = "werkzeug"
+version = "3.0.3"
+
+[[package]]
+name = "python-dotenv"
+version = "1.0.1"
+
+[[package]]
+name = "uvicorn"
+version = "0.30.1"
+
+[package.dependencies]
+uvloop = {version = ">=0.14.0", optional = true, markers = "sys_platform != \"win32\""}
+
+[package.extras]
+standard = ["uvloop (>=0.14.0) ; sys_platform != \"win32\""]
+
+[[package]]
+name = "uvloop"
+version = "0.19.0"
+
+[[package]]
+name = "pywin32"
+version = "306"
+
+[[package]]
+name = "pytest"
+version = "8.2.0"
+
+[[package]]
+name = "internal-lib"
+version = "1.2.0"
+
+[package.source]
+type = "legacy"
+url = "https://pypi.example.com/simple"
+reference = "internal"
+`
+
+func TestPyProjectRequirements(t *testing.T) {
+	testCases := []struct {
+		name      string
+		files     map[string]string
+		want      []string
+		wantError bool
+	}{
+		{
+			name: "poetry lock",
+			files: map[string]string{
+				PyprojectTOML: poetryPyProject,
+				PoetryLock:    poetryLockContent,
+			},
+			want: []string{
+				"click==8.1.7",
+				"colorama==0.4.6 ; platform_system == \"Windows\"",
+				"flask==3.0.3",
+				"pywin32==306 ; sys_platform == 'win32'",
+				"uvicorn==0.30.1",
+				"uvloop==0.19.0 ; sys_platform != \"win32\"",
+				"werkzeug==3.0.3",
+			},
+		},
+		{
+			name: "poetry 2 project with legacy source and package markers",
+			files: map[string]string{
+				PyprojectTOML: `
+[project]
+name = "app"
+dependencies = ["internal-lib>=1.0", "click"]
+
+[tool.poetry]
+`,
+				PoetryLock: poetryLockContent + `
+[[package]]
+name = "tomli"
+version = "2.0.1"
+markers = "python_version < \"3.11\""
+`,
+			},
+			want: []string{
+				"--extra-index-url https://pypi.example.com/simple",
+				"click==8.1.7",
+				"colorama==0.4.6 ; platform_system == \"Windows\"",
+				"internal-lib==1.2.0",
+			},
+		},
+		{
+			name: "git source",
+			files: map[string]string{
+				PyprojectTOML: `
+[tool.poetry.dependencies]
+mylib = {git = "https://github.com/example/mylib.git", branch = "main"}
+`,
+				PoetryLock: `
+[[package]]
+name = "mylib"
+version = "0.1.0"
+
+[package.source]
+type = "git"
+url = "https://github.com/example/mylib.git"
+reference = "main"
+resolved_reference = "0123abcd"
+subdirectory = "python"
+`,
+			},
+			want: []string{"mylib @ git+https://github.com/example/mylib.git@0123abcd#subdirectory=python"},
+		},
+		{
+			name: "pep 621 without lock",
+			files: map[string]string{
+				PyprojectTOML: `
+[project]
+name = "app"
+dependencies = ["fastapi>=0.110", "uvicorn[standard]"]
+`,
+			},
+			want: []string{"fastapi>=0.110", "uvicorn[standard]"},
+		},
+		{
+			name: "poetry without lock",
+			files: map[string]string{
+				PyprojectTOML: poetryPyProject,
+			},
+			wantError: true,
+		},
+		{
+			name: "lock out of date",
+			files: map[string]string{
+				PyprojectTOML: "[tool.poetry.dependencies]\ndjango = \"^5.0\"\n",
+				PoetryLock:    poetryLockContent,
+			},
+			wantError: true,
+		},
+		{
+			name: "invalid pyproject",
+			files: map[string]string{
+				PyprojectTOML: "[project",
+			},
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatalf("writing %s: %v", name, err)
+				}
+			}
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(dir))
+
+			got, err := PyProjectRequirements(ctx, dir)
+			if tc.wantError != (err != nil) {
+				t.Fatalf("PyProjectRequirements() got error: %v, want error? %v", err, tc.wantError)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("PyProjectRequirements() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProjectDependencyPresent(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		pkg   string
+		want  bool
+	}{
+		{
+			name:  "requirements.txt",
+			files: map[string]string{"requirements.txt": "gunicorn==22.0.0\n"},
+			pkg:   "gunicorn",
+			want:  true,
+		},
+		{
+			name:  "pep 621 dependency",
+			files: map[string]string{PyprojectTOML: "[project]\ndependencies = [\"Gunicorn>=22; python_version >= '3.8'\"]\n"},
+			pkg:   "gunicorn",
+			want:  true,
+		},
+		{
+			name:  "poetry dependency",
+			files: map[string]string{PyprojectTOML: poetryPyProject},
+			pkg:   "uvicorn",
+			want:  true,
+		},
+		{
+			name:  "poetry dev dependency",
+			files: map[string]string{PyprojectTOML: poetryPyProject},
+			pkg:   "pytest",
+			want:  false,
+		},
+		{
+			name:  "similar name",
+			files: map[string]string{PyprojectTOML: "[project]\ndependencies = [\"gunicorn-logging\"]\n"},
+			pkg:   "gunicorn",
+			want:  false,
+		},
+		{
+			name: "no dependency files",
+			pkg:  "gunicorn",
+			want: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatalf("writing %s: %v", name, err)
+				}
+			}
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(dir))
+
+			got, err := ProjectDependencyPresent(ctx, tc.pkg)
+			if err != nil {
+				t.Fatalf("ProjectDependencyPresent(%q) got error: %v", tc.pkg, err)
+			}
+			if got != tc.want {
+				t.Errorf("ProjectDependencyPresent(%q) = %t, want %t", tc.pkg, got, tc.want)
+			}
+		})
+	}
+}
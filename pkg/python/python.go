@@ -184,7 +184,13 @@ func versionFromFile(ctx *gcp.Context, dir string) (string, error) {
 // PYTHONPATH. However, this caused issues with some packages as it would allow users to
 // accidentally override some builtin stdlib modules, e.g. typing, enum, etc., and cause both
 // build-time and run-time failures.
-func InstallRequirements(ctx *gcp.Context, l *libcnb.Layer, reqs ...string) error {
+//
+// The wheels of the dependencies are built in the wheelLayer cache layer first, and found there
+// by pip install, so that wheels with native extensions are not rebuilt when the dependencies
+// layer is invalidated. The wheels are then installed without the index, which saves resolving the
+// dependencies against the index twice. Requirements files with hashes or direct references are
+// installed from the index without the wheel cache.
+func InstallRequirements(ctx *gcp.Context, l, wheelLayer *libcnb.Layer, reqs ...string) error {
 	// Defensive check, this should not happen in practice.
 	if len(reqs) == 0 {
 		ctx.Debugf("No requirements.txt to install, clearing layer.")
@@ -194,42 +200,26 @@ func InstallRequirements(ctx *gcp.Context, l *libcnb.Layer, reqs ...string) erro
 		return nil
 	}
 
-	currentPythonVersion, err := Version(ctx)
+	prune, err := pruneConfig()
 	if err != nil {
 		return err
 	}
-	hash, cached, err := cache.HashAndCheck(ctx, l, dependencyHashKey,
-		cache.WithFiles(reqs...),
-		cache.WithStrings(currentPythonVersion))
-	if err != nil {
+	cached, err := prepareDependenciesLayer(ctx, l, reqs, prune.cacheKey()...)
+	if err != nil || cached {
 		return err
 	}
 
-	// Check cache expiration to pick up new versions of dependencies that are not pinned.
-	expired := cacheExpired(ctx, l)
-
-	if cached && !expired {
-		return nil
-	}
-
-	if expired {
-		ctx.Debugf("Dependencies cache expired, clearing layer.")
-	}
-
-	if err := ctx.ClearLayer(l); err != nil {
-		return fmt.Errorf("clearing layer %q: %w", l.Name, err)
-	}
-
-	ctx.Logf("Installing application dependencies.")
-	cache.Add(ctx, l, dependencyHashKey, hash)
-	// Update the layer metadata.
-	ctx.SetMetadata(l, pythonVersionKey, currentPythonVersion)
-	ctx.SetMetadata(l, expiryTimestampKey, time.Now().Add(expirationTime).Format(dateFormat))
-
 	if err := ar.GeneratePythonConfig(ctx); err != nil {
 		return fmt.Errorf("generating Artifact Registry credentials: %w", err)
 	}
 
+	vendorDir, isVendored := os.LookupEnv(VendorPipDepsEnv)
+	if !isVendored {
+		if err := prepareWheelCache(ctx, wheelLayer); err != nil {
+			return err
+		}
+	}
+
 	// History of the logic below:
 	//
 	// pip install --target has several subtle issues:
@@ -274,6 +264,7 @@ func InstallRequirements(ctx *gcp.Context, l *libcnb.Layer, reqs ...string) erro
 		}
 	}
 
+	wheelsBuilt := false
 	for _, req := range reqs {
 		cmd := []string{
 			"python3", "-m", "pip", "install",
@@ -287,10 +278,19 @@ func InstallRequirements(ctx *gcp.Context, l *libcnb.Layer, reqs ...string) erro
 			"--disable-pip-version-check", // If we were going to upgrade pip, we would have done it already in the runtime buildpack.
 			"--no-cache-dir",              // We used to save this to a layer, but it made builds slower because it includes http caching of pypi requests.
 		}
-		vendorDir, isVendored := os.LookupEnv(VendorPipDepsEnv)
 		if isVendored {
 			cmd = append(cmd, "--no-index", "--find-links", vendorDir)
 			buildermetrics.GlobalBuilderMetrics().GetCounter(buildermetrics.PipVendorDependenciesCounterID).Increment(1)
+		} else {
+			built, err := buildWheels(ctx, wheelLayer, req)
+			if err != nil {
+				return err
+			}
+			// pip wheel collected the wheels of every package of the requirements.
+			if built {
+				cmd = append(cmd, "--no-index", "--find-links", wheelDir(wheelLayer))
+				wheelsBuilt = true
+			}
 		}
 		if !virtualEnv {
 			cmd = append(cmd, "--user") // Install into user site-packages directory.
@@ -300,7 +300,58 @@ func InstallRequirements(ctx *gcp.Context, l *libcnb.Layer, reqs ...string) erro
 			return err
 		}
 	}
+	if wheelsBuilt {
+		if err := saveWheelCache(ctx, wheelLayer); err != nil {
+			return fmt.Errorf("saving wheel cache: %w", err)
+		}
+	}
+
+	if err := compileBytecode(ctx, l); err != nil {
+		return err
+	}
+	return pruneDependencies(ctx, l, prune)
+}
+
+// prepareDependenciesLayer checks if the dependencies installed in the layer are up to date with
+// the given files. If not, it clears the layer and records the new cache key in its metadata.
+// The extra strings are added to the cache key, e.g. to identify the installer.
+func prepareDependenciesLayer(ctx *gcp.Context, l *libcnb.Layer, files []string, extra ...string) (bool, error) {
+	currentPythonVersion, err := Version(ctx)
+	if err != nil {
+		return false, err
+	}
+	hash, cached, err := cache.HashAndCheck(ctx, l, dependencyHashKey,
+		cache.WithFiles(files...),
+		cache.WithStrings(append([]string{currentPythonVersion}, extra...)...))
+	if err != nil {
+		return false, err
+	}
+
+	// Check cache expiration to pick up new versions of dependencies that are not pinned.
+	expired := cacheExpired(ctx, l)
+
+	if cached && !expired {
+		return true, nil
+	}
+
+	if expired {
+		ctx.Debugf("Dependencies cache expired, clearing layer.")
+	}
+
+	if err := ctx.ClearLayer(l); err != nil {
+		return false, fmt.Errorf("clearing layer %q: %w", l.Name, err)
+	}
+
+	ctx.Logf("Installing application dependencies.")
+	cache.Add(ctx, l, dependencyHashKey, hash)
+	// Update the layer metadata.
+	ctx.SetMetadata(l, pythonVersionKey, currentPythonVersion)
+	ctx.SetMetadata(l, expiryTimestampKey, time.Now().Add(expirationTime).Format(dateFormat))
+	return false, nil
+}
 
+// compileBytecode compiles the Python files installed in the layer.
+func compileBytecode(ctx *gcp.Context, l *libcnb.Layer) error {
 	// Generate deterministic hash-based pycs (https://www.python.org/dev/peps/pep-0552/).
 	// Use the unchecked version to skip hash validation at run time (for faster startup).
 	result, cerr := ctx.Exec([]string{
@@ -0,0 +1,233 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/ar"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/buildermetrics"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/fetch"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/runtime"
+	"github.com/buildpacks/libcnb/v2"
+)
+
+const (
+	// UVLock is the name of the uv lock file.
+	UVLock = "uv.lock"
+
+	// PackageManagerEnv is the env var used to select the tool that installs the dependencies,
+	// either "pip" or "uv". Projects with a uv.lock file use uv by default.
+	PackageManagerEnv = "GOOGLE_PYTHON_PACKAGE_MANAGER"
+
+	// UVVersionEnv is the env var used to override the version of uv to install.
+	UVVersionEnv = "GOOGLE_UV_VERSION"
+
+	packageManagerPip = "pip"
+	packageManagerUV  = "uv"
+
+	// defaultUVVersion is the version of uv installed if UVVersionEnv is not set.
+	defaultUVVersion = "0.8.17"
+	// uvVersionKey is the metadata key used to store the uv version in the uv layer.
+	uvVersionKey = "version"
+	// uvArchKey is the metadata key used to store the uv architecture in the uv layer.
+	uvArchKey = "arch"
+)
+
+var (
+	// uvDownloadURL is the template used to generate a uv download URL.
+	uvDownloadURL = "https://github.com/astral-sh/uv/releases/download/%s/uv-%s-unknown-linux-gnu.tar.gz"
+)
+
+// UsesUV returns true if the dependencies of the application should be installed with uv.
+func UsesUV(ctx *gcp.Context) (bool, error) {
+	switch pm := os.Getenv(PackageManagerEnv); pm {
+	case packageManagerUV:
+		return true, nil
+	case packageManagerPip:
+		return false, nil
+	case "":
+		return ctx.FileExists(filepath.Join(ctx.ApplicationRoot(), UVLock))
+	default:
+		return false, gcp.UserErrorf("invalid %s %q, must be %q or %q", PackageManagerEnv, pm, packageManagerPip, packageManagerUV)
+	}
+}
+
+// InstallUV installs uv in the given layer if it is not already cached.
+func InstallUV(ctx *gcp.Context, uvLayer *libcnb.Layer) error {
+	installDir := filepath.Join(uvLayer.Path, "bin")
+	version := os.Getenv(UVVersionEnv)
+	if version == "" {
+		version = defaultUVVersion
+	}
+	arch := uvArch()
+	// Check the metadata in the cache layer to determine if we need to proceed.
+	metaVersion := ctx.GetMetadata(uvLayer, uvVersionKey)
+	metaArch := ctx.GetMetadata(uvLayer, uvArchKey)
+	if version == metaVersion && arch == metaArch {
+		ctx.CacheHit(uvLayer.Name)
+		ctx.Logf("uv cache hit: %q, skipping installation.", version)
+	} else {
+		ctx.CacheMiss(uvLayer.Name)
+		if err := ctx.ClearLayer(uvLayer); err != nil {
+			return fmt.Errorf("clearing layer %q: %w", uvLayer.Name, err)
+		}
+		ctx.Logf("Installing uv v%s", version)
+		if err := ctx.MkdirAll(installDir, 0755); err != nil {
+			return err
+		}
+		if err := fetch.Tarball(fmt.Sprintf(uvDownloadURL, version, arch), installDir, 1); err != nil {
+			return gcp.InternalErrorf("downloading uv: %w", err)
+		}
+	}
+
+	ctx.SetMetadata(uvLayer, uvVersionKey, version)
+	ctx.SetMetadata(uvLayer, uvArchKey, arch)
+	// We need to update the path here to ensure the version we just installed take precedence over
+	// anything pre-installed in the base image.
+	return ctx.Setenv("PATH", installDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// uvArch returns the architecture name used in the uv packages for the target architecture.
+func uvArch() string {
+	if runtime.TargetArch() == "arm64" {
+		return "aarch64"
+	}
+	return "x86_64"
+}
+
+// InstallRequirementsWithUV installs the dependencies of the application with uv into a virtual
+// env in the given layer. Projects with a uv.lock file are synced from the lock file, then the
+// given requirements files are installed in order.
+//
+// uv keeps downloaded and built packages in cacheLayer, so that a change to the dependencies only
+// downloads the packages that changed.
+func InstallRequirementsWithUV(ctx *gcp.Context, l, cacheLayer *libcnb.Layer, reqs ...string) error {
+	if os.Getenv(env.Runtime) == "python37" {
+		return gcp.UserErrorf("installing dependencies with uv requires Python 3.8 or later")
+	}
+	lockExists, err := ctx.FileExists(UVLock)
+	if err != nil {
+		return err
+	}
+	files := reqs
+	if lockExists {
+		files = append([]string{UVLock, PyprojectTOML}, reqs...)
+	}
+	if len(files) == 0 {
+		ctx.Debugf("No uv.lock or requirements.txt to install, clearing layer.")
+		if err := ctx.ClearLayer(l); err != nil {
+			return fmt.Errorf("clearing layer %q: %w", l.Name, err)
+		}
+		return nil
+	}
+
+	// The virtual env is used by later buildpacks and at run time whether or not it is cached.
+	if err := setUVEnv(ctx, l, cacheLayer); err != nil {
+		return err
+	}
+
+	prune, err := pruneConfig()
+	if err != nil {
+		return err
+	}
+	cached, err := prepareDependenciesLayer(ctx, l, files, append([]string{packageManagerUV}, prune.cacheKey()...)...)
+	if err != nil || cached {
+		return err
+	}
+
+	// uv authenticates to Artifact Registry with the generated .netrc file.
+	if err := ar.GeneratePythonConfig(ctx); err != nil {
+		return fmt.Errorf("generating Artifact Registry credentials: %w", err)
+	}
+
+	vendorDir, isVendored := os.LookupEnv(VendorPipDepsEnv)
+	if isVendored {
+		buildermetrics.GlobalBuilderMetrics().GetCounter(buildermetrics.PipVendorDependenciesCounterID).Increment(1)
+	}
+	if lockExists {
+		if _, err := ctx.Exec(uvSyncCommand(vendorDir), gcp.WithUserAttribution); err != nil {
+			return err
+		}
+	} else {
+		if _, err := ctx.Exec([]string{"uv", "venv", "--allow-existing", l.Path}, gcp.WithUserAttribution); err != nil {
+			return err
+		}
+	}
+	for _, req := range reqs {
+		if _, err := ctx.Exec(uvPipInstallCommand(req, vendorDir), gcp.WithUserAttribution); err != nil {
+			return err
+		}
+	}
+
+	// uv compiles timestamp-based pycs, which are invalidated by the normalized timestamps of the
+	// image, so the deterministic pycs are generated as for pip.
+	if err := compileBytecode(ctx, l); err != nil {
+		return err
+	}
+	return pruneDependencies(ctx, l, prune)
+}
+
+// setUVEnv points uv and the following build steps at the virtual env in the given layer and
+// the uv cache at cacheLayer.
+func setUVEnv(ctx *gcp.Context, l, cacheLayer *libcnb.Layer) error {
+	uvEnv := map[string]string{
+		"UV_CACHE_DIR": cacheLayer.Path,
+		// The cache and the environment are in different layers, which can be on different file
+		// systems, so packages are copied rather than hard linked.
+		"UV_LINK_MODE": "copy",
+		// Always use the Python runtime installed by the runtime buildpack.
+		"UV_PYTHON":           "python3",
+		"UV_PYTHON_DOWNLOADS": "never",
+		// uv sync installs into the project environment.
+		"UV_PROJECT_ENVIRONMENT": l.Path,
+		"VIRTUAL_ENV":            l.Path,
+		"PATH":                   filepath.Join(l.Path, "bin") + string(os.PathListSeparator) + os.Getenv("PATH"),
+	}
+	for k, v := range uvEnv {
+		if err := ctx.Setenv(k, v); err != nil {
+			return err
+		}
+	}
+	// The VIRTUAL_ENV variable is usually set by the virtual environment's activate script.
+	l.SharedEnvironment.Override("VIRTUAL_ENV", l.Path)
+	return nil
+}
+
+// uvSyncCommand returns the command that installs the locked dependencies of the project.
+func uvSyncCommand(vendorDir string) []string {
+	cmd := []string{
+		"uv", "sync",
+		"--frozen", // Install exactly the locked versions, fail if uv.lock is missing a dependency.
+		"--no-dev", // Development dependencies are not needed at run time.
+	}
+	if vendorDir != "" {
+		cmd = append(cmd, "--no-index", "--find-links", vendorDir)
+	}
+	return cmd
+}
+
+// uvPipInstallCommand returns the command that installs a requirements file.
+func uvPipInstallCommand(req, vendorDir string) []string {
+	cmd := []string{"uv", "pip", "install", "--requirement", req}
+	if vendorDir != "" {
+		cmd = append(cmd, "--no-index", "--find-links", vendorDir)
+	}
+	return cmd
+}
@@ -0,0 +1,163 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb/v2"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUsesUV(t *testing.T) {
+	testCases := []struct {
+		name           string
+		lockFile       bool
+		packageManager string
+		want           bool
+		wantError      bool
+	}{
+		{
+			name:     "uv lock file",
+			lockFile: true,
+			want:     true,
+		},
+		{
+			name: "no lock file",
+			want: false,
+		},
+		{
+			name:           "opt in without lock file",
+			packageManager: "uv",
+			want:           true,
+		},
+		{
+			name:           "opt out with lock file",
+			lockFile:       true,
+			packageManager: "pip",
+			want:           false,
+		},
+		{
+			name:           "invalid package manager",
+			packageManager: "poetry",
+			wantError:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tc.lockFile {
+				if err := os.WriteFile(filepath.Join(dir, UVLock), []byte("version = 1\n"), 0644); err != nil {
+					t.Fatalf("writing %s: %v", UVLock, err)
+				}
+			}
+			t.Setenv(PackageManagerEnv, tc.packageManager)
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(dir))
+
+			got, err := UsesUV(ctx)
+			if tc.wantError != (err != nil) {
+				t.Fatalf("UsesUV() got error: %v, want error? %v", err, tc.wantError)
+			}
+			if got != tc.want {
+				t.Errorf("UsesUV() = %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUVCommands(t *testing.T) {
+	testCases := []struct {
+		name      string
+		vendorDir string
+		wantSync  []string
+		wantPip   []string
+	}{
+		{
+			name:     "default",
+			wantSync: []string{"uv", "sync", "--frozen", "--no-dev"},
+			wantPip:  []string{"uv", "pip", "install", "--requirement", "requirements.txt"},
+		},
+		{
+			name:      "vendored",
+			vendorDir: "/workspace/vendor",
+			wantSync:  []string{"uv", "sync", "--frozen", "--no-dev", "--no-index", "--find-links", "/workspace/vendor"},
+			wantPip:   []string{"uv", "pip", "install", "--requirement", "requirements.txt", "--no-index", "--find-links", "/workspace/vendor"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.wantSync, uvSyncCommand(tc.vendorDir)); diff != "" {
+				t.Errorf("uvSyncCommand(%q) mismatch (-want +got):\n%s", tc.vendorDir, diff)
+			}
+			if diff := cmp.Diff(tc.wantPip, uvPipInstallCommand("requirements.txt", tc.vendorDir)); diff != "" {
+				t.Errorf("uvPipInstallCommand(%q) mismatch (-want +got):\n%s", tc.vendorDir, diff)
+			}
+		})
+	}
+}
+
+func TestSetUVEnv(t *testing.T) {
+	venv := t.TempDir()
+	uvCache := t.TempDir()
+	for _, k := range []string{"UV_CACHE_DIR", "UV_LINK_MODE", "UV_PYTHON", "UV_PYTHON_DOWNLOADS", "UV_PROJECT_ENVIRONMENT", "VIRTUAL_ENV"} {
+		t.Setenv(k, "")
+	}
+	t.Setenv("PATH", "/usr/bin")
+	l := &libcnb.Layer{Path: venv, SharedEnvironment: libcnb.Environment{}}
+
+	if err := setUVEnv(gcp.NewContext(), l, &libcnb.Layer{Path: uvCache}); err != nil {
+		t.Fatalf("setUVEnv() got error: %v", err)
+	}
+
+	want := map[string]string{
+		"UV_CACHE_DIR":           uvCache,
+		"UV_PROJECT_ENVIRONMENT": venv,
+		"VIRTUAL_ENV":            venv,
+		"PATH":                   filepath.Join(venv, "bin") + string(os.PathListSeparator) + "/usr/bin",
+	}
+	for k, v := range want {
+		if got := os.Getenv(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+	if got := l.SharedEnvironment["VIRTUAL_ENV.override"]; got != venv {
+		t.Errorf("shared VIRTUAL_ENV = %q, want %q", got, venv)
+	}
+}
+
+func TestUVArch(t *testing.T) {
+	testCases := []struct {
+		arch string
+		want string
+	}{
+		{arch: "amd64", want: "x86_64"},
+		{arch: "arm64", want: "aarch64"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.arch, func(t *testing.T) {
+			t.Setenv(libcnb.EnvTargetArch, tc.arch)
+
+			if got := uvArch(); got != tc.want {
+				t.Errorf("uvArch() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
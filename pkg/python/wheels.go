@@ -0,0 +1,183 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/cache"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/runtime"
+	"github.com/buildpacks/libcnb/v2"
+)
+
+const (
+	// wheelCacheKey is the metadata key of the platform the cached wheels were built for.
+	wheelCacheKey = "wheel_cache_key"
+	// wheelBuildDir is the directory of the wheel cache layer the wheels of the current build are
+	// collected in, before they replace the cached wheels.
+	wheelBuildDir = ".build"
+)
+
+// prepareWheelCache clears the wheel cache layer if its wheels were built for a different Python
+// version, stack or architecture than the current build.
+//
+// Unlike the dependencies layer, the wheel cache is not keyed by the requirements files: wheels are
+// immutable, so a wheel built for a pinned version can be reused by later builds on the same
+// platform, which avoids recompiling native extensions. The cache only keeps the wheels of the
+// last build, see saveWheelCache.
+func prepareWheelCache(ctx *gcp.Context, wl *libcnb.Layer) error {
+	pythonVersion, err := Version(ctx)
+	if err != nil {
+		return err
+	}
+	hash, cached, err := cache.HashAndCheck(ctx, wl, wheelCacheKey,
+		cache.WithStrings(pythonVersion, ctx.StackID(), runtime.TargetArch(),
+			os.Getenv(libcnb.EnvTargetDistroName), os.Getenv(libcnb.EnvTargetDistroVersion)))
+	if err != nil {
+		return err
+	}
+	if cached {
+		// Wheels collected by a failed build are not reused.
+		return ctx.RemoveAll(filepath.Join(wl.Path, wheelBuildDir))
+	}
+	ctx.Debugf("Wheel cache was built for a different platform, clearing layer.")
+	if err := ctx.ClearLayer(wl); err != nil {
+		return fmt.Errorf("clearing layer %q: %w", wl.Name, err)
+	}
+	cache.Add(ctx, wl, wheelCacheKey, hash)
+	return nil
+}
+
+// buildWheels builds or downloads the wheels of all the packages of a requirements file into the
+// build directory of the wheel cache layer, returned by wheelDir. Wheels already in the layer are
+// reused instead of being rebuilt. It returns false without building anything if the requirements
+// cannot be installed from the cache.
+func buildWheels(ctx *gcp.Context, wl *libcnb.Layer, req string) (bool, error) {
+	cacheable, err := wheelCacheable(ctx, req, map[string]bool{})
+	if err != nil || !cacheable {
+		return false, err
+	}
+	if _, err := ctx.Exec([]string{
+		"python3", "-m", "pip", "wheel",
+		"--requirement", req,
+		"--wheel-dir", wheelDir(wl),
+		"--find-links", wl.Path,
+		"--disable-pip-version-check",
+		"--no-cache-dir", // Only the built wheels are cached, not the http responses.
+	}, gcp.WithUserAttribution); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// wheelDir returns the directory the wheels of the current build are collected in.
+func wheelDir(wl *libcnb.Layer) string {
+	return filepath.Join(wl.Path, wheelBuildDir)
+}
+
+// saveWheelCache replaces the cached wheels with the wheels of the current build, so that the wheels
+// of dependencies which are no longer required do not accumulate in the layer.
+func saveWheelCache(ctx *gcp.Context, wl *libcnb.Layer) error {
+	cached, err := filepath.Glob(filepath.Join(wl.Path, "*.whl"))
+	if err != nil {
+		return err
+	}
+	for _, wheel := range cached {
+		if err := ctx.RemoveAll(wheel); err != nil {
+			return err
+		}
+	}
+	built, err := ctx.ReadDir(wheelDir(wl))
+	if err != nil {
+		return err
+	}
+	for _, wheel := range built {
+		if err := ctx.Rename(filepath.Join(wheelDir(wl), wheel.Name()), filepath.Join(wl.Path, wheel.Name())); err != nil {
+			return err
+		}
+	}
+	ctx.Debugf("Cached %d wheels, removed %d previously cached wheels.", len(built), len(cached))
+	return ctx.RemoveAll(wheelDir(wl))
+}
+
+// wheelCacheable returns false if a requirements file, or a file it includes, checks hashes or
+// has direct references. Wheels built locally from sdists never match the hashes of the published
+// artifacts, and VCS, URL, editable and local path requirements are built from their source by
+// pip install anyway.
+func wheelCacheable(ctx *gcp.Context, req string, seen map[string]bool) (bool, error) {
+	if seen[req] {
+		return true, nil
+	}
+	seen[req] = true
+	exists, err := ctx.FileExists(req)
+	if err != nil || !exists {
+		// pip reports the missing file.
+		return false, err
+	}
+	content, err := ctx.ReadFile(req)
+	if err != nil {
+		return false, err
+	}
+	content = bytes.ReplaceAll(content, []byte("\\\n"), []byte(" "))
+	for _, line := range strings.Split(string(content), "\n") {
+		if c := strings.Index(line, "#"); c == 0 || c > 0 && unicode.IsSpace(rune(line[c-1])) {
+			line = line[:c]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		opt, value, _ := strings.Cut(fields[0], "=")
+		if value == "" && len(fields) > 1 {
+			value = fields[1]
+		}
+		switch {
+		case opt == "-r" || opt == "--requirement" || opt == "-c" || opt == "--constraint":
+			if !filepath.IsAbs(value) {
+				value = filepath.Join(filepath.Dir(req), value)
+			}
+			if cacheable, err := wheelCacheable(ctx, value, seen); err != nil || !cacheable {
+				return false, err
+			}
+		case opt == "--require-hashes" || opt == "-e" || opt == "--editable" || strings.Contains(line, "--hash"):
+			ctx.Debugf("Not using the wheel cache for %s: %q", req, strings.TrimSpace(line))
+			return false, nil
+		case strings.HasPrefix(opt, "-"):
+			// Other options, e.g. --index-url, do not affect the wheel cache.
+		case isDirectReference(line):
+			ctx.Debugf("Not using the wheel cache for %s: %q", req, strings.TrimSpace(line))
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// isDirectReference returns true if a requirement is installed from a VCS, URL or local path rather
+// than from the index.
+func isDirectReference(req string) bool {
+	req = strings.TrimSpace(req)
+	if name, _, ok := strings.Cut(req, ";"); ok {
+		req = name
+	}
+	return strings.Contains(req, "@") || strings.Contains(req, "://") ||
+		strings.HasPrefix(req, ".") || strings.HasPrefix(req, "/") || strings.HasPrefix(req, "~") ||
+		strings.HasPrefix(req, "file:")
+}
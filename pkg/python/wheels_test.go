@@ -0,0 +1,222 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package python
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+	"github.com/buildpacks/libcnb/v2"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPrepareWheelCache(t *testing.T) {
+	testCases := []struct {
+		name     string
+		arch     string
+		wantKept bool
+	}{
+		{
+			name:     "same platform",
+			arch:     "amd64",
+			wantKept: true,
+		},
+		{
+			name: "architecture changed",
+			arch: "arm64",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := gcp.NewContext(gcp.WithStackID("google.22"), gcp.WithExecCmd(recordExec(t, nil)))
+			wl := &libcnb.Layer{Name: "pip-wheels", Path: t.TempDir(), Metadata: map[string]any{}}
+			t.Setenv(libcnb.EnvTargetArch, "amd64")
+			if err := prepareWheelCache(ctx, wl); err != nil {
+				t.Fatalf("prepareWheelCache() got error: %v", err)
+			}
+			wheel := filepath.Join(wl.Path, "numpy-2.0.0-cp312-cp312-linux_x86_64.whl")
+			if err := os.WriteFile(wheel, nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			t.Setenv(libcnb.EnvTargetArch, tc.arch)
+			if err := prepareWheelCache(ctx, wl); err != nil {
+				t.Fatalf("prepareWheelCache() got error: %v", err)
+			}
+			_, err := os.Stat(wheel)
+			if kept := err == nil; kept != tc.wantKept {
+				t.Errorf("cached wheel kept = %t, want %t", kept, tc.wantKept)
+			}
+		})
+	}
+}
+
+func TestBuildWheels(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		want  bool
+	}{
+		{
+			name: "index requirements",
+			files: map[string]string{
+				"requirements.txt": "--extra-index-url https://example.com/simple\nflask==3.0.0  # web\nnumpy>=2; python_version >= \"3.9\"\n",
+			},
+			want: true,
+		},
+		{
+			name: "included requirements",
+			files: map[string]string{
+				"requirements.txt":      "-r requirements/base.txt\ngunicorn\n",
+				"requirements/base.txt": "flask==3.0.0\n",
+			},
+			want: true,
+		},
+		{
+			name: "hashes",
+			files: map[string]string{
+				"requirements.txt": "flask==3.0.0 \\\n    --hash=sha256:00\n",
+			},
+		},
+		{
+			name: "require hashes",
+			files: map[string]string{
+				"requirements.txt": "--require-hashes\nflask==3.0.0\n",
+			},
+		},
+		{
+			name: "hashes in included requirements",
+			files: map[string]string{
+				"requirements.txt":      "-r requirements/lock.txt\n",
+				"requirements/lock.txt": "flask==3.0.0 --hash=sha256:00\n",
+			},
+		},
+		{
+			name: "VCS requirement",
+			files: map[string]string{
+				"requirements.txt": "mylib @ git+https://github.com/example/mylib@v1.0.0\n",
+			},
+		},
+		{
+			name: "URL requirement",
+			files: map[string]string{
+				"requirements.txt": "https://example.com/mylib-1.0.0.tar.gz\n",
+			},
+		},
+		{
+			name: "editable requirement",
+			files: map[string]string{
+				"requirements.txt": "-e ./libs/mylib\n",
+			},
+		},
+		{
+			name: "local path requirement",
+			files: map[string]string{
+				"requirements.txt": "./libs/mylib\n",
+			},
+		},
+		{
+			name: "pyproject directory source",
+			files: map[string]string{
+				"requirements.txt": "mylib @ file:///workspace/libs/mylib\n",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var cmds []string
+			ctx := gcp.NewContext(gcp.WithApplicationRoot(dir), gcp.WithExecCmd(recordExec(t, &cmds)))
+			wl := &libcnb.Layer{Name: "pip-wheels", Path: t.TempDir(), Metadata: map[string]any{}}
+
+			got, err := buildWheels(ctx, wl, filepath.Join(dir, "requirements.txt"))
+			if err != nil {
+				t.Fatalf("buildWheels() got error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("buildWheels() = %t, want %t", got, tc.want)
+			}
+			if built := len(cmds) > 0; built != tc.want {
+				t.Errorf("buildWheels() ran %q, want pip wheel run = %t", cmds, tc.want)
+			}
+		})
+	}
+}
+
+func TestSaveWheelCache(t *testing.T) {
+	wl := &libcnb.Layer{Name: "pip-wheels", Path: t.TempDir(), Metadata: map[string]any{}}
+	for _, wheel := range []string{
+		"numpy-1.26.0-cp312-cp312-linux_x86_64.whl",
+		"flask-3.0.0-py3-none-any.whl",
+		filepath.Join(wheelBuildDir, "numpy-2.0.0-cp312-cp312-linux_x86_64.whl"),
+		filepath.Join(wheelBuildDir, "flask-3.0.0-py3-none-any.whl"),
+	} {
+		path := filepath.Join(wl.Path, wheel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := saveWheelCache(gcp.NewContext(), wl); err != nil {
+		t.Fatalf("saveWheelCache() got error: %v", err)
+	}
+
+	entries, err := os.ReadDir(wl.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+	want := []string{"flask-3.0.0-py3-none-any.whl", "numpy-2.0.0-cp312-cp312-linux_x86_64.whl"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("saveWheelCache() cached wheels diff (-want +got):\n%s", diff)
+	}
+}
+
+// recordExec returns an exec func recording the commands in cmds, if not nil, and running them as
+// no-ops, except python3 --version which reports Python 3.12.1.
+func recordExec(t *testing.T, cmds *[]string) func(string, ...string) *exec.Cmd {
+	t.Helper()
+	return func(name string, args ...string) *exec.Cmd {
+		cmd := strings.Join(append([]string{name}, args...), " ")
+		if cmd == "python3 --version" {
+			return exec.Command("echo", "Python 3.12.1")
+		}
+		if cmds != nil {
+			*cmds = append(*cmds, cmd)
+		}
+		return exec.Command("true")
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	goruntime "runtime"
 	"slices"
 	"strings"
 
@@ -32,22 +33,14 @@ import (
 )
 
 var (
-	dartSdkURL         = "https://storage.googleapis.com/dart-archive/channels/stable/release/%s/sdk/dartsdk-linux-x64-release.zip"
-	flutterSdkURL      = "https://storage.googleapis.com/flutter_infra_release/releases/%s"
-	googleTarballURL   = "https://dl.google.com/runtimes/%s/%[2]s/%[2]s-%s.tar.gz"
-	runtimeVersionsURL = "https://dl.google.com/runtimes/%s/%s/version.json"
-	// goTarballURL is the location from which we download Go. This is different from other runtimes
-	// because the Go team already provides re-built tarballs on the same CDN.
-	goTarballURL          = "https://dl.google.com/go/go%s.linux-amd64.tar.gz"
-	runtimeImageARURL     = "%s-docker.pkg.dev/gae-runtimes/runtimes-%s/%s:%s"
-	runtimeImageARRepoURL = "%s-docker.pkg.dev/gae-runtimes/runtimes-%s/%s"
-	fallbackRegion        = "us"
+	fallbackRegion = "us"
 )
 
 // InstallableRuntime is used to hold runtimes information
 type InstallableRuntime string
 
-// All runtimes that can be installed using the InstallTarballIfNotCached function.
+// All runtimes that can be installed using the InstallTarballIfNotCached function. Where and how
+// each runtime is downloaded is described by the runtime source registry (see sources.json).
 const (
 	Nodejs       InstallableRuntime = "nodejs"
 	PHP          InstallableRuntime = "php"
@@ -60,25 +53,19 @@ const (
 	OpenJDK      InstallableRuntime = "openjdk"
 	CanonicalJDK InstallableRuntime = "canonicaljdk"
 	Go           InstallableRuntime = "go"
+	Deno         InstallableRuntime = "deno"
+
+	// Dart and Flutter have their own installers, but are downloaded from their registered sources.
+	Dart    InstallableRuntime = "dart"
+	Flutter InstallableRuntime = "flutter"
 
 	Ubuntu1804 string = "ubuntu1804"
 	Ubuntu2204 string = "ubuntu2204"
 	Ubuntu2404 string = "ubuntu2404"
 )
 
-// User friendly display name of all runtime (e.g. for use in error message).
-var runtimeNames = map[InstallableRuntime]string{
-	Nodejs:    "Node.js",
-	PHP:       "PHP Runtime",
-	Python:    "Python",
-	Ruby:      "Ruby Runtime",
-	Nginx:     "Nginx Web Server",
-	Pid1:      "Pid1",
-	DotnetSDK: ".NET SDK",
-	Go:        "Go",
-}
-
-// stackToOS contains the mapping of Stack to OS.
+// stackToOS contains the mapping of Stack to OS. It is used when the platform does not provide the
+// target distro.
 var stackToOS = map[string]string{
 	"google":                 Ubuntu1804,
 	"google.gae.18":          Ubuntu1804,
@@ -91,6 +78,14 @@ var stackToOS = map[string]string{
 	"google.24.full":         Ubuntu2404,
 }
 
+// supportedOS lists the operating systems runtimes are published for.
+var supportedOS = []string{Ubuntu1804, Ubuntu2204, Ubuntu2404}
+
+// ReadOSRelease returns the content of the os-release file of the build image.
+var ReadOSRelease = func() ([]byte, error) {
+	return os.ReadFile("/etc/os-release")
+}
+
 var languageRuntimes = []InstallableRuntime{Nodejs, PHP, Python, Ruby, OpenJDK, CanonicalJDK, Go, DotnetSDK, AspNetCore}
 
 const (
@@ -101,13 +96,74 @@ const (
 )
 
 // OSForStack returns the Operating System being used by input stackID.
+//
+// The OS is read from the CNB target distro provided by the platform if it names a supported OS.
+// Otherwise well known stacks are looked up in stackToOS, and the os-release file of the build
+// image is used for any other (e.g. custom) stack. Without a stack ID the buildpack is not running
+// in a build image (e.g. in unit tests), so os-release is not used.
 func OSForStack(ctx *gcp.Context) string {
-	os, ok := stackToOS[ctx.StackID()]
-	if !ok {
-		ctx.Warnf("unknown stack ID %q, falling back to Ubuntu 22.04", ctx.StackID())
-		os = Ubuntu2204
+	if os, ok := targetDistroOS(); ok {
+		if slices.Contains(supportedOS, os) {
+			return os
+		}
+		ctx.Debugf("Ignoring unsupported OS %q of the CNB target distro", os)
+	}
+	if os, ok := stackToOS[ctx.StackID()]; ok {
+		return os
+	}
+	if ctx.StackID() != "" {
+		if os, ok := osReleaseOS(); ok {
+			if slices.Contains(supportedOS, os) {
+				ctx.Debugf("Using OS %q from the build image os-release for stack ID %q", os, ctx.StackID())
+				return os
+			}
+			ctx.Debugf("Ignoring unsupported OS %q of the build image os-release", os)
+		}
+	}
+	ctx.Warnf("unknown stack ID %q, falling back to Ubuntu 22.04", ctx.StackID())
+	return Ubuntu2204
+}
+
+// targetDistroOS returns the OS described by the CNB_TARGET_DISTRO_NAME and
+// CNB_TARGET_DISTRO_VERSION env vars.
+func targetDistroOS() (string, bool) {
+	name := os.Getenv(libcnb.EnvTargetDistroName)
+	version := os.Getenv(libcnb.EnvTargetDistroVersion)
+	return distroOS(name, version)
+}
+
+// TargetArch returns the architecture of the build target from CNB_TARGET_ARCH, or the
+// architecture of the buildpack if the platform does not set it.
+func TargetArch() string {
+	if arch := os.Getenv(libcnb.EnvTargetArch); arch != "" {
+		return arch
+	}
+	return goruntime.GOARCH
+}
+
+// osReleaseOS returns the OS described by the ID and VERSION_ID fields of the os-release file.
+func osReleaseOS() (string, bool) {
+	content, err := ReadOSRelease()
+	if err != nil {
+		return "", false
+	}
+	fields := map[string]string{}
+	for _, line := range strings.Split(string(content), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		fields[key] = strings.Trim(value, `"'`)
+	}
+	return distroOS(fields["ID"], fields["VERSION_ID"])
+}
+
+// distroOS formats a distro name and version as an OS name, e.g. ubuntu and 22.04 as ubuntu2204.
+func distroOS(name, version string) (string, bool) {
+	if name == "" || version == "" {
+		return "", false
 	}
-	return os
+	return strings.ToLower(name) + strings.ReplaceAll(version, ".", ""), true
 }
 
 // IsCached returns true if the requested version of a runtime is installed in the given layer.
@@ -122,7 +178,11 @@ func InstallDartSDK(ctx *gcp.Context, layer *libcnb.Layer, version string) error
 	if err := ctx.ClearLayer(layer); err != nil {
 		return fmt.Errorf("clearing layer %q: %w", layer.Name, err)
 	}
-	sdkURL := fmt.Sprintf(dartSdkURL, version)
+	src, err := sourceFor(Dart)
+	if err != nil {
+		return err
+	}
+	sdkURL := src.archiveURL(Dart, OSForStack(ctx), version)
 
 	zip, err := ioutil.TempFile(layer.Path, "dart-sdk-*.zip")
 	if err != nil {
@@ -164,7 +224,11 @@ func InstallFlutterSDK(ctx *gcp.Context, layer *libcnb.Layer, version string, ar
 	if err := ctx.ClearLayer(layer); err != nil {
 		return fmt.Errorf("clearing layer %q: %w", layer.Name, err)
 	}
-	sdkURL := fmt.Sprintf(flutterSdkURL, archive)
+	src, err := sourceFor(Flutter)
+	if err != nil {
+		return err
+	}
+	sdkURL := src.releaseArchiveURL(Flutter, OSForStack(ctx), version, archive)
 
 	tar, err := ioutil.TempFile(layer.Path, "flutter_linux*.tar.xz")
 	if err != nil {
@@ -195,13 +259,20 @@ func InstallFlutterSDK(ctx *gcp.Context, layer *libcnb.Layer, version string, ar
 	return nil
 }
 
-// InstallTarballIfNotCached installs a runtime tarball hosted on dl.google.com into the provided layer
-// with caching.
+// InstallTarballIfNotCached installs a runtime tarball from the source registered for the runtime
+// into the provided layer with caching.
 // Returns true if a cached layer is used.
 func InstallTarballIfNotCached(ctx *gcp.Context, runtime InstallableRuntime, versionConstraint string, layer *libcnb.Layer) (bool, error) {
-	runtimeName := runtimeNames[runtime]
+	src, err := sourceFor(runtime)
+	if err != nil {
+		return false, err
+	}
+	runtimeName := src.displayName(runtime)
 	runtimeID := string(runtime)
 	osName := OSForStack(ctx)
+	if err := src.supportsOS(runtime, osName); err != nil {
+		return false, err
+	}
 
 	version, err := ResolveVersion(ctx, runtime, versionConstraint, osName)
 	if err != nil {
@@ -226,21 +297,20 @@ func InstallTarballIfNotCached(ctx *gcp.Context, runtime InstallableRuntime, ver
 	}
 	ctx.Logf("Installing %s v%s.", runtimeName, version)
 
-	runtimeURL := tarballDownloadURL(runtime, osName, version)
-	stripComponents := 0
-	if runtime == OpenJDK || runtime == Go {
-		stripComponents = 1
-	}
 	region, present := os.LookupEnv(env.RuntimeImageRegion)
-	if present && runtime != Go {
-		url := runtimeImageURL(runtime, osName, version, region)
-		fallbackURL := runtimeImageURL(runtime, osName, version, fallbackRegion)
-		if err := fetch.ARImage(url, fallbackURL, layer.Path, stripComponents, ctx); err != nil {
+	if present && src.ImageRepo != "" {
+		url := src.imageURL(runtime, osName, version, region)
+		fallbackURL := src.imageURL(runtime, osName, version, fallbackRegion)
+		if err := fetch.ARImage(url, fallbackURL, layer.Path, src.StripComponents, ctx); err != nil {
 			ctx.Warnf("Failed to download %s version %s osName %s from artifact registry. You can specify the version by setting the GOOGLE_RUNTIME_VERSION environment variable", runtimeName, version, osName)
 			return false, err
 		}
 	} else {
-		if err := fetch.Tarball(runtimeURL, layer.Path, stripComponents); err != nil {
+		fetchArchive := fetch.Tarball
+		if src.Format == formatZip {
+			fetchArchive = fetch.Zip
+		}
+		if err := fetchArchive(src.archiveURL(runtime, osName, version), layer.Path, src.StripComponents); err != nil {
 			ctx.Warnf("Failed to download %s version %s osName %s from lorry. You can specify the version by setting the GOOGLE_RUNTIME_VERSION environment variable", runtimeName, version, osName)
 			return false, err
 		}
@@ -252,22 +322,6 @@ func InstallTarballIfNotCached(ctx *gcp.Context, runtime InstallableRuntime, ver
 	return false, nil
 }
 
-func runtimeImageURL(runtime InstallableRuntime, osName, version, region string) string {
-	flag, present := os.LookupEnv(env.ServerlessRuntimesTarballs)
-	if present && flag == "true" {
-		newRuntimeImageARURL := "%s-docker.pkg.dev/serverless-runtimes/runtimes-%s/%s:%s"
-		return fmt.Sprintf(newRuntimeImageARURL, region, osName, runtime, version)
-	}
-	return fmt.Sprintf(runtimeImageARURL, region, osName, runtime, version)
-}
-
-func tarballDownloadURL(runtime InstallableRuntime, os, version string) string {
-	if runtime == Go {
-		return fmt.Sprintf(goTarballURL, version)
-	}
-	return fmt.Sprintf(googleTarballURL, os, runtime, strings.ReplaceAll(version, "+", "_"))
-}
-
 // PinGemAndBundlerVersion pins the RubyGems versions for GAE and GCF runtime versions to prevent
 // unexpected behaviors with new versions. This is only expected to be called if the target
 // platform is GAE or GCF.
@@ -331,8 +385,13 @@ func IsReleaseCandidate(verConstraint string) bool {
 // ResolveVersion returns the newest available version of a runtime that satisfies the provided
 // version constraint.
 func ResolveVersion(ctx *gcp.Context, runtime InstallableRuntime, verConstraint, osName string) (string, error) {
-	if runtime == Go {
-		// verConstraint will be resolved to the exact version in golang.RuntimeVersion() already.
+	src, err := sourceFor(runtime)
+	if err != nil {
+		return "", err
+	}
+	if src.VersionIndex == "" {
+		// Runtimes without a version index (e.g. Go) resolve verConstraint to an exact version
+		// themselves (see golang.RuntimeVersion()).
 		return verConstraint, nil
 	}
 	// Some release candidates do not follow the convention for semver
@@ -342,35 +401,32 @@ func ResolveVersion(ctx *gcp.Context, runtime InstallableRuntime, verConstraint,
 	}
 
 	var versions []string
-	var err error
 	region, present := os.LookupEnv(env.RuntimeImageRegion)
-	if present {
-		url := fmt.Sprintf(runtimeImageARRepoURL, region, osName, runtime)
-		fallbackURL := fmt.Sprintf(runtimeImageARRepoURL, fallbackRegion, osName, runtime)
+	fromAR := present && src.ImageRepo != ""
+	if fromAR {
+		url := src.versionRepoURL(runtime, osName, region)
+		fallbackURL := src.versionRepoURL(runtime, osName, fallbackRegion)
 		versions, err = fetch.ARVersions(url, fallbackURL, ctx)
 	} else {
-		url := fmt.Sprintf(runtimeVersionsURL, osName, runtime)
-		err = fetch.JSON(url, &versions)
+		err = fetch.JSON(src.versionIndexURL(runtime, osName), &versions)
 	}
 	if err != nil {
-		return "", gcp.InternalErrorf("fetching %s versions %s osName: %v", runtimeNames[runtime], osName, err)
+		return "", gcp.InternalErrorf("fetching %s versions %s osName: %v", src.displayName(runtime), osName, err)
 	}
 
-	if present && (runtime == OpenJDK || runtime == CanonicalJDK) {
+	if fromAR && src.EncodeBuildMetadata {
 		for i, v := range versions {
-			// When resolving version openjdk versions should be decoded to align with semver requirement. (eg. 11.0.21_9 -> 11.0.21+9)
+			// When resolving version tags should be decoded to align with semver requirement. (eg. 11.0.21_9 -> 11.0.21+9)
 			versions[i] = strings.ReplaceAll(v, "_", "+")
 		}
 	}
 	v, err := version.ResolveVersion(verConstraint, versions)
 	if err != nil {
-		return "", gcp.UserErrorf("invalid %s version specified: %v. You may need to use a different builder. Please check if the language version specified is supported by the os: %v. You can refer to https://cloud.google.com/docs/buildpacks/builders for a list of compatible runtime languages per builder", runtimeNames[runtime], err, osName)
+		return "", gcp.UserErrorf("invalid %s version specified: %v. You may need to use a different builder. Please check if the language version specified is supported by the os: %v. You can refer to https://cloud.google.com/docs/buildpacks/builders for a list of compatible runtime languages per builder", src.displayName(runtime), err, osName)
 	}
-	// When downloading from AR the openjdk version should be encoded to align with tag format requirement. (eg. 11.0.21+9 -> 11.0.21_9)
-	if present {
-		if runtime == OpenJDK || runtime == CanonicalJDK {
-			v = strings.ReplaceAll(v, "+", "_")
-		}
+	// When downloading from AR the version should be encoded to align with tag format requirement. (eg. 11.0.21+9 -> 11.0.21_9)
+	if fromAR && src.EncodeBuildMetadata {
+		v = strings.ReplaceAll(v, "+", "_")
 	}
 	return v, nil
 }
@@ -21,6 +21,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	goruntime "runtime"
 	"strings"
 	"testing"
 
@@ -66,11 +67,11 @@ func TestInstallDartSDK(t *testing.T) {
 				Path:     t.TempDir(),
 				Metadata: map[string]any{},
 			}
-			testserver.New(
+			svr := testserver.New(
 				t,
 				testserver.WithStatus(tc.httpStatus),
-				testserver.WithFile(testdata.MustGetPath(tc.responseFile)),
-				testserver.WithMockURL(&dartSdkURL))
+				testserver.WithFile(testdata.MustGetPath(tc.responseFile)))
+			stubSources(t, svr.URL, "")
 
 			version := "2.15.1"
 			err := InstallDartSDK(ctx, l, version)
@@ -127,11 +128,11 @@ func TestInstallFlutterSDK(t *testing.T) {
 				Path:     t.TempDir(),
 				Metadata: map[string]any{},
 			}
-			testserver.New(
+			svr := testserver.New(
 				t,
 				testserver.WithStatus(tc.httpStatus),
-				testserver.WithFile(testdata.MustGetPath(tc.responseFile)),
-				testserver.WithMockURL(&flutterSdkURL))
+				testserver.WithFile(testdata.MustGetPath(tc.responseFile)))
+			stubSources(t, svr.URL, "")
 
 			version := "3.29.3"
 			err := InstallFlutterSDK(ctx, l, version, "stable/linux/flutter_linux_3.29.3-stable.tar.xz")
@@ -219,19 +220,18 @@ func TestInstallRuby(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			// stub the file server
-			testserver.New(
+			tarballSvr := testserver.New(
 				t,
 				testserver.WithStatus(tc.httpStatus),
-				testserver.WithFile(testdata.MustGetPath(tc.responseFile)),
-				testserver.WithMockURL(&googleTarballURL))
+				testserver.WithFile(testdata.MustGetPath(tc.responseFile)))
 
 			// stub the version manifest
-			testserver.New(
+			versionsSvr := testserver.New(
 				t,
 				testserver.WithStatus(http.StatusOK),
 				testserver.WithJSON(`["1.1.1","3.3.3","2.2.2"]`),
-				testserver.WithMockURL(&runtimeVersionsURL),
 			)
+			stubSources(t, tarballSvr.URL, versionsSvr.URL)
 
 			layer := &libcnb.Layer{
 				Path:     t.TempDir(),
@@ -241,6 +241,7 @@ func TestInstallRuby(t *testing.T) {
 			if tc.stackID == "" {
 				tc.stackID = "google.gae.18"
 			}
+			mockReadOSRelease(t, "")
 			ctx := gcp.NewContext(gcp.WithStackID(tc.stackID))
 			if tc.wantCached {
 				ctx.SetMetadata(layer, versionKey, "2.2.2")
@@ -349,19 +350,18 @@ func TestInstallSource(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			// stub the file server
-			testserver.New(
+			tarballSvr := testserver.New(
 				t,
 				testserver.WithStatus(tc.httpStatus),
-				testserver.WithFile(testdata.MustGetPath(tc.responseFile)),
-				testserver.WithMockURL(&googleTarballURL))
+				testserver.WithFile(testdata.MustGetPath(tc.responseFile)))
 
 			// stub the version manifest
-			testserver.New(
+			versionsSvr := testserver.New(
 				t,
 				testserver.WithStatus(http.StatusOK),
 				testserver.WithJSON(`["1.1.1","3.3.3","2.2.2","16.20.0"]`),
-				testserver.WithMockURL(&runtimeVersionsURL),
 			)
+			stubSources(t, tarballSvr.URL, versionsSvr.URL)
 			fetchedFromAR := false
 			defer func(fn func(url, fallbackURL, dir string, stripComponents int, ctx *gcp.Context) error) {
 				fetch.ARImage = fn
@@ -389,6 +389,7 @@ func TestInstallSource(t *testing.T) {
 			if tc.stackID == "" {
 				tc.stackID = "google.gae.18"
 			}
+			mockReadOSRelease(t, "")
 			ctx := gcp.NewContext(gcp.WithStackID(tc.stackID))
 			if tc.runtimeImageRegion != "" {
 				t.Setenv(env.RuntimeImageRegion, tc.runtimeImageRegion)
@@ -420,6 +421,13 @@ func TestInstallSource(t *testing.T) {
 	}
 }
 
+// stubSources points the runtime source registry at the given archive and version index URLs.
+func stubSources(t *testing.T, archiveURL, versionIndexURL string) {
+	t.Helper()
+	sources := fmt.Sprintf(`{"defaults": {"archive": %q, "versionIndex": %q}}`, archiveURL, versionIndexURL)
+	t.Setenv(env.RuntimeSources, writeSources(t, sources))
+}
+
 func TestPinGemAndBundlerVersion(t *testing.T) {
 	testCases := []struct {
 		name         string
@@ -578,7 +586,11 @@ func TestRuntimeImageURL(t *testing.T) {
 			t.Setenv(env.ServerlessRuntimesTarballs, tc.serverlessRuntimesTarballs)
 		}
 		t.Run(fmt.Sprintf("%s-%s-%s-%s", tc.runtime, tc.osName, tc.version, tc.region), func(t *testing.T) {
-			runtimeImageURL := runtimeImageURL(tc.runtime, tc.osName, tc.version, tc.region)
+			src, err := sourceFor(tc.runtime)
+			if err != nil {
+				t.Fatalf("sourceFor(%q) got error: %v", tc.runtime, err)
+			}
+			runtimeImageURL := src.imageURL(tc.runtime, tc.osName, tc.version, tc.region)
 
 			if runtimeImageURL != tc.want {
 				t.Errorf("runtimeImageURL got %s, want %s", runtimeImageURL, tc.want)
@@ -724,3 +736,131 @@ func TestRuntimeMatchesInstallableRuntime(t *testing.T) {
 		}
 	}
 }
+
+func TestOSForStack(t *testing.T) {
+	testCases := []struct {
+		name          string
+		stackID       string
+		distroName    string
+		distroVersion string
+		osRelease     string
+		want          string
+	}{
+		{
+			name:    "known stack without os-release",
+			stackID: "google.gae.18",
+			want:    Ubuntu1804,
+		},
+		{
+			name:          "target distro takes precedence over os-release and stack",
+			stackID:       "google.gae.18",
+			distroName:    "ubuntu",
+			distroVersion: "24.04",
+			osRelease:     "VERSION_ID=\"22.04\"\nID=ubuntu\n",
+			want:          Ubuntu2404,
+		},
+		{
+			name:      "known stack takes precedence over os-release",
+			stackID:   "google.gae.18",
+			osRelease: "NAME=\"Ubuntu\"\nVERSION_ID=\"24.04\"\nID=ubuntu\nID_LIKE=debian\n",
+			want:      Ubuntu1804,
+		},
+		{
+			name:      "unknown stack uses os-release",
+			stackID:   "my.custom.24",
+			osRelease: "NAME=\"Ubuntu\"\nVERSION_ID=\"24.04\"\nID=ubuntu\nID_LIKE=debian\n",
+			want:      Ubuntu2404,
+		},
+		{
+			name:          "unsupported target distro uses stack",
+			stackID:       "google.24",
+			distroName:    "debian",
+			distroVersion: "12",
+			osRelease:     "VERSION_ID=\"22.04\"\nID=ubuntu\n",
+			want:          Ubuntu2404,
+		},
+		{
+			name:          "unsupported target distro of unknown stack uses os-release",
+			stackID:       "my.custom.24",
+			distroName:    "debian",
+			distroVersion: "12",
+			osRelease:     "VERSION_ID=\"24.04\"\nID=ubuntu\n",
+			want:          Ubuntu2404,
+		},
+		{
+			name:      "unknown stack with unsupported os-release",
+			stackID:   "my.custom.24",
+			osRelease: "NAME=\"Debian GNU/Linux\"\nVERSION_ID=\"12\"\nID=debian\n",
+			want:      Ubuntu2204,
+		},
+		{
+			name:      "os-release is not used without a stack",
+			osRelease: "VERSION_ID=\"24.04\"\nID=ubuntu\n",
+			want:      Ubuntu2204,
+		},
+		{
+			name:      "unknown stack with incomplete os-release",
+			stackID:   "my.custom.24",
+			osRelease: "ID=ubuntu\n",
+			want:      Ubuntu2204,
+		},
+		{
+			name:    "unknown stack without os-release",
+			stackID: "my.custom.24",
+			want:    Ubuntu2204,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(libcnb.EnvTargetDistroName, tc.distroName)
+			t.Setenv(libcnb.EnvTargetDistroVersion, tc.distroVersion)
+			mockReadOSRelease(t, tc.osRelease)
+
+			ctx := gcp.NewContext(gcp.WithStackID(tc.stackID))
+			if got := OSForStack(ctx); got != tc.want {
+				t.Errorf("OSForStack() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTargetArch(t *testing.T) {
+	testCases := []struct {
+		name string
+		arch string
+		want string
+	}{
+		{
+			name: "from target env",
+			arch: "arm64",
+			want: "arm64",
+		},
+		{
+			name: "defaults to build arch",
+			want: goruntime.GOARCH,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(libcnb.EnvTargetArch, tc.arch)
+
+			if got := TargetArch(); got != tc.want {
+				t.Errorf("TargetArch() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func mockReadOSRelease(t *testing.T, content string) {
+	t.Helper()
+	origReadOSRelease := ReadOSRelease
+	ReadOSRelease = func() ([]byte, error) {
+		if content == "" {
+			return nil, os.ErrNotExist
+		}
+		return []byte(content), nil
+	}
+	t.Cleanup(func() { ReadOSRelease = origReadOSRelease })
+}
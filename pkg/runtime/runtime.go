@@ -81,11 +81,11 @@ func formatVersion(languageName, version string) (string, error) {
 	}
 	switch languageName {
 	case "java":
-		return strconv.FormatUint(semVer.Minor(), 10), nil
+		return strconv.FormatUint(uint64(semVer.Minor()), 10), nil
 	case "dotnet":
 		fallthrough
 	case "nodejs":
-		return strconv.FormatUint(semVer.Major(), 10), nil
+		return strconv.FormatUint(uint64(semVer.Major()), 10), nil
 	default:
 		return fmt.Sprintf("%d%d", semVer.Major(), semVer.Minor()), nil
 	}
@@ -0,0 +1,235 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	// Blank import required for go:embed.
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	gcp "github.com/GoogleCloudPlatform/buildpacks/pkg/gcpbuildpack"
+)
+
+// embeddedSources is the runtime source registry shipped with the buildpacks. Entries can be
+// overridden by operators with the file referenced by env.RuntimeSources.
+//
+//go:embed sources.json
+var embeddedSources []byte
+
+const (
+	formatTarGz = "tar.gz"
+	formatZip   = "zip"
+
+	defaultChannel            = "default"
+	serverlessRuntimesChannel = "serverless-runtimes"
+)
+
+// source describes where and how a runtime is distributed. URL templates may reference ${os},
+// ${arch}, ${runtime}, ${version}, ${region}, ${release} and any variable of the selected channel.
+type source struct {
+	// Name is the user friendly display name of the runtime (e.g. for use in error messages).
+	Name string `json:"name"`
+	// VersionIndex is the URL of a JSON list of available versions. If empty, the requested version
+	// is used as is and must be exact.
+	VersionIndex string `json:"versionIndex"`
+	// Archive is the URL of the runtime archive.
+	Archive string `json:"archive"`
+	// ImageRepo is the Artifact Registry repository hosting the runtime as images tagged with the
+	// version. If empty, the runtime is always installed from Archive.
+	ImageRepo string `json:"imageRepo"`
+	// Format is the format of Archive.
+	Format string `json:"format"`
+	// StripComponents is the number of leading path elements removed during extraction.
+	StripComponents int `json:"stripComponents"`
+	// EncodeBuildMetadata is true if image tags encode the semver '+' as '_' (e.g. 11.0.21_9).
+	EncodeBuildMetadata bool `json:"encodeBuildMetadata"`
+	// OS lists the operating systems the runtime is published for.
+	OS []string `json:"os"`
+	// Arch maps the CNB target architectures to the names used by the runtime distribution, e.g.
+	// amd64 to x86_64. Architectures missing from the map are used as is.
+	Arch map[string]string `json:"arch"`
+
+	// vars are the template variables of the selected distribution channel.
+	vars map[string]string
+	// listingVars are the template variables of the default distribution channel, which always
+	// hosts the list of available versions.
+	listingVars map[string]string
+}
+
+// sourceRegistry is the on-disk format of the runtime source registry. Defaults and runtime entries
+// are kept raw so that an entry only needs to specify the fields it changes.
+type sourceRegistry struct {
+	Defaults json.RawMessage                        `json:"defaults"`
+	Channels map[string]map[string]string           `json:"channels"`
+	Runtimes map[InstallableRuntime]json.RawMessage `json:"runtimes"`
+}
+
+// sourceFor returns the source of the given runtime. Runtimes missing from the registry use the
+// registry defaults.
+func sourceFor(runtime InstallableRuntime) (source, error) {
+	registries, err := loadSourceRegistries()
+	if err != nil {
+		return source{}, err
+	}
+	// Each registry is merged as a whole so that operator defaults, e.g. a mirror, take precedence
+	// over the embedded runtime entries.
+	var src source
+	for _, r := range registries {
+		if err := mergeSource(&src, r.Defaults); err != nil {
+			return source{}, gcp.InternalErrorf("parsing runtime source defaults: %w", err)
+		}
+		if err := mergeSource(&src, r.Runtimes[runtime]); err != nil {
+			return source{}, gcp.InternalErrorf("parsing runtime source of %q: %w", runtime, err)
+		}
+	}
+	if src.Format != formatTarGz && src.Format != formatZip {
+		return source{}, gcp.InternalErrorf("unsupported archive format %q for runtime %q", src.Format, runtime)
+	}
+	src.vars = channelVars(registries, selectedChannel())
+	src.listingVars = channelVars(registries, defaultChannel)
+	return src, nil
+}
+
+// loadSourceRegistries returns the embedded registry followed by the operator provided one, if any.
+func loadSourceRegistries() ([]sourceRegistry, error) {
+	var embedded sourceRegistry
+	if err := json.Unmarshal(embeddedSources, &embedded); err != nil {
+		return nil, gcp.InternalErrorf("parsing embedded runtime sources: %w", err)
+	}
+	path, ok := os.LookupEnv(env.RuntimeSources)
+	if !ok || path == "" {
+		return []sourceRegistry{embedded}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, gcp.InternalErrorf("reading runtime sources %s: %w", path, err)
+	}
+	var override sourceRegistry
+	if err := json.Unmarshal(data, &override); err != nil {
+		return nil, gcp.InternalErrorf("parsing runtime sources %s: %w", path, err)
+	}
+	return []sourceRegistry{embedded, override}, nil
+}
+
+func mergeSource(src *source, raw json.RawMessage) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, src)
+}
+
+// selectedChannel returns the distribution channel selected by the build environment.
+func selectedChannel() string {
+	if os.Getenv(env.ServerlessRuntimesTarballs) == "true" {
+		return serverlessRuntimesChannel
+	}
+	return defaultChannel
+}
+
+// channelVars returns the template variables of the given distribution channel.
+func channelVars(registries []sourceRegistry, channel string) map[string]string {
+	vars := map[string]string{}
+	for _, r := range registries {
+		if v, ok := r.Channels[channel]; ok {
+			vars = v
+		}
+	}
+	return vars
+}
+
+// archName returns the name of the target architecture used by the runtime distribution.
+func (s source) archName() string {
+	arch := TargetArch()
+	if name, ok := s.Arch[arch]; ok {
+		return name
+	}
+	return arch
+}
+
+// displayName returns the user friendly name of the runtime.
+func (s source) displayName(runtime InstallableRuntime) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return string(runtime)
+}
+
+// supportsOS returns an error if the runtime is not published for the given OS.
+func (s source) supportsOS(runtime InstallableRuntime, osName string) error {
+	if len(s.OS) == 0 || slices.Contains(s.OS, osName) {
+		return nil
+	}
+	return gcp.UserErrorf("%s is not available for %s. You may need to use a different builder. You can refer to https://cloud.google.com/docs/buildpacks/builders for a list of compatible runtime languages per builder", s.displayName(runtime), osName)
+}
+
+// versionIndexURL returns the URL of the list of available versions.
+func (s source) versionIndexURL(runtime InstallableRuntime, osName string) string {
+	return s.expand(s.VersionIndex, s.vars, runtime, osName, "", "")
+}
+
+// archiveURL returns the URL of the runtime archive for the given version.
+func (s source) archiveURL(runtime InstallableRuntime, osName, version string) string {
+	return s.expand(s.Archive, s.vars, runtime, osName, strings.ReplaceAll(version, "+", "_"), "")
+}
+
+// releaseArchiveURL returns the URL of the runtime archive published at the given path of the
+// runtime release index, e.g. the Flutter SDK archive.
+func (s source) releaseArchiveURL(runtime InstallableRuntime, osName, version, release string) string {
+	vars := map[string]string{"release": release}
+	for k, v := range s.vars {
+		vars[k] = v
+	}
+	return s.expand(s.Archive, vars, runtime, osName, version, "")
+}
+
+// imageRepoURL returns the Artifact Registry repository of the runtime in the given region.
+func (s source) imageRepoURL(runtime InstallableRuntime, osName, region string) string {
+	return s.expand(s.ImageRepo, s.vars, runtime, osName, "", region)
+}
+
+// versionRepoURL returns the Artifact Registry repository listing the available versions of the
+// runtime in the given region. Versions are always listed from the default channel, the selected
+// channel only changes where the images are pulled from.
+func (s source) versionRepoURL(runtime InstallableRuntime, osName, region string) string {
+	return s.expand(s.ImageRepo, s.listingVars, runtime, osName, "", region)
+}
+
+// imageURL returns the runtime image for the given version in the given region.
+func (s source) imageURL(runtime InstallableRuntime, osName, version, region string) string {
+	return fmt.Sprintf("%s:%s", s.imageRepoURL(runtime, osName, region), version)
+}
+
+func (s source) expand(tmpl string, vars map[string]string, runtime InstallableRuntime, osName, version, region string) string {
+	return os.Expand(tmpl, func(key string) string {
+		switch key {
+		case "os":
+			return osName
+		case "arch":
+			return s.archName()
+		case "runtime":
+			return string(runtime)
+		case "version":
+			return version
+		case "region":
+			return region
+		}
+		return vars[key]
+	})
+}
@@ -0,0 +1,278 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/buildpacks/pkg/env"
+	"github.com/buildpacks/libcnb/v2"
+)
+
+func TestSourceFor(t *testing.T) {
+	testCases := []struct {
+		name                       string
+		runtime                    InstallableRuntime
+		osName                     string
+		arch                       string
+		version                    string
+		overrides                  string
+		serverlessRuntimesTarballs string
+		wantArchive                string
+		wantVersionIndex           string
+		wantImage                  string
+		wantVersionRepo            string
+		wantStripComponents        int
+		wantName                   string
+	}{
+		{
+			name:             "embedded defaults",
+			runtime:          Python,
+			osName:           Ubuntu2204,
+			version:          "3.12.1",
+			wantArchive:      "https://dl.google.com/runtimes/ubuntu2204/python/python-3.12.1.tar.gz",
+			wantVersionIndex: "https://dl.google.com/runtimes/ubuntu2204/python/version.json",
+			wantImage:        "us-docker.pkg.dev/gae-runtimes/runtimes-ubuntu2204/python:3.12.1",
+			wantVersionRepo:  "us-docker.pkg.dev/gae-runtimes/runtimes-ubuntu2204/python",
+			wantName:         "Python",
+		},
+		{
+			name:                "build metadata is encoded in archive",
+			runtime:             OpenJDK,
+			osName:              Ubuntu2204,
+			version:             "11.0.21+9",
+			wantArchive:         "https://dl.google.com/runtimes/ubuntu2204/openjdk/openjdk-11.0.21_9.tar.gz",
+			wantVersionIndex:    "https://dl.google.com/runtimes/ubuntu2204/openjdk/version.json",
+			wantImage:           "us-docker.pkg.dev/gae-runtimes/runtimes-ubuntu2204/openjdk:11.0.21+9",
+			wantStripComponents: 1,
+			wantName:            "OpenJDK",
+		},
+		{
+			name:                "go is downloaded from the go CDN",
+			runtime:             Go,
+			osName:              Ubuntu2404,
+			version:             "1.22.1",
+			wantArchive:         "https://dl.google.com/go/go1.22.1.linux-amd64.tar.gz",
+			wantImage:           ":1.22.1",
+			wantStripComponents: 1,
+			wantName:            "Go",
+		},
+		{
+			name:                "go archive for the target arch",
+			runtime:             Go,
+			osName:              Ubuntu2404,
+			arch:                "arm64",
+			version:             "1.22.1",
+			wantArchive:         "https://dl.google.com/go/go1.22.1.linux-arm64.tar.gz",
+			wantImage:           ":1.22.1",
+			wantStripComponents: 1,
+			wantName:            "Go",
+		},
+		{
+			name:        "dart is downloaded as a zip from the dart archive",
+			runtime:     Dart,
+			osName:      Ubuntu2404,
+			version:     "3.5.0",
+			wantArchive: "https://storage.googleapis.com/dart-archive/channels/stable/release/3.5.0/sdk/dartsdk-linux-x64-release.zip",
+			wantImage:   ":3.5.0",
+			wantName:    "Dart SDK",
+		},
+		{
+			name:        "dart archive for the target arch",
+			runtime:     Dart,
+			osName:      Ubuntu2404,
+			arch:        "arm64",
+			version:     "3.5.0",
+			wantArchive: "https://storage.googleapis.com/dart-archive/channels/stable/release/3.5.0/sdk/dartsdk-linux-arm64-release.zip",
+			wantImage:   ":3.5.0",
+			wantName:    "Dart SDK",
+		},
+		{
+			name:        "deno is downloaded as a zip from GitHub releases",
+			runtime:     Deno,
+			osName:      Ubuntu2404,
+			version:     "2.1.4",
+			wantArchive: "https://github.com/denoland/deno/releases/download/v2.1.4/deno-x86_64-unknown-linux-gnu.zip",
+			wantImage:   ":2.1.4",
+			wantName:    "Deno",
+		},
+		{
+			name:        "deno archive for the target arch",
+			runtime:     Deno,
+			osName:      Ubuntu2404,
+			arch:        "arm64",
+			version:     "2.1.4",
+			wantArchive: "https://github.com/denoland/deno/releases/download/v2.1.4/deno-aarch64-unknown-linux-gnu.zip",
+			wantImage:   ":2.1.4",
+			wantName:    "Deno",
+		},
+		{
+			name:                       "serverless runtimes channel",
+			runtime:                    Nodejs,
+			osName:                     Ubuntu2204,
+			version:                    "20.1.0",
+			serverlessRuntimesTarballs: "true",
+			wantArchive:                "https://dl.google.com/runtimes/ubuntu2204/nodejs/nodejs-20.1.0.tar.gz",
+			wantVersionIndex:           "https://dl.google.com/runtimes/ubuntu2204/nodejs/version.json",
+			wantImage:                  "us-docker.pkg.dev/serverless-runtimes/runtimes-ubuntu2204/nodejs:20.1.0",
+			wantVersionRepo:            "us-docker.pkg.dev/gae-runtimes/runtimes-ubuntu2204/nodejs",
+			wantName:                   "Node.js",
+		},
+		{
+			name:    "unknown runtime uses defaults",
+			runtime: InstallableRuntime("bun"),
+			osName:  Ubuntu2404,
+			version: "1.1.0",
+			overrides: `{
+				"runtimes": {"bun": {"archive": "https://mirror.example.com/${runtime}/${version}.tar.gz", "stripComponents": 1}}
+			}`,
+			wantArchive:         "https://mirror.example.com/bun/1.1.0.tar.gz",
+			wantVersionIndex:    "https://dl.google.com/runtimes/ubuntu2404/bun/version.json",
+			wantImage:           "us-docker.pkg.dev/gae-runtimes/runtimes-ubuntu2404/bun:1.1.0",
+			wantStripComponents: 1,
+			wantName:            "bun",
+		},
+		{
+			name:    "operator overrides defaults and channels",
+			runtime: Ruby,
+			osName:  Ubuntu1804,
+			version: "3.2.0",
+			overrides: `{
+				"defaults": {"archive": "https://mirror.example.com/${os}/${runtime}-${version}.tar.gz"},
+				"channels": {"default": {"arProject": "my-mirror"}}
+			}`,
+			wantArchive:      "https://mirror.example.com/ubuntu1804/ruby-3.2.0.tar.gz",
+			wantVersionIndex: "https://dl.google.com/runtimes/ubuntu1804/ruby/version.json",
+			wantImage:        "us-docker.pkg.dev/my-mirror/runtimes-ubuntu1804/ruby:3.2.0",
+			wantName:         "Ruby Runtime",
+		},
+		{
+			name:    "operator defaults override embedded runtime entries",
+			runtime: Go,
+			osName:  Ubuntu2404,
+			version: "1.22.1",
+			overrides: `{
+				"defaults": {"archive": "https://mirror.example.com/${runtime}/${version}-${arch}.tar.gz"}
+			}`,
+			wantArchive:         "https://mirror.example.com/go/1.22.1-amd64.tar.gz",
+			wantImage:           ":1.22.1",
+			wantStripComponents: 1,
+			wantName:            "Go",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.arch == "" {
+				tc.arch = "amd64"
+			}
+			t.Setenv(libcnb.EnvTargetArch, tc.arch)
+			if tc.overrides != "" {
+				t.Setenv(env.RuntimeSources, writeSources(t, tc.overrides))
+			}
+			if tc.serverlessRuntimesTarballs != "" {
+				t.Setenv(env.ServerlessRuntimesTarballs, tc.serverlessRuntimesTarballs)
+			}
+
+			src, err := sourceFor(tc.runtime)
+			if err != nil {
+				t.Fatalf("sourceFor(%q) got error: %v", tc.runtime, err)
+			}
+			if got := src.archiveURL(tc.runtime, tc.osName, tc.version); got != tc.wantArchive {
+				t.Errorf("archiveURL() = %q, want %q", got, tc.wantArchive)
+			}
+			if got := src.versionIndexURL(tc.runtime, tc.osName); got != tc.wantVersionIndex {
+				t.Errorf("versionIndexURL() = %q, want %q", got, tc.wantVersionIndex)
+			}
+			if got := src.imageURL(tc.runtime, tc.osName, tc.version, "us"); got != tc.wantImage {
+				t.Errorf("imageURL() = %q, want %q", got, tc.wantImage)
+			}
+			if tc.wantVersionRepo != "" {
+				if got := src.versionRepoURL(tc.runtime, tc.osName, "us"); got != tc.wantVersionRepo {
+					t.Errorf("versionRepoURL() = %q, want %q", got, tc.wantVersionRepo)
+				}
+			}
+			if src.StripComponents != tc.wantStripComponents {
+				t.Errorf("StripComponents = %d, want %d", src.StripComponents, tc.wantStripComponents)
+			}
+			if got := src.displayName(tc.runtime); got != tc.wantName {
+				t.Errorf("displayName() = %q, want %q", got, tc.wantName)
+			}
+		})
+	}
+}
+
+func TestReleaseArchiveURL(t *testing.T) {
+	src, err := sourceFor(Flutter)
+	if err != nil {
+		t.Fatalf("sourceFor(%q) got error: %v", Flutter, err)
+	}
+	want := "https://storage.googleapis.com/flutter_infra_release/releases/stable/linux/flutter_linux_3.29.3-stable.tar.xz"
+	if got := src.releaseArchiveURL(Flutter, Ubuntu2404, "3.29.3", "stable/linux/flutter_linux_3.29.3-stable.tar.xz"); got != want {
+		t.Errorf("releaseArchiveURL() = %q, want %q", got, want)
+	}
+}
+
+func TestSourceForErrors(t *testing.T) {
+	testCases := []struct {
+		name      string
+		overrides string
+	}{
+		{
+			name:      "malformed overrides",
+			overrides: `{"runtimes": `,
+		},
+		{
+			name:      "unsupported format",
+			overrides: `{"runtimes": {"python": {"format": "7z"}}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(env.RuntimeSources, writeSources(t, tc.overrides))
+
+			if _, err := sourceFor(Python); err == nil {
+				t.Errorf("sourceFor(%q) got nil error, want error", Python)
+			}
+		})
+	}
+}
+
+func TestSupportsOS(t *testing.T) {
+	t.Setenv(env.RuntimeSources, writeSources(t, `{"runtimes": {"nginx": {"os": ["ubuntu2204"]}}}`))
+
+	src, err := sourceFor(Nginx)
+	if err != nil {
+		t.Fatalf("sourceFor(%q) got error: %v", Nginx, err)
+	}
+	if err := src.supportsOS(Nginx, Ubuntu2204); err != nil {
+		t.Errorf("supportsOS(%q, %q) got error: %v", Nginx, Ubuntu2204, err)
+	}
+	if err := src.supportsOS(Nginx, Ubuntu2404); err == nil {
+		t.Errorf("supportsOS(%q, %q) got nil error, want error", Nginx, Ubuntu2404)
+	}
+}
+
+func writeSources(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "sources.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("writing runtime sources: %v", err)
+	}
+	return path
+}
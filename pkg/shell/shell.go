@@ -0,0 +1,232 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package shell parses command lines following the quoting rules of the POSIX shell.
+package shell
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// operatorChars are the characters of the shell control and redirection operators.
+	operatorChars = "|&;<>()"
+	// doubleQuoteEscapable are the characters a backslash escapes in double quotes.
+	doubleQuoteEscapable = "$`\"\\\n"
+)
+
+// Error is a command line that cannot be parsed.
+type Error struct {
+	// Command is the command line.
+	Command string
+	// Offset is the byte offset of the error in the command line.
+	Offset int
+	// Reason describes the error.
+	Reason string
+	// RequiresShell is true if the command line uses operators or expansions, it is valid but can
+	// only be run by a shell.
+	RequiresShell bool
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("parsing command %q: %s at offset %d", e.Command, e.Reason, e.Offset)
+}
+
+// Command is a simple command: a list of words preceded by optional variable assignments.
+type Command struct {
+	// Env are the NAME=value variable assignments preceding the command, to be added to its
+	// environment.
+	Env []string
+	// Args are the command and its arguments.
+	Args []string
+}
+
+// Split splits a command line into words, removing the quotes and backslashes escaping
+// characters. Operators and expansions are not interpreted: $HOME or && are words like any other.
+// It is meant for lists of arguments, such as those appended to a build tool command line.
+func Split(line string) ([]string, error) {
+	words, err := lex(line, false)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, len(words))
+	for _, w := range words {
+		args = append(args, w.value)
+	}
+	return args, nil
+}
+
+// Parse parses a simple command line such as NODE_OPTIONS="--max-old-space-size=4096" npm run
+// build. It returns an Error with RequiresShell set if the line uses operators, such as && or >,
+// or parameter and command expansions, which must be interpreted by a shell. Tilde and pathname
+// expansions are not performed.
+func Parse(line string) (*Command, error) {
+	words, err := lex(line, true)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &Command{}
+	for _, w := range words {
+		if len(cmd.Args) == 0 && w.assignment {
+			cmd.Env = append(cmd.Env, w.value)
+			continue
+		}
+		cmd.Args = append(cmd.Args, w.value)
+	}
+	return cmd, nil
+}
+
+type word struct {
+	value string
+	// assignment is true if the word starts with an unquoted NAME=.
+	assignment bool
+}
+
+type lexer struct {
+	line string
+	// strict rejects the operators and expansions interpreted by the shell.
+	strict bool
+	words  []word
+	cur    strings.Builder
+	inWord bool
+	// plain is true while the current word only contains unquoted characters.
+	plain      bool
+	eqSeen     bool
+	assignment bool
+}
+
+func lex(line string, strict bool) ([]word, error) {
+	l := &lexer{line: line, strict: strict, plain: true}
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t':
+			l.endWord()
+		case c == '\n':
+			l.endWord()
+			if strict && strings.TrimSpace(line[i:]) != "" {
+				return nil, l.errorAt(i, true, "unquoted newline separating commands requires a shell")
+			}
+		case c == '#' && !l.inWord:
+			// A comment extends to the end of the line.
+			end := strings.IndexByte(line[i:], '\n')
+			if end < 0 {
+				i = len(line)
+			} else {
+				i += end - 1
+			}
+		case c == '\\':
+			if i+1 == len(line) {
+				return nil, l.errorAt(i, false, "trailing backslash")
+			}
+			i++
+			// A backslash followed by a newline continues the line.
+			if line[i] != '\n' {
+				l.add(line[i], true)
+			}
+		case c == '\'':
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end < 0 {
+				return nil, l.errorAt(i, false, "unterminated single quote")
+			}
+			l.inWord = true
+			for _, q := range []byte(line[i+1 : i+1+end]) {
+				l.add(q, true)
+			}
+			i += end + 1
+		case c == '"':
+			end, err := l.doubleQuoted(i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+		case strict && strings.IndexByte(operatorChars, c) >= 0:
+			op := string(c)
+			if (c == '&' || c == '|') && i+1 < len(line) && line[i+1] == c {
+				op += string(c)
+			}
+			return nil, l.errorAt(i, true, fmt.Sprintf("unquoted %q requires a shell", op))
+		case strict && (c == '$' || c == '`'):
+			return nil, l.errorAt(i, true, fmt.Sprintf("unquoted %q expansion requires a shell", c))
+		case c == '=' && !l.eqSeen:
+			l.eqSeen = true
+			l.assignment = l.plain && isName(l.cur.String())
+			l.add(c, false)
+		default:
+			l.add(c, false)
+		}
+	}
+	l.endWord()
+	return l.words, nil
+}
+
+// doubleQuoted adds the content of the double quoted string starting at offset start to the
+// current word and returns the offset of the closing quote.
+func (l *lexer) doubleQuoted(start int) (int, error) {
+	l.inWord = true
+	for i := start + 1; i < len(l.line); i++ {
+		c := l.line[i]
+		switch {
+		case c == '"':
+			return i, nil
+		case c == '\\' && i+1 < len(l.line) && strings.IndexByte(doubleQuoteEscapable, l.line[i+1]) >= 0:
+			i++
+			if l.line[i] != '\n' {
+				l.add(l.line[i], true)
+			}
+		case l.strict && (c == '$' || c == '`'):
+			return 0, l.errorAt(i, true, fmt.Sprintf("%q expansion requires a shell", c))
+		default:
+			l.add(c, true)
+		}
+	}
+	return 0, l.errorAt(start, false, "unterminated double quote")
+}
+
+func (l *lexer) add(c byte, quoted bool) {
+	l.cur.WriteByte(c)
+	l.inWord = true
+	if quoted {
+		l.plain = false
+	}
+}
+
+func (l *lexer) endWord() {
+	if l.inWord {
+		l.words = append(l.words, word{value: l.cur.String(), assignment: l.assignment})
+	}
+	l.cur.Reset()
+	l.inWord = false
+	l.plain = true
+	l.eqSeen = false
+	l.assignment = false
+}
+
+func (l *lexer) errorAt(offset int, requiresShell bool, reason string) error {
+	return &Error{Command: l.line, Offset: offset, Reason: reason, RequiresShell: requiresShell}
+}
+
+// isName returns true if s is a valid shell variable name.
+func isName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		if c != '_' && !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && (i == 0 || !('0' <= c && c <= '9')) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,217 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shell
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSplit(t *testing.T) {
+	testCases := []struct {
+		name    string
+		line    string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			line: "  ",
+			want: []string{},
+		},
+		{
+			name: "whitespace",
+			line: " clean \tpackage\n-DskipTests ",
+			want: []string{"clean", "package", "-DskipTests"},
+		},
+		{
+			name: "single quotes",
+			line: `-Dmessage='hello  world' '' 'a"b'`,
+			want: []string{"-Dmessage=hello  world", "", `a"b`},
+		},
+		{
+			name: "double quotes",
+			line: `"a \"quoted\" \$word" "back\slash" "\\"`,
+			want: []string{`a "quoted" $word`, `back\slash`, `\`},
+		},
+		{
+			name: "backslashes",
+			line: `a\ b \'c\' line\
+continued`,
+			want: []string{"a b", "'c'", "linecontinued"},
+		},
+		{
+			name: "operators and expansions are words",
+			line: "$HOME && echo `id`",
+			want: []string{"$HOME", "&&", "echo", "`id`"},
+		},
+		{
+			name: "comment",
+			line: "-q # quiet\n-U",
+			want: []string{"-q", "-U"},
+		},
+		{
+			name: "utf-8",
+			line: `--name="café crème"`,
+			want: []string{"--name=café crème"},
+		},
+		{
+			name:    "unterminated single quote",
+			line:    "-Dmessage='hello",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated double quote",
+			line:    `"hello`,
+			wantErr: true,
+		},
+		{
+			name:    "trailing backslash",
+			line:    `hello\`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Split(tc.line)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("Split(%q) got error: %v, want error? %v", tc.line, err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Split(%q) mismatch (-want +got):\n%s", tc.line, diff)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name              string
+		line              string
+		want              *Command
+		wantOffset        int
+		wantRequiresShell bool
+		wantErr           bool
+	}{
+		{
+			name: "command",
+			line: "npm run build",
+			want: &Command{Args: []string{"npm", "run", "build"}},
+		},
+		{
+			name: "quoted arguments",
+			line: `ng build --configuration "production" --base-href='/my app/'`,
+			want: &Command{Args: []string{"ng", "build", "--configuration", "production", "--base-href=/my app/"}},
+		},
+		{
+			name: "assignments",
+			line: `NODE_OPTIONS="--max-old-space-size=4096" CI=true npm run build -- --mode=production`,
+			want: &Command{
+				Env:  []string{"NODE_OPTIONS=--max-old-space-size=4096", "CI=true"},
+				Args: []string{"npm", "run", "build", "--", "--mode=production"},
+			},
+		},
+		{
+			name: "quoted name is not an assignment",
+			line: `"CI"=true npm run build`,
+			want: &Command{Args: []string{"CI=true", "npm", "run", "build"}},
+		},
+		{
+			name: "invalid name is not an assignment",
+			line: `1CI=true`,
+			want: &Command{Args: []string{"1CI=true"}},
+		},
+		{
+			name: "only assignments",
+			line: `CI=true`,
+			want: &Command{Env: []string{"CI=true"}},
+		},
+		{
+			name: "quoted operators",
+			line: `echo '&&' "a|b" \;`,
+			want: &Command{Args: []string{"echo", "&&", "a|b", ";"}},
+		},
+		{
+			name: "trailing newline",
+			line: "npm run build\n",
+			want: &Command{Args: []string{"npm", "run", "build"}},
+		},
+		{
+			name:              "and list",
+			line:              "npm run lint && npm run build",
+			wantOffset:        13,
+			wantRequiresShell: true,
+			wantErr:           true,
+		},
+		{
+			name:              "redirection",
+			line:              "npm run build >build.log",
+			wantOffset:        14,
+			wantRequiresShell: true,
+			wantErr:           true,
+		},
+		{
+			name:              "parameter expansion",
+			line:              "npm run build -- --base=$BASE",
+			wantOffset:        24,
+			wantRequiresShell: true,
+			wantErr:           true,
+		},
+		{
+			name:              "expansion in double quotes",
+			line:              `echo "$(date)"`,
+			wantOffset:        6,
+			wantRequiresShell: true,
+			wantErr:           true,
+		},
+		{
+			name:              "commands on several lines",
+			line:              "npm run lint\nnpm run build",
+			wantOffset:        12,
+			wantRequiresShell: true,
+			wantErr:           true,
+		},
+		{
+			name:       "unterminated quote",
+			line:       `npm run "build`,
+			wantOffset: 8,
+			wantErr:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Parse(tc.line)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("Parse(%q) got error: %v, want error? %v", tc.line, err, tc.wantErr)
+			}
+			if err != nil {
+				var shellErr *Error
+				if !errors.As(err, &shellErr) {
+					t.Fatalf("Parse(%q) got error %v, want an *Error", tc.line, err)
+				}
+				if shellErr.Offset != tc.wantOffset || shellErr.RequiresShell != tc.wantRequiresShell {
+					t.Errorf("Parse(%q) got error at offset %d requiring a shell: %v, want offset %d requiring a shell: %v", tc.line, shellErr.Offset, shellErr.RequiresShell, tc.wantOffset, tc.wantRequiresShell)
+				}
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Parse(%q) mismatch (-want +got):\n%s", tc.line, diff)
+			}
+		})
+	}
+}
@@ -3,7 +3,7 @@
 load("@rules_pkg//pkg:mappings.bzl", "pkg_mklink")
 load("@rules_pkg//pkg:tar.bzl", "pkg_tar")
 
-def buildpack(name, executables, prefix, version, api = "0.9", srcs = None, extension = "tgz", strip_prefix = ".", visibility = None):
+def buildpack(name, executables, prefix, version, api = "0.9", srcs = None, execd = None, extension = "tgz", strip_prefix = ".", visibility = None):
     """Macro to create a single buildpack as a tgz or tar archive.
 
     The result is a tar or tgz archive with a buildpack descriptor
@@ -19,6 +19,8 @@ def buildpack(name, executables, prefix, version, api = "0.9", srcs = None, exte
       version: the version of the buildpack
       api: the buildpacks API version
       executables: list of labels of buildpack binaries
+      execd: dict of labels of exec.d binaries to their names in the exec directory, the buildpack
+        copies them to the exec.d directory of a launch layer
       strip_prefix: by default preserves the paths of srcs
       extension: tgz by default
       visibility: the visibility
@@ -48,6 +50,9 @@ def buildpack(name, executables, prefix, version, api = "0.9", srcs = None, exte
 
     if not srcs:
         srcs = []
+    files = {executables[0]: "/bin/main"}
+    for label, binary in (execd or {}).items():
+        files[label] = "/exec/" + binary
     pkg_tar(
         name = name,
         extension = extension,
@@ -56,9 +61,7 @@ def buildpack(name, executables, prefix, version, api = "0.9", srcs = None, exte
             "_link_build" + name,
             "_link_detect" + name,
         ] + srcs,
-        files = {
-            executables[0]: "/bin/main",
-        },
+        files = files,
         strip_prefix = strip_prefix,
         visibility = visibility,
     )